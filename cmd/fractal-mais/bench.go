@@ -0,0 +1,209 @@
+// main package structures experiment which measures time complexity of the algorithms. This file in particular
+// implements the bench command, which benchmarks time complexity of the FMAIS and ME-ERT-CORE algorithms.
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/internal/benchmarking"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/internal/config"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/sweep"
+	"log"
+)
+
+// benchmarks, which can be run by the bench command
+const (
+	benchFMAIS     = "fmais"
+	benchMeErtCORE = "meertcore"
+	benchOptimized = "optimized"
+	benchErtCORE   = "ertcore"
+)
+
+// benchFlags are parameters of the benchmarks
+type benchFlags struct {
+	modelFlags
+	iterations     int
+	seed           int64
+	sweepDepths    string
+	sweepApps      string
+	sweepInstances string
+	warmUp         int
+	gc             bool
+	trim           float64
+	confidence     float64
+	workers        int
+	procsPerWorker int
+	lockOSThread   bool
+	resume         bool
+	report         bool
+	docker         bool
+	hardcoded      bool
+}
+
+// register adds the flags to the flag set
+func (f *benchFlags) register(flags *pflag.FlagSet) {
+	f.modelFlags.register(flags)
+	defaults := benchmarking.DefaultOptions()
+	flags.IntVar(&f.iterations, "iterations", 25000, "sets a number of iterations per single parameter set to perform")
+	flags.Int64Var(&f.seed, "seed", 0, "sets a seed of the randomly generated System Models (0 keeps the generator randomly seeded)")
+	flags.StringVar(&f.sweepDepths, "depths", "", "sets depths swept by the benchmark, e.g., 1:4 (by default, all depths up to --depth)")
+	flags.StringVar(&f.sweepApps, "apps", "", "sets numbers of applications swept by the benchmark, e.g., 1:101:5, 10,20,50 or log:1:1000:7 (by default, up to --appNumber with a step of 5)")
+	flags.StringVar(&f.sweepInstances, "instances", "", "sets numbers of instances per application swept by the benchmark (by default, up to --maxNumInstances with a step of 5)")
+	flags.IntVar(&f.warmUp, "warmUp", defaults.WarmUp, "sets a number of warm-up iterations performed (and not recorded) before each parameter set")
+	flags.BoolVar(&f.gc, "gc", false, "runs a garbage collection before each parameter set of the benchmark")
+	flags.Float64Var(&f.trim, "trim", 0, "sets a fraction of the lowest and the highest measured samples discarded as outliers (e.g., 0.01)")
+	flags.Float64Var(&f.confidence, "confidence", defaults.Confidence, "sets a confidence level of the interval of the mean measured time")
+	flags.IntVar(&f.workers, "workers", 1, "sets a number of parameter sets benchmarked in parallel (1 is the sequential reference mode, memory metrics are gathered in it only)")
+	flags.IntVar(&f.procsPerWorker, "procsPerWorker", 0, "sets GOMAXPROCS to workers × procsPerWorker for the duration of the benchmark (0 keeps the default)")
+	flags.BoolVar(&f.lockOSThread, "lockOSThread", false, "locks each benchmark worker to its own OS thread")
+	flags.BoolVar(&f.resume, "resume", false, "resumes the benchmarks from their last checkpoints stored in the data directory (finished parameter sets are not measured again)")
+	flags.BoolVar(&f.report, "report", false, "generates an HTML report at the end of the benchmarking (stored next to the figures)")
+	flags.BoolVar(&f.docker, "docker", false, "indicates that the benchmarking is done in Docker container")
+	flags.BoolVar(&f.hardcoded, "hardcoded", false, "performs a hardcoded benchmarking (with hardcoded values)")
+}
+
+// configure validates the flags and sets up the benchmarking package
+func (f *benchFlags) configure() error {
+	err := f.modelFlags.validate()
+	if err != nil {
+		return err
+	}
+	if f.iterations < 1 {
+		return fmt.Errorf("--iterations should be positive, got %d", f.iterations)
+	}
+	benchmarking.SetReport(f.report)
+	benchmarking.SetResume(f.resume)
+	return benchmarking.SetOptions(benchmarking.Options{
+		WarmUp:         f.warmUp,
+		GC:             f.gc,
+		Trim:           f.trim,
+		Confidence:     f.confidence,
+		Workers:        f.workers,
+		ProcsPerWorker: f.procsPerWorker,
+		LockOSThread:   f.lockOSThread,
+		Seed:           f.seed,
+	})
+}
+
+// spec returns a parameter grid of the benchmark. Axes set by --depths, --apps and --instances flags take
+// precedence, the rest is derived from --depth, --appNumber and --maxNumInstances flags in the same way as it was
+// done originally by the (optimized) benchmark.
+func (f *benchFlags) spec(optimized bool) (sweep.Spec, error) {
+	spec := sweep.Default(f.depth, f.appNumber, f.maxNumInstances)
+	var err error
+	if optimized {
+		spec, err = benchmarking.DefaultOptimizedSpec(f.depth, f.appNumber, f.maxNumInstances)
+		if err != nil {
+			return sweep.Spec{}, err
+		}
+	}
+	if f.sweepDepths != "" {
+		spec.Depths, err = sweep.ParseAxis(f.sweepDepths)
+		if err != nil {
+			return sweep.Spec{}, fmt.Errorf("can't parse --depths: %w", err)
+		}
+	}
+	if f.sweepApps != "" {
+		spec.Apps, err = sweep.ParseAxis(f.sweepApps)
+		if err != nil {
+			return sweep.Spec{}, fmt.Errorf("can't parse --apps: %w", err)
+		}
+	}
+	if f.sweepInstances != "" {
+		spec.Instances, err = sweep.ParseAxis(f.sweepInstances)
+		if err != nil {
+			return sweep.Spec{}, fmt.Errorf("can't parse --instances: %w", err)
+		}
+	}
+	log.Printf("Benchmark sweeps over %s (%d parameter sets)\n", spec, spec.Cells())
+	return spec, nil
+}
+
+// benchCommand implements a command, which benchmarks time complexity of the algorithms
+func benchCommand() *cobra.Command {
+	flags := &benchFlags{}
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Benchmarks time complexity of the FMAIS and ME-ERT-CORE algorithms",
+		Long: "Benchmarks time complexity of the chosen algorithm over a parameter grid of randomly generated FMAIS. " +
+			"Results are stored in the data directory and figures of the time complexity are stored in the output directory.",
+		Args: cobra.NoArgs,
+	}
+	flags.register(cmd.PersistentFlags())
+
+	for _, sub := range []struct {
+		use   string
+		short string
+		runs  []string
+	}{
+		{benchFMAIS, "Benchmarks generation of the FMAIS System Model", []string{benchFMAIS}},
+		{benchMeErtCORE, "Benchmarks computation of the reliability with ME-ERT-CORE (per definition)", []string{benchMeErtCORE}},
+		{benchOptimized, "Benchmarks the optimized ME-ERT-CORE together with ME-ERT-CORE per definition", []string{benchOptimized}},
+		{benchErtCORE, "Benchmarks computation of the reliability with ERT-CORE (not implemented yet)", []string{benchErtCORE}},
+		{"all", "Benchmarks the FMAIS System Model and ME-ERT-CORE (per definition)", []string{benchFMAIS, benchMeErtCORE}},
+	} {
+		cmd.AddCommand(&cobra.Command{
+			Use:   sub.use,
+			Short: sub.short,
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				err := config.Apply(cfg.Benchmark, cmd.Flags())
+				if err != nil {
+					return err
+				}
+				err = flags.configure()
+				if err != nil {
+					return err
+				}
+				// configuration is stored by each run, so that it carries the timestamp of its result files
+				benchmarking.SetRunHook(func(benchmark, timestamp string) error {
+					return saveEffectiveConfig(cmd, "bench_"+benchmark, timestamp, &config.Config{Benchmark: &config.Benchmark{}})
+				})
+				defer benchmarking.SetRunHook(nil)
+				for _, benchmark := range sub.runs {
+					err = runBenchmark(cmd.Context(), benchmark, flags)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		})
+	}
+	return cmd
+}
+
+// runBenchmark runs a single benchmark with the (already configured) flags, it is interrupted, once the context is done
+func runBenchmark(ctx context.Context, benchmark string, flags *benchFlags) error {
+	switch benchmark {
+	case benchFMAIS:
+		if flags.hardcoded {
+			return benchmarking.BenchSystemModelNoParamContext(ctx, flags.docker, greyScale)
+		}
+		spec, err := flags.spec(false)
+		if err != nil {
+			return err
+		}
+		return benchmarking.BenchSystemModelContext(ctx, spec, flags.iterations, flags.docker, greyScale)
+	case benchMeErtCORE:
+		if flags.hardcoded {
+			return benchmarking.BenchMeErtCORENoParamContext(ctx, flags.docker, greyScale)
+		}
+		spec, err := flags.spec(false)
+		if err != nil {
+			return err
+		}
+		return benchmarking.BenchMeErtCOREContext(ctx, spec, flags.iterations, flags.docker, greyScale)
+	case benchOptimized:
+		spec, err := flags.spec(true)
+		if err != nil {
+			return err
+		}
+		return benchmarking.BenchMeErtCoreOptimizedContext(ctx, spec, flags.docker, greyScale)
+	case benchErtCORE:
+		return fmt.Errorf("ERT-CORE benchmark is not implemented yet")
+	}
+	return fmt.Errorf("unknown benchmark %s", benchmark)
+}
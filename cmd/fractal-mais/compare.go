@@ -0,0 +1,112 @@
+// main package structures experiment which measures time complexity of the algorithms. This file in particular
+// implements commands, which analyse benchmark results, i.e., a comparison of two runs and curve fitting.
+package main
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/compare"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/draw"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/fitting"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/sweep"
+	"path/filepath"
+	"strings"
+)
+
+// compareCommand implements a command, which compares two benchmark runs and reports regressions and improvements
+func compareCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compare <old> <new>",
+		Short: "Compares two benchmark results and reports regressions and improvements",
+		Long: "Compares means of each parameter set, which is present in both benchmark results, with Welch's t-test. " +
+			"Results are read from the data directory, unless a path is given. A text table is printed and a figure " +
+			"of the ratios of the new to the old time is stored next to the other figures.",
+		Args: cobra.ExactArgs(2),
+		RunE: runCompare,
+	}
+	defaults := compare.DefaultOptions()
+	cmd.Flags().Float64("alpha", defaults.Alpha, "sets a significance level of the Welch's t-test")
+	cmd.Flags().Float64("threshold", defaults.Threshold, "sets a minimal relative change of the mean time considered as a regression or an improvement (e.g., 0.05)")
+	cmd.Flags().Bool("failOnRegression", false, "exits with an error, if any regression is found")
+	return cmd
+}
+
+// runCompare compares two benchmark results given by the arguments
+func runCompare(cmd *cobra.Command, args []string) error {
+	alpha, _ := cmd.Flags().GetFloat64("alpha")
+	threshold, _ := cmd.Flags().GetFloat64("threshold")
+	failOnRegression, _ := cmd.Flags().GetBool("failOnRegression")
+
+	old, err := importResultFile(args[0])
+	if err != nil {
+		return err
+	}
+	new, err := importResultFile(args[1])
+	if err != nil {
+		return err
+	}
+	c, err := compare.Compare(old, new, compare.Options{Alpha: alpha, Threshold: threshold})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Comparing %s (old) with %s (new)\n\n", args[0], args[1])
+	err = c.WriteTable(cmd.OutOrStdout())
+	if err != nil {
+		return err
+	}
+	ratios := c.Ratios()
+	err = draw.PlotComparison(ratios, sweep.FromData(ratios), "Comparison", greyScale)
+	if err != nil {
+		return err
+	}
+
+	if failOnRegression && c.Total.Regressions > 0 {
+		return fmt.Errorf("found %d regressions out of %d compared cells", c.Total.Regressions, c.Total.Cells)
+	}
+	return nil
+}
+
+// fitCommand implements a command, which fits a model to the slices of the benchmarked data
+func fitCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fit <result>",
+		Short: "Fits a model to the benchmarked time complexity and extrapolates it to larger depths",
+		Long: "Fits a model (exponential, polynomial[degree], power-law, or the best one by R²) to the same slices of the " +
+			"benchmarked data, which are plotted as time complexity figures, and prints its coefficients and R². The result " +
+			"is read from the data directory, unless a path is given. Fitted models are stored in the data directory.",
+		Args: cobra.ExactArgs(1),
+		RunE: runFit,
+	}
+	cmd.Flags().String("model", string(fitting.Best), "sets a fitted model (exponential, polynomial[degree], power-law or best)")
+	cmd.Flags().IntSlice("extrapolateDepth", nil, "extrapolates the time to the provided depths (e.g., 5,6) with the models fitted on the depth")
+	return cmd
+}
+
+// runFit fits a model to the benchmark result given by the argument
+func runFit(cmd *cobra.Command, args []string) error {
+	model, _ := cmd.Flags().GetString("model")
+	extrapolateDepths, _ := cmd.Flags().GetIntSlice("extrapolateDepth")
+
+	result, err := importResultFile(args[0])
+	if err != nil {
+		return err
+	}
+	data := result.Means()
+	// the same slices are fitted, which are plotted by the plot command
+	spec := sweep.FromData(data)
+	spec.Slices = sweep.PaperSlices()
+	fits, err := draw.FitTimeComplexities(data, spec, model, extrapolateDepths)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Fitting %s model to %s (times in ms)\n\n", model, args[0])
+	err = fitting.WriteTable(cmd.OutOrStdout(), fits)
+	if err != nil {
+		return err
+	}
+	name := "fit_" + strings.TrimSuffix(strings.TrimSuffix(filepath.Base(args[0]), ".json"), ".csv")
+	return storedata.ExportDataToJSON(storedata.DataDir(), name, fits, "", "  ")
+}
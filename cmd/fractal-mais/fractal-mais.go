@@ -2,28 +2,48 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"github.com/spf13/cobra"
-	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/internal/benchmarking"
-	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/internal/measurement"
+	"github.com/spf13/pflag"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/internal/config"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/internal/logging"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/draw"
-	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
 	"log"
 	"os"
-	"strconv"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 	"time"
 )
 
-var depth int
-var appNumber int
-var iterations int
-var maxNumInstances int
-var genFig []string
-var genJointFig []string
+var outDir string
+var dataDir string
+var formats []string
+var dpi int
+var greyScale bool
+var fitModel string
+var configFile string
+var logLevel string
+var logFormat string
+var timeout time.Duration
+
+// stopTimeout releases the context with the timeout of the command (if any)
+var stopTimeout context.CancelFunc = func() {}
+
+// cfg is a configuration loaded from --config, its sections fill the flags, which were not set on the command line
+var cfg = &config.Config{}
 
 // The main entry point
 func main() {
-	if err := fractalMAIS().Execute(); err != nil {
-		println(err)
+	// interruption (e.g., Ctrl+C) cancels the context, so that long-running commands store partial results and stop
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := fractalMAIS().ExecuteContext(ctx)
+	stopTimeout()
+	stop()
+	// error is already printed by cobra
+	if err != nil {
 		os.Exit(1)
 	}
 }
@@ -33,172 +53,167 @@ func fractalMAIS() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "fractal-mais",
 		Short: "Fractal Multi-Agent IoT System benchmarker",
-		Long: "Fractal Multi-Agent IoT System, or Fractal MAIS, implements a System Model based on means of Fractal theory" +
-			"which covers scalable MAIS systems. This tool implements a benchmarker, drawer (to plot results of a benchmark)" +
+		Long: "Fractal Multi-Agent IoT System, or Fractal MAIS, implements a System Model based on means of Fractal theory " +
+			"which covers scalable MAIS systems. This tool implements a benchmarker, drawer (to plot results of a benchmark) " +
 			"and ME-ERT-CORE (with its predecessor ERT-CORE) packages.",
-		RunE: runFractalMAIS,
-	}
-	// adding flags - default values are false
-	cmd.PersistentFlags().Bool("example", false, "generates in a single run a random Fractal MAIS and plots a figure of it")
-	cmd.PersistentFlags().Bool("benchmark", false, "performs a time complexity benchmarking of a Fractal MAIS system model algorithm and ME-ERT-CORE algorithms")
-	cmd.PersistentFlags().Bool("hardcoded", false, "performs a hardcoded benchmarking (with hardcoded values")
-	cmd.PersistentFlags().Bool("benchFMAIS", false, "performs a time complexity benchmarking of a Fractal MAIS system model algorithm")
-	cmd.PersistentFlags().Bool("benchMeErtCORE", false, "performs a time complexity benchmarking of a ME-ERT-CORE algorithm")
-	cmd.PersistentFlags().Bool("benchMeErtCOREoptimized", false, "performs a time complexity benchmarking of an optimized version of ME-ERT-CORE algorithm")
-	// ToDo - this is to be done in the near future..
-	//cmd.PersistentFlags().Bool("benchErtCORE", false, "performs a time complexity benchmarking of a ERT-CORE algorithms")
-	cmd.PersistentFlags().IntVar(&iterations, "iterations", 25000, "sets a number of iterations per single parameter set to perform")
-	cmd.PersistentFlags().IntVar(&depth, "depth", 4, "sets a depth of a system model")
-	cmd.PersistentFlags().IntVar(&appNumber, "appNumber", 100, "number of applications to be deployed")
-	cmd.PersistentFlags().IntVar(&maxNumInstances, "maxNumInstances", 100, "maximum number of instances to be deployed by application")
-	cmd.PersistentFlags().StringArrayVar(&genFig, "generateFigures", nil, "generates figures based on the provided benchmarked data")
-	cmd.PersistentFlags().StringArrayVar(&genJointFig, "generateJointFigure", nil, "generates joint figure based on the provided benchmarked data")
-	cmd.PersistentFlags().Bool("docker", false, "indicates that the benchmarking is done in Docker container")
-	cmd.PersistentFlags().Bool("greyScale", false, "indicates that the plotter should generate figures in grey scale")
-	cmd.PersistentFlags().Bool("runMeasurement", false, "runs measurement for FMAIS of Depth 2, 3 and 4")
-	cmd.PersistentFlags().Bool("meertcore", false, "To indicate to the plotter to draw reliability lines")
+		Args: cobra.NoArgs,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			// flags and arguments were parsed, usage is not printed on failures of the command itself
+			cmd.SilenceUsage = true
+			err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			err = configureLogging()
+			if err != nil {
+				return err
+			}
+			configureTimeout(cmd)
+			return configureOutput()
+		},
+	}
+	cmd.PersistentFlags().StringVar(&outDir, "outDir", "figures/", "sets a directory, where the figures are stored")
+	cmd.PersistentFlags().StringVar(&dataDir, "dataDir", "data/", "sets a directory, where the data are stored and from where they are read")
+	cmd.PersistentFlags().StringSliceVar(&formats, "formats", nil, "sets formats of the figures (png, svg, eps, pdf, tex), by default each figure is stored in its own default formats")
+	cmd.PersistentFlags().IntVar(&dpi, "dpi", 0, "sets a resolution of the PNG figures (default resolution of the plotter is used, if not set)")
+	cmd.PersistentFlags().BoolVar(&greyScale, "greyScale", false, "indicates that the plotter should generate figures in grey scale")
+	cmd.PersistentFlags().StringVar(&configFile, "config", "", "sets a YAML or JSON configuration file, flags set on the command line take precedence over it")
+	cmd.PersistentFlags().StringVar(&logLevel, "logLevel", "info", "sets a level of the log records (debug, info, warn, error), debug level includes dumps of the System Models on failures")
+	cmd.PersistentFlags().StringVar(&logFormat, "logFormat", logging.FormatText, "sets a format of the log records (text, json)")
+	cmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "sets a time limit of the command (e.g., 90m), long-running commands store partial results once it is exceeded (0 means no limit)")
+	cmd.PersistentFlags().StringVar(&fitModel, "fit", "", "overlays curves of a model (exponential, polynomial[degree], power-law or best) fitted to each line of the complexity figures")
+	cmd.RunE = registerLegacyFlags(cmd).run
+
+	cmd.AddCommand(generateCommand())
+	cmd.AddCommand(exportCommand())
+	cmd.AddCommand(benchCommand())
+	cmd.AddCommand(measureCommand())
+	cmd.AddCommand(evaluateCommand())
+	cmd.AddCommand(plotCommand())
+	cmd.AddCommand(reportCommand())
+	cmd.AddCommand(compareCommand())
+	cmd.AddCommand(fitCommand())
 	return cmd
 }
 
-// runFractalMAIS performs main logic of the CLI - either showcases an example System Model or performs benchmarking
-func runFractalMAIS(cmd *cobra.Command, _ []string) error {
-	example, _ := cmd.Flags().GetBool("example")
-	benchmark, _ := cmd.Flags().GetBool("benchmark")
-	hardcoded, _ := cmd.Flags().GetBool("hardcoded")
-	benchFMAIS, _ := cmd.Flags().GetBool("benchFMAIS")
-	benchMeErtCORE, _ := cmd.Flags().GetBool("benchMeErtCORE")
-	benchMeErtCOREoptimized, _ := cmd.Flags().GetBool("benchMeErtCOREoptimized")
-	//benchErtCORE, _ := cmd.Flags().GetBool("benchErtCORE")
-	iterations, _ = cmd.Flags().GetInt("iterations")
-	genFig, _ = cmd.Flags().GetStringArray("generateFigures")
-	docker, _ := cmd.Flags().GetBool("docker")
-	greyScale, _ := cmd.Flags().GetBool("greyScale")
-	runMeasurement, _ := cmd.Flags().GetBool("runMeasurement")
-	meertcore, _ := cmd.Flags().GetBool("meertcore")
-
-	log.Printf("Starting fractal-mais\nExample: %v\nBenchmarking: %v\n"+
-		"Hardcoded: %v\nBenchmark Fractal MAIS: %v\nBenchmark ME-ERT-CORE: %v\n"+
-		"Depth: %v\nNumber of applications: %v\nMaximum number of instances per application: %v\n"+
-		"Data file(s) provided: %v\nBenchmarked in Docker: %v\n",
-		example, benchmark, hardcoded, benchFMAIS, benchMeErtCORE,
-		depth, appNumber, maxNumInstances, genFig, docker)
-
-	if example {
-		generateExampleSystemModel()
-	}
-	if benchmark && hardcoded {
-		err := benchmarking.BenchSystemModelNoParam(docker, greyScale)
-		if err != nil {
-			return err
-		}
-		err = benchmarking.BenchMeErtCORENoParam(docker, greyScale)
-		if err != nil {
-			return err
-		}
+// modelFlags are parameters of a randomly generated System Model
+type modelFlags struct {
+	depth           int // depth of the System Model
+	appNumber       int // number of applications to be deployed
+	maxNumInstances int // maximum number of instances deployed by application
+}
+
+// register adds the flags to the flag set
+func (f *modelFlags) register(flags *pflag.FlagSet) {
+	flags.IntVar(&f.depth, "depth", 4, "sets a depth of a system model")
+	flags.IntVar(&f.appNumber, "appNumber", 100, "number of applications to be deployed")
+	flags.IntVar(&f.maxNumInstances, "maxNumInstances", 100, "maximum number of instances to be deployed by application")
+}
+
+// validate checks that the System Model can be generated with the parameters
+func (f *modelFlags) validate() error {
+	if f.depth < 1 {
+		return fmt.Errorf("--depth should be positive, got %d", f.depth)
 	}
-	if benchmark && !hardcoded {
-		err := benchmarking.BenchSystemModel(depth, appNumber, maxNumInstances, iterations, docker, greyScale)
-		if err != nil {
-			return err
-		}
-		err = benchmarking.BenchMeErtCORE(depth, appNumber, maxNumInstances, iterations, docker, greyScale)
-		if err != nil {
-			return err
-		}
+	if f.appNumber < 1 {
+		return fmt.Errorf("--appNumber should be positive, got %d", f.appNumber)
 	}
-	if benchFMAIS && hardcoded {
-		err := benchmarking.BenchSystemModelNoParam(docker, greyScale)
-		if err != nil {
-			return err
-		}
+	if f.maxNumInstances < 1 {
+		return fmt.Errorf("--maxNumInstances should be positive, got %d", f.maxNumInstances)
 	}
-	if benchFMAIS && !hardcoded {
-		err := benchmarking.BenchSystemModel(depth, appNumber, maxNumInstances, iterations, docker, greyScale)
-		if err != nil {
-			return err
-		}
+	return nil
+}
+
+// importResultFile imports a result document from a file. File name without a directory is read from the data directory.
+func importResultFile(path string) (*storedata.ResultDocument, error) {
+	dir, fileName := filepath.Split(path)
+	if dir == "" {
+		dir = storedata.DataDir()
 	}
-	if benchMeErtCORE && hardcoded {
-		err := benchmarking.BenchMeErtCORENoParam(docker, greyScale)
-		if err != nil {
-			return err
-		}
+	return storedata.ImportResult(dir, fileName)
+}
+
+// loadConfig loads the configuration file (if any) and applies its output section to the flags
+func loadConfig(cmd *cobra.Command) error {
+	cfg = &config.Config{}
+	if configFile == "" {
+		return nil
 	}
-	if benchMeErtCORE && !hardcoded {
-		err := benchmarking.BenchMeErtCORE(depth, appNumber, maxNumInstances, iterations, docker, greyScale)
-		if err != nil {
-			return err
-		}
+	var err error
+	cfg, err = config.Load(configFile)
+	if err != nil {
+		return err
 	}
+	return config.Apply(cfg.Output, cmd.Flags())
+}
 
-	if benchMeErtCOREoptimized {
-		err := benchmarking.BenchMeErtCoreOptimized(maxNumInstances, appNumber, docker, greyScale)
-		if err != nil {
-			return err
-		}
+// saveEffectiveConfig stores the effective configuration of a command, i.e., values of its flags (whether they were
+// set on the command line, in the configuration file, or they are the defaults), in the data directory. Timestamp should
+// be the one of the result files of the run, so that the configuration is stored next to them.
+func saveEffectiveConfig(cmd *cobra.Command, name, timestamp string, effective *config.Config) error {
+	effective.Output = &config.Output{}
+	err := config.Capture(effective.Output, cmd.Flags())
+	if err != nil {
+		return err
 	}
-
-	//if benchErtCORE {
-	//	err := benchmarking.BenchErtCORE(docker)
-	//	if err != nil {
-	//		return err
-	//	}
-	//}
-
-	if genFig != nil {
-		err := draw.PlotFigures(greyScale, genFig...)
+	if effective.Benchmark != nil {
+		err = config.Capture(effective.Benchmark, cmd.Flags())
 		if err != nil {
 			return err
 		}
 	}
-
-	if genJointFig != nil && !meertcore {
-		err := draw.PlotJointFigure(greyScale, false, genJointFig...)
+	if effective.Measurement != nil {
+		err = config.Capture(effective.Measurement, cmd.Flags())
 		if err != nil {
 			return err
 		}
 	}
-
-	if genJointFig != nil && meertcore {
-		err := draw.PlotJointFigure(greyScale, true, genJointFig...)
-		if err != nil {
-			return err
-		}
+	err = os.MkdirAll(storedata.DataDir(), 0755)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(storedata.DataDir(), "config_"+name+"_"+timestamp+".json")
+	err = effective.Save(path)
+	if err != nil {
+		return fmt.Errorf("couldn't store the effective configuration: %w", err)
 	}
+	log.Printf("Effective configuration is stored in %s\n", path)
+	return nil
+}
 
-	if runMeasurement {
-		log.Printf("Running measurement\n")
-		err := measurement.RunMeasurement()
-		if err != nil {
-			return err
-		}
+// configureTimeout limits the time of the command, if --timeout is set
+func configureTimeout(cmd *cobra.Command) {
+	if timeout <= 0 {
+		return
 	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	cmd.SetContext(ctx)
+	stopTimeout = cancel
+}
 
-	return nil
+// configureLogging sets a default logger with the chosen level and format, which is used by all packages
+func configureLogging() error {
+	return logging.Configure(os.Stderr, logLevel, logFormat)
 }
 
-// generateExampleSystemModel generates System Model example
-func generateExampleSystemModel() {
-	// Generating a system Model
-	sm := systemmodel.SystemModel{}
-	// defining list of application names
-	names := systemmodel.GenerateAppNames(appNumber)
-	sm.InitializeSystemModel(appNumber, depth)
-	sm.CreateRandomApplications(names, 1, maxNumInstances)
-	start := time.Now()
-	sm.GenerateSystemModel()
-	duration := time.Since(start)
-	log.Printf("It took %d us to generate a random System Model\n", duration.Microseconds())
-
-	// Drawing a figure of System Model
-	d := draw.Draw{}
-	d.InitializeDrawStruct()
-	d.FigureName = "Random System Model with " + strconv.FormatInt(sm.GetTotalNumberOfInstances(), 10) + " instances"
-	start = time.Now()
-	err := d.DrawSystemModel(&sm)
+// configureOutput sets directories, where the figures and the data are stored, and formats of the figures
+func configureOutput() error {
+	figureFormats, err := draw.ParseFormats(formats...)
+	if err != nil {
+		return err
+	}
+	err = draw.SetDefaultOutputTarget(draw.OutputTarget{
+		Dir:     outDir,
+		Formats: figureFormats,
+		DPI:     dpi,
+	})
+	if err != nil {
+		return err
+	}
+	err = draw.SetFitOverlay(fitModel)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	duration = time.Since(start)
-	log.Printf("It took %d us to draw a System Model Figure\n", duration.Microseconds())
+	storedata.SetDataDir(dataDir)
+	return nil
 }
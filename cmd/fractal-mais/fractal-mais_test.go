@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/internal/config"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/meertcore"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"gotest.tools/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLegacyActions(t *testing.T) {
+	legacy := registerLegacyFlags(&cobra.Command{})
+	assert.Equal(t, len(legacy.actions()), 0)
+
+	// combination of the flags requesting the same benchmark runs it once
+	legacy.benchmark = true
+	legacy.benchFMAIS = true
+	legacy.example = true
+	legacy.runMeasurement = true
+	actions := legacy.actions()
+	t.Logf("Legacy flags request %v", actions)
+	assert.DeepEqual(t, actions, []string{actionGenerate, actionBenchFMAIS, actionBenchMeErtCORE, actionMeasure})
+}
+
+func TestCommandValidation(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"bench", "fmais", "--iterations", "0"}, "--iterations should be positive"},
+		{[]string{"bench", "meertcore", "--appNumber", "0"}, "--appNumber should be positive"},
+		{[]string{"bench", "ertcore"}, "not implemented"},
+		{[]string{"generate", "--depth", "0"}, "--depth should be positive"},
+		{[]string{"generate", "--layout", "circle", "--depth", "1", "--appNumber", "1", "--maxNumInstances", "1"}, "layout"},
+		{[]string{"export", "graph.svg", "--depth", "1"}, "unknown graph format"},
+		{[]string{"plot", "--meertcore", "benchmark.json"}, "--joint only"},
+		{[]string{"plot"}, "requires at least 1 arg"},
+		{[]string{"measure", "simulate", "--ticks", "0"}, "--ticks should be positive"},
+		{[]string{"measure", "--scenario", "missing.yaml"}, "no such file"},
+		{[]string{"bench", "optimized", "--appNumber", "3"}, "numbers of applications of the optimized benchmark (at least 6): start 6 is greater than stop 3"},
+		{[]string{"evaluate"}, "at least one of --model and --whatIf"},
+		{[]string{"evaluate", "--model", "sm.json", "--whatIf", "scenarios.json"}, "no such file"},
+		{[]string{"evaluate", "--model", "sm.json", "--method", "fast"}, "unknown ME-ERT-CORE method"},
+		{[]string{"evaluate", "--model", "sm.json", "--output", "yaml"}, "unknown output format"},
+		{[]string{"--formats", "bmp", "generate"}, "unsupported figure format"},
+		{[]string{"--logLevel", "verbose", "generate"}, "unknown log level"},
+		{[]string{"--logFormat", "xml", "generate"}, "unknown log format"},
+		{[]string{"--timeout", "1ns", "generate", "--depth", "2", "--appNumber", "1", "--maxNumInstances", "1"}, "context deadline exceeded"},
+		{[]string{"--exportGraph", "graph.svg", "--depth", "1"}, "unknown graph format"},
+	} {
+		cmd := fractalMAIS()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(append(tc.args, "--outDir", t.TempDir(), "--dataDir", t.TempDir()))
+		err := cmd.Execute()
+		assert.ErrorContains(t, err, tc.err, "%v", tc.args)
+	}
+}
+
+func TestEvaluateModel(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "sm.json")
+	assert.NilError(t, systemmodel.CreateSystemModelDepth4().SaveSystemModel(fileName))
+
+	for _, method := range []string{meertcore.MethodPerDefinition, meertcore.MethodOptimized} {
+		cmd := fractalMAIS()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"evaluate", "--model", fileName, "--method", method, "--output", "json",
+			"--outDir", t.TempDir(), "--dataDir", t.TempDir()})
+		assert.NilError(t, cmd.Execute())
+		t.Logf("Evaluation with %s method is\n%s", method, out.String())
+
+		var evaluation meertcore.Evaluation
+		assert.NilError(t, json.Unmarshal(out.Bytes(), &evaluation))
+		assert.Equal(t, evaluation.Method, method)
+		assert.Equal(t, fmt.Sprintf("%.6f", evaluation.Reliability), "0.147176")
+		assert.Equal(t, len(evaluation.Applications), 4)
+	}
+}
+
+func TestEvaluateWhatIfModel(t *testing.T) {
+	dir := t.TempDir()
+	modelFile := filepath.Join(dir, "sm.json")
+	assert.NilError(t, systemmodel.CreateSystemModelDepth3().SaveSystemModel(modelFile))
+	scenariosFile := filepath.Join(dir, "scenarios.json")
+	assert.NilError(t, os.WriteFile(scenariosFile, []byte(`[
+  {"name": "App#1 degrades", "perturbations": [{"type": "scale-application", "target": "App#1", "factor": 0.8}]}
+]`), 0644))
+
+	dataDir := t.TempDir()
+	cmd := fractalMAIS()
+	cmd.SetArgs([]string{"evaluate", "--whatIf", scenariosFile, "--model", modelFile,
+		"--outDir", t.TempDir(), "--dataDir", dataDir})
+	assert.NilError(t, cmd.Execute())
+
+	// scenarios are evaluated on the loaded FMAIS, not on the measurement FMAIS of the default depth
+	files, err := filepath.Glob(filepath.Join(dataDir, "whatif_fmais_depth_3_*.json"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(files), 1)
+}
+
+func TestConfig(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	assert.NilError(t, os.WriteFile(configFile, []byte(`
+benchmark:
+  depth: 0
+measurement:
+  wideStep: 0
+  depth: 2
+  ticks: 5
+  seed: 7
+`), 0644))
+
+	for _, tc := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"bench", "fmais"}, "--depth should be positive"},
+		// flags set on the command line take precedence over the configuration
+		{[]string{"bench", "fmais", "--depth", "1", "--iterations", "0"}, "--iterations should be positive"},
+		{[]string{"measure"}, "step of the number of applications"},
+		{[]string{"--depth", "0", "--benchFMAIS"}, "--depth should be positive"},
+	} {
+		cmd := fractalMAIS()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(append(tc.args, "--config", configFile, "--outDir", t.TempDir(), "--dataDir", t.TempDir()))
+		err := cmd.Execute()
+		assert.ErrorContains(t, err, tc.err, "%v", tc.args)
+	}
+
+	// effective configuration is stored next to the results
+	dataDir := filepath.Join(dir, "data")
+	cmd := fractalMAIS()
+	cmd.SetArgs([]string{"measure", "simulate", "--ticks", "3", "--config", configFile, "--outDir", t.TempDir(), "--dataDir", dataDir})
+	assert.NilError(t, cmd.Execute())
+	files, err := filepath.Glob(filepath.Join(dataDir, "config_simulation_*.json"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(files), 1)
+	effective, err := config.Load(files[0])
+	assert.NilError(t, err)
+	assert.Equal(t, *effective.Measurement.Depth, 2)
+	assert.Equal(t, *effective.Measurement.Ticks, 3)
+	assert.Equal(t, *effective.Measurement.Seed, int64(7))
+	assert.Equal(t, *effective.Output.DataDir, dataDir)
+	assert.Assert(t, effective.Benchmark == nil)
+}
+
+func TestBenchEffectiveConfig(t *testing.T) {
+	// effective configuration of each benchmark carries the timestamp of its result files
+	dataDir := t.TempDir()
+	cmd := fractalMAIS()
+	cmd.SetArgs([]string{"bench", "all", "--depths", "2", "--apps", "1", "--instances", "1", "--iterations", "2",
+		"--outDir", t.TempDir(), "--dataDir", dataDir})
+	assert.NilError(t, cmd.Execute())
+	for _, benchmark := range []string{benchFMAIS, benchMeErtCORE} {
+		files, err := filepath.Glob(filepath.Join(dataDir, "config_bench_"+benchmark+"_*.json"))
+		assert.NilError(t, err)
+		assert.Equal(t, len(files), 1, benchmark)
+		t.Logf("Effective configuration of %s is stored in %s", benchmark, files[0])
+		ts := strings.TrimPrefix(filepath.Base(files[0]), "config_bench_"+benchmark+"_")
+		_, err = os.Stat(filepath.Join(dataDir, "benchmark_"+benchmark+"_"+ts))
+		assert.NilError(t, err)
+	}
+}
@@ -0,0 +1,155 @@
+// main package structures experiment which measures time complexity of the algorithms. This file in particular
+// implements commands, which generate a random FMAIS and either draw it, or export it as a graph.
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/draw"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/meertcore"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// generateCommand implements a command, which generates a random FMAIS and plots a figure of it
+func generateCommand() *cobra.Command {
+	model := &modelFlags{}
+	var layout string
+	var heatMap bool
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generates a random Fractal MAIS and plots a figure of it",
+		Long: "Generates a random Fractal MAIS of a given depth and plots a figure of it. With --heatMap, reliability of the " +
+			"FMAIS is computed with ME-ERT-CORE and nodes are coloured by their reliability.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			err := model.validate()
+			if err != nil {
+				return err
+			}
+			return generateExampleSystemModel(cmd.Context(), *model, layout, heatMap)
+		},
+	}
+	model.register(cmd.Flags())
+	cmd.Flags().StringVar(&layout, "layout", "tidy", "sets a layout of the Fractal MAIS figure (tidy, radial or layered)")
+	cmd.Flags().BoolVar(&heatMap, "heatMap", false, "colours the nodes of the Fractal MAIS figure by their reliability (computed with ME-ERT-CORE) and sets edge width by priority")
+	return cmd
+}
+
+// exportCommand implements a command, which generates a random FMAIS and exports it as a graph
+func exportCommand() *cobra.Command {
+	model := &modelFlags{}
+	var collapseApps bool
+	cmd := &cobra.Command{
+		Use:   "export <file>",
+		Short: "Generates a random Fractal MAIS and exports it as a graph",
+		Long: "Generates a random Fractal MAIS of a given depth and exports it to the file in Graphviz DOT (.dot, .gv) or " +
+			"Mermaid (.mmd) format. Format is determined by the file extension.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := model.validate()
+			if err != nil {
+				return err
+			}
+			return exportSystemModelGraph(cmd.Context(), args[0], *model, collapseApps)
+		},
+	}
+	model.register(cmd.Flags())
+	cmd.Flags().BoolVar(&collapseApps, "collapseApps", false, "collapses instances of an application deployed by the same VI into a single node in the exported graph")
+	return cmd
+}
+
+// generateExampleSystemModel generates System Model example. If heatMap is true, reliability of the System Model
+// is computed with ME-ERT-CORE and the figure is rendered as a reliability heat map.
+func generateExampleSystemModel(ctx context.Context, model modelFlags, layout string, heatMap bool) error {
+	l, err := draw.ParseLayout(layout)
+	if err != nil {
+		return err
+	}
+
+	// Generating a system Model
+	sm := systemmodel.SystemModel{}
+	// defining list of application names
+	names := systemmodel.GenerateAppNames(model.appNumber)
+	sm.InitializeSystemModel(model.appNumber, model.depth)
+	sm.CreateRandomApplications(names, 1, model.maxNumInstances)
+	start := time.Now()
+	_, err = sm.GenerateSystemModelContext(ctx)
+	if err != nil {
+		return err
+	}
+	duration := time.Since(start)
+	log.Printf("It took %d us to generate a random System Model\n", duration.Microseconds())
+
+	if heatMap {
+		sm.SetApplicationPrioritiesRandom()
+		err = sm.SetInstancePrioritiesRandom()
+		if err != nil {
+			return err
+		}
+		err = sm.SetInstanceReliabilitiesRandom()
+		if err != nil {
+			return err
+		}
+		me := meertcore.MeErtCore{
+			SystemModel: &sm,
+		}
+		rel, err := me.ComputeReliabilityPerDefinitionContext(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("Reliability of a random System Model is %.6f\n", rel)
+	}
+
+	// Drawing a figure of System Model
+	d := draw.Draw{}
+	d.InitializeDrawStruct().SetLayout(l).SetHeatMap(heatMap)
+	d.FigureName = "Random System Model with " + strconv.FormatInt(sm.GetTotalNumberOfInstances(), 10) + " instances"
+	start = time.Now()
+	err = d.DrawSystemModel(&sm)
+	if err != nil {
+		return err
+	}
+	duration = time.Since(start)
+	log.Printf("It took %d us to draw a System Model Figure\n", duration.Microseconds())
+	return nil
+}
+
+// exportSystemModelGraph generates a random System Model and exports it as a graph to the file.
+// Format is determined by the file extension.
+func exportSystemModelGraph(ctx context.Context, fileName string, model modelFlags, collapseApps bool) error {
+	export := draw.ExportDOT
+	switch filepath.Ext(fileName) {
+	case ".dot", ".gv":
+	case ".mmd":
+		export = draw.ExportMermaid
+	default:
+		return fmt.Errorf("unknown graph format of %s, expected .dot, .gv or .mmd file", fileName)
+	}
+
+	sm := systemmodel.SystemModel{}
+	sm.InitializeSystemModel(model.appNumber, model.depth)
+	sm.CreateRandomApplications(systemmodel.GenerateAppNames(model.appNumber), 1, model.maxNumInstances)
+	_, err := sm.GenerateSystemModelContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = export(&sm, f, collapseApps)
+	if err != nil {
+		return err
+	}
+	log.Printf("System Model with %d instances was exported to %s\n", sm.GetTotalNumberOfInstances(), fileName)
+	return nil
+}
@@ -0,0 +1,193 @@
+// main package structures experiment which measures time complexity of the algorithms. This file in particular
+// implements a compatibility shim of the flags, which were used before the CLI was split into subcommands. Legacy
+// flags are hidden and they are translated to the subcommands, each of them is run at most once.
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/internal/config"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/internal/measurement"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/draw"
+	"log"
+)
+
+// actions of the legacy flags, they are named by the equivalent commands
+const (
+	actionGenerate       = "generate"
+	actionBenchFMAIS     = "bench " + benchFMAIS
+	actionBenchMeErtCORE = "bench " + benchMeErtCORE
+	actionBenchOptimized = "bench " + benchOptimized
+	actionPlot           = "plot"
+	actionPlotJoint      = "plot --joint"
+	actionReport         = "report"
+	actionExport         = "export"
+	actionEvaluate       = "evaluate"
+	actionSimulate       = "measure simulate"
+	actionMeasure        = "measure"
+)
+
+// legacyFlags are flags of the root command used before the CLI was split into subcommands
+type legacyFlags struct {
+	bench benchFlags // parameters of the benchmarks, --depth, --appNumber and --maxNumInstances are shared by all actions
+
+	example                 bool
+	heatMap                 bool
+	layout                  string
+	benchmark               bool
+	benchFMAIS              bool
+	benchMeErtCORE          bool
+	benchMeErtCOREoptimized bool
+	genFig                  []string
+	genJointFig             []string
+	genReport               []string
+	meertcore               bool
+	exportGraph             string
+	collapseApps            bool
+	whatIf                  string
+	simulate                bool
+	ticks                   int
+	runMeasurement          bool
+}
+
+// registerLegacyFlags adds hidden legacy flags to the root command and returns their values. Flags requesting
+// an action are deprecated in favour of the equivalent command.
+func registerLegacyFlags(cmd *cobra.Command) *legacyFlags {
+	legacy := &legacyFlags{}
+	flags := cmd.Flags()
+	legacy.bench.register(flags)
+	flags.BoolVar(&legacy.example, "example", false, "generates in a single run a random Fractal MAIS and plots a figure of it")
+	flags.BoolVar(&legacy.heatMap, "heatMap", false, "colours the nodes of the example Fractal MAIS figure by their reliability")
+	flags.StringVar(&legacy.layout, "layout", "tidy", "sets a layout of the Fractal MAIS figure (tidy, radial or layered)")
+	flags.BoolVar(&legacy.benchmark, "benchmark", false, "performs a time complexity benchmarking of a Fractal MAIS system model algorithm and ME-ERT-CORE algorithms")
+	flags.BoolVar(&legacy.benchFMAIS, "benchFMAIS", false, "performs a time complexity benchmarking of a Fractal MAIS system model algorithm")
+	flags.BoolVar(&legacy.benchMeErtCORE, "benchMeErtCORE", false, "performs a time complexity benchmarking of a ME-ERT-CORE algorithm")
+	flags.BoolVar(&legacy.benchMeErtCOREoptimized, "benchMeErtCOREoptimized", false, "performs a time complexity benchmarking of an optimized version of ME-ERT-CORE algorithm")
+	flags.StringArrayVar(&legacy.genFig, "generateFigures", nil, "generates figures based on the provided benchmarked data")
+	flags.StringArrayVar(&legacy.genJointFig, "generateJointFigure", nil, "generates joint figure based on the provided benchmarked data")
+	flags.StringArrayVar(&legacy.genReport, "generateReport", nil, "generates an HTML report based on the provided benchmarked data")
+	flags.BoolVar(&legacy.meertcore, "meertcore", false, "To indicate to the plotter to draw reliability lines")
+	flags.StringVar(&legacy.exportGraph, "exportGraph", "", "generates a random Fractal MAIS and exports it to the provided file")
+	flags.BoolVar(&legacy.collapseApps, "collapseApps", false, "collapses instances of an application deployed by the same VI into a single node in the exported graph")
+	flags.StringVar(&legacy.whatIf, "whatIf", "", "evaluates what-if scenarios defined in the provided JSON file on the measurement FMAIS of a given depth")
+	flags.BoolVar(&legacy.simulate, "simulate", false, "simulates evolution of the measurement FMAIS of a given depth over time")
+	flags.IntVar(&legacy.ticks, "ticks", 300, "sets a number of ticks of the virtual clock to simulate")
+	flags.BoolVar(&legacy.runMeasurement, "runMeasurement", false, "runs measurement for FMAIS of Depth 2, 3 and 4")
+
+	flags.VisitAll(func(f *pflag.Flag) {
+		f.Hidden = true
+	})
+	for name, replacement := range map[string]string{
+		"example":                 actionGenerate,
+		"benchmark":               "bench all",
+		"benchFMAIS":              actionBenchFMAIS,
+		"benchMeErtCORE":          actionBenchMeErtCORE,
+		"benchMeErtCOREoptimized": actionBenchOptimized,
+		"generateFigures":         actionPlot,
+		"generateJointFigure":     actionPlotJoint,
+		"generateReport":          actionReport,
+		"exportGraph":             actionExport,
+		"whatIf":                  actionEvaluate + " --whatIf",
+		"simulate":                actionSimulate,
+		"runMeasurement":          actionMeasure,
+	} {
+		_ = flags.MarkDeprecated(name, "use 'fractal-mais "+replacement+"' instead")
+	}
+	return legacy
+}
+
+// actions returns the commands requested by the legacy flags in the order they were originally run.
+// Each command is returned once, even if it is requested by several flags (e.g., --benchmark and --benchFMAIS).
+func (l *legacyFlags) actions() []string {
+	requested := []struct {
+		action string
+		set    bool
+	}{
+		{actionGenerate, l.example},
+		{actionBenchFMAIS, l.benchmark || l.benchFMAIS},
+		{actionBenchMeErtCORE, l.benchmark || l.benchMeErtCORE},
+		{actionBenchOptimized, l.benchMeErtCOREoptimized},
+		{actionPlot, len(l.genFig) > 0},
+		{actionPlotJoint, len(l.genJointFig) > 0},
+		{actionReport, len(l.genReport) > 0},
+		{actionExport, l.exportGraph != ""},
+		{actionEvaluate, l.whatIf != ""},
+		{actionSimulate, l.simulate},
+		{actionMeasure, l.runMeasurement},
+	}
+	actions := make([]string, 0)
+	for _, r := range requested {
+		if r.set {
+			actions = append(actions, r.action)
+		}
+	}
+	return actions
+}
+
+// run runs the commands requested by the legacy flags, help is printed, if there is none
+func (l *legacyFlags) run(cmd *cobra.Command, _ []string) error {
+	actions := l.actions()
+	if len(actions) == 0 {
+		return cmd.Help()
+	}
+	err := config.Apply(cfg.Benchmark, cmd.Flags())
+	if err != nil {
+		return err
+	}
+
+	// benchmarking package is configured once, before the first benchmark is run
+	configured := false
+	bench := func(benchmark string) error {
+		if !configured {
+			err := l.bench.configure()
+			if err != nil {
+				return err
+			}
+			configured = true
+		}
+		return runBenchmark(cmd.Context(), benchmark, &l.bench)
+	}
+
+	for _, action := range actions {
+		log.Printf("Running '%s' requested by the legacy flags\n", action)
+		switch action {
+		case actionGenerate:
+			err = l.bench.modelFlags.validate()
+			if err == nil {
+				err = generateExampleSystemModel(cmd.Context(), l.bench.modelFlags, l.layout, l.heatMap)
+			}
+		case actionBenchFMAIS:
+			err = bench(benchFMAIS)
+		case actionBenchMeErtCORE:
+			err = bench(benchMeErtCORE)
+		case actionBenchOptimized:
+			err = bench(benchOptimized)
+		case actionPlot:
+			err = draw.PlotFigures(greyScale, l.genFig...)
+		case actionPlotJoint:
+			err = draw.PlotJointFigure(greyScale, l.meertcore, l.genJointFig...)
+		case actionReport:
+			err = generateReport(l.genReport...)
+		case actionExport:
+			err = l.bench.modelFlags.validate()
+			if err == nil {
+				err = exportSystemModelGraph(cmd.Context(), l.exportGraph, l.bench.modelFlags, l.collapseApps)
+			}
+		case actionEvaluate:
+			err = evaluateScenarios(l.whatIf, "", l.bench.depth)
+		case actionSimulate:
+			// --seed is shared with the benchmarks, the simulation used to be seeded with 1 by default
+			seed := l.bench.seed
+			if !cmd.Flags().Changed("seed") {
+				seed = 1
+			}
+			err = simulateSystemModel(l.bench.depth, l.ticks, seed)
+		case actionMeasure:
+			err = measurement.RunMeasurementContext(cmd.Context())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
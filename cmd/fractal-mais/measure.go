@@ -0,0 +1,235 @@
+// main package structures experiment which measures time complexity of the algorithms. This file in particular
+// implements commands working with the measurement FMAIS, i.e., the measurement itself, its simulation and evaluation
+// of what-if scenarios, and evaluation of the FMAIS described in a file.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/internal/config"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/internal/measurement"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/internal/simulation"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/meertcore"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/scenario"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"io"
+	"log"
+	"strconv"
+)
+
+// formats of the evaluation of the FMAIS
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// measureCommand implements a command, which runs the measurement of the reliability of FMAIS of depth 2, 3 and 4
+func measureCommand() *cobra.Command {
+	var seed int64
+	defaults := measurement.DefaultSettings()
+	settings := defaults
+	cmd := &cobra.Command{
+		Use:   "measure",
+		Short: "Runs measurement of the reliability for FMAIS of depth 2, 3 and 4",
+		Long: "Runs measurement of the reliability computed with ME-ERT-CORE for the measurement FMAIS of depth 2, 3 and 4, " +
+			"whose instances follow the reliability scenarios of the paper. With --scenario, measures a scenario loaded " +
+			"from a YAML or JSON file instead. Measured data are stored in the data directory.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			err := config.Apply(cfg.Measurement, cmd.Flags())
+			if err != nil {
+				return err
+			}
+			settings.Seed = seed
+			err = measurement.SetSettings(settings)
+			if err != nil {
+				return err
+			}
+			err = saveEffectiveConfig(cmd, "measurement", storedata.Timestamp(), &config.Config{Measurement: &config.Measurement{}})
+			if err != nil {
+				return err
+			}
+			log.Printf("Running measurement\n")
+			return measurement.RunMeasurementContext(cmd.Context())
+		},
+	}
+	cmd.Flags().Int64Var(&seed, "seed", 0, "sets a seed of the generated reliabilities (0 keeps the generator randomly seeded)")
+	cmd.Flags().Float64Var(&settings.Deviation, "deviation", defaults.Deviation, "sets a deviation of the generated reliabilities around their mean values")
+	cmd.Flags().Float64Var(&settings.WideDeviation, "wideDeviation", defaults.WideDeviation, "sets a deviation of the generated reliabilities in the large-scale measurement")
+	cmd.Flags().IntVar(&settings.WideMaxApps, "wideMaxApps", defaults.WideMaxApps, "sets a maximum number of applications of the large-scale FMAIS")
+	cmd.Flags().IntVar(&settings.WideStep, "wideStep", defaults.WideStep, "sets a step of the number of applications of the large-scale FMAIS")
+	cmd.Flags().StringVar(&settings.Scenario, "scenario", "", "sets a YAML or JSON file with a scenario to measure instead of the scenarios of the paper")
+	cmd.AddCommand(simulateCommand())
+	return cmd
+}
+
+// simulateCommand implements a command, which simulates evolution of the measurement FMAIS over time
+func simulateCommand() *cobra.Command {
+	var depth int
+	var ticks int
+	var seed int64
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Simulates evolution of the measurement FMAIS over time",
+		Long: "Simulates evolution of the measurement FMAIS of a given depth (2, 3 or 4) over a virtual clock and plots " +
+			"its reliability and availability.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			err := config.Apply(cfg.Measurement, cmd.Flags())
+			if err != nil {
+				return err
+			}
+			if ticks < 1 {
+				return fmt.Errorf("--ticks should be positive, got %d", ticks)
+			}
+			err = saveEffectiveConfig(cmd, "simulation", storedata.Timestamp(), &config.Config{Measurement: &config.Measurement{}})
+			if err != nil {
+				return err
+			}
+			return simulateSystemModel(depth, ticks, seed)
+		},
+	}
+	cmd.Flags().IntVar(&depth, "depth", 4, "sets a depth of the measurement FMAIS (2, 3 or 4)")
+	cmd.Flags().IntVar(&ticks, "ticks", 300, "sets a number of ticks of the virtual clock to simulate")
+	cmd.Flags().Int64Var(&seed, "seed", 1, "sets a seed of the simulation")
+	return cmd
+}
+
+// evaluateCommand implements a command, which evaluates reliability of the FMAIS, either of the one described in a file,
+// or of the measurement FMAIS under what-if scenarios
+func evaluateCommand() *cobra.Command {
+	var depth int
+	var whatIf string
+	var model string
+	var method string
+	var output string
+	cmd := &cobra.Command{
+		Use:   "evaluate",
+		Short: "Evaluates reliability of a given FMAIS or what-if scenarios on the measurement FMAIS",
+		Long: "With --model, loads the FMAIS (including reliabilities and priorities of its instances) from a JSON file, " +
+			"computes its reliability with a chosen ME-ERT-CORE method and prints it together with reliabilities and chain " +
+			"coefficients of each application. With --whatIf, evaluates what-if scenarios defined in a JSON file on the " +
+			"FMAIS from --model, or on the measurement FMAIS of a given depth (2, 3 or 4), if --model is not set, i.e., " +
+			"computes reliability of the FMAIS after each scenario is applied. Results of the scenarios are stored in " +
+			"the data directory.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if model == "" && whatIf == "" {
+				return fmt.Errorf("at least one of --model and --whatIf should be set")
+			}
+			if whatIf != "" {
+				return evaluateScenarios(whatIf, model, depth)
+			}
+			return evaluateSystemModel(cmd.OutOrStdout(), model, method, output)
+		},
+	}
+	cmd.Flags().IntVar(&depth, "depth", 4, "sets a depth of the measurement FMAIS (2, 3 or 4), on which what-if scenarios are evaluated without --model")
+	cmd.Flags().StringVar(&whatIf, "whatIf", "", "sets a JSON file with the what-if scenarios")
+	cmd.Flags().StringVar(&model, "model", "", "sets a JSON file with the FMAIS to evaluate")
+	cmd.Flags().StringVar(&method, "method", meertcore.MethodPerDefinition, "sets a ME-ERT-CORE method ("+
+		meertcore.MethodPerDefinition+" or "+meertcore.MethodOptimized+") to evaluate the FMAIS from --model")
+	cmd.Flags().StringVar(&output, "output", outputTable, "sets a format of the evaluation of the FMAIS from --model ("+
+		outputTable+" or "+outputJSON+")")
+	return cmd
+}
+
+// evaluateSystemModel computes reliability of the FMAIS stored in a file with a given ME-ERT-CORE method and writes
+// the evaluation in a given format
+func evaluateSystemModel(w io.Writer, fileName, method, output string) error {
+	if output != outputTable && output != outputJSON {
+		return fmt.Errorf("unknown output format %q (expected %s or %s)", output, outputTable, outputJSON)
+	}
+	method, err := meertcore.ParseMethod(method)
+	if err != nil {
+		return err
+	}
+
+	sm, err := systemmodel.LoadSystemModel(fileName)
+	if err != nil {
+		return err
+	}
+	evaluation, err := meertcore.Evaluate(sm, method)
+	if err != nil {
+		return err
+	}
+
+	if output == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", " ")
+		return enc.Encode(evaluation)
+	}
+	return evaluation.WriteTable(w)
+}
+
+// evaluateScenarios evaluates what-if scenarios defined in a file on the FMAIS loaded from a model file, or on
+// the measurement FMAIS of a given depth, if no model file is given
+func evaluateScenarios(fileName, model string, depth int) error {
+	scenarios, err := scenario.LoadScenarios(fileName)
+	if err != nil {
+		return err
+	}
+
+	var sm *systemmodel.SystemModel
+	if model != "" {
+		sm, err = systemmodel.LoadSystemModel(model)
+	} else {
+		sm, err = measurementSystemModel(depth)
+	}
+	if err != nil {
+		return err
+	}
+	depth = sm.Depth
+
+	results, err := scenario.Evaluate(sm, scenarios...)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Evaluated %d what-if scenarios on FMAIS of depth %d\n", len(results), depth)
+	for _, r := range results {
+		log.Printf("%-40s baseline %.6f, reliability %.6f, delta %+.6f\n", r.Name, r.BaselineReliability, r.Reliability, r.Delta)
+	}
+
+	return storedata.ExportDataToJSON(storedata.DataDir(), "whatif_fmais_depth_"+strconv.Itoa(depth)+"_"+storedata.Timestamp(), results, "", " ")
+}
+
+// simulateSystemModel simulates evolution of the measurement FMAIS of a given depth over time
+func simulateSystemModel(depth, ticks int, seed int64) error {
+	sm, err := measurementSystemModel(depth)
+	if err != nil {
+		return err
+	}
+
+	config := simulation.DefaultConfig()
+	config.Ticks = ticks
+	config.Seed = seed
+	samples, err := simulation.RunSimulation(sm, config, greyScale)
+	if err != nil {
+		return err
+	}
+
+	var reliability, availability float64
+	for _, s := range samples {
+		reliability += s.Reliability
+		availability += s.Availability
+	}
+	log.Printf("Simulated FMAIS of depth %d for %d ticks: average reliability %.6f, average availability %.6f\n",
+		depth, ticks, reliability/float64(len(samples)), availability/float64(len(samples)))
+	return nil
+}
+
+// measurementSystemModel returns the FMAIS used in the measurement of a given depth
+func measurementSystemModel(depth int) (*systemmodel.SystemModel, error) {
+	switch depth {
+	case 2:
+		return systemmodel.CreateSystemModelDepth2(), nil
+	case 3:
+		return systemmodel.CreateSystemModelDepth3(), nil
+	case 4:
+		return systemmodel.CreateSystemModelDepth4(), nil
+	default:
+		return nil, fmt.Errorf("only measurement FMAIS of depth 2, 3 or 4 is available, got %d", depth)
+	}
+}
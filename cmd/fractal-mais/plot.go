@@ -0,0 +1,67 @@
+// main package structures experiment which measures time complexity of the algorithms. This file in particular
+// implements commands, which plot figures and generate reports out of the benchmarked data.
+package main
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/draw"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/report"
+	"log"
+	"strings"
+)
+
+// plotCommand implements a command, which plots figures of the benchmarked data
+func plotCommand() *cobra.Command {
+	var joint bool
+	var meertcore bool
+	cmd := &cobra.Command{
+		Use:   "plot <result>...",
+		Short: "Plots figures of the benchmarked data",
+		Long: "Plots time complexity figures of each benchmark result (and memory complexity figures, if they were " +
+			"measured). With --joint, a single figure joining all results is plotted instead. Results are read from the " +
+			"data directory.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if meertcore && !joint {
+				return fmt.Errorf("--meertcore can be used with --joint only")
+			}
+			if joint {
+				return draw.PlotJointFigure(greyScale, meertcore, args...)
+			}
+			return draw.PlotFigures(greyScale, args...)
+		},
+	}
+	cmd.Flags().BoolVar(&joint, "joint", false, "plots a joint figure of all results")
+	cmd.Flags().BoolVar(&meertcore, "meertcore", false, "plots a joint figure of the measured ME-ERT-CORE reliabilities")
+	return cmd
+}
+
+// reportCommand implements a command, which generates an HTML report of the benchmarked data
+func reportCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "report <result>...",
+		Short: "Generates an HTML report of the benchmarked data",
+		Long: "Generates a self-contained HTML report of the benchmark results (the first one is the main one, the rest " +
+			"are reported as additional values). Results are read from the data directory and the report is stored next " +
+			"to the figures.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return generateReport(args...)
+		},
+	}
+}
+
+// generateReport generates an HTML report of the benchmark results and stores it next to the figures
+func generateReport(fileNames ...string) error {
+	r, err := report.FromData("Fractal MAIS benchmark", fileNames...)
+	if err != nil {
+		return err
+	}
+	path, err := r.Save(outDir, "report_"+strings.TrimSuffix(strings.TrimSuffix(fileNames[0], ".json"), ".csv"))
+	if err != nil {
+		return err
+	}
+	log.Printf("Report is stored in %s\n", path)
+	return nil
+}
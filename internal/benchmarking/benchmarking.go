@@ -3,21 +3,20 @@
 package benchmarking
 
 import (
+	"context"
 	"fmt"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/internal/measurement"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/draw"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/meertcore"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/report"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/sweep"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
-	"log"
+	"log/slog"
 	"math/rand"
-	"runtime"
-	"strings"
 	"time"
 )
 
-var timer uint64
-
 // Setting number of iterations to perform on a single parameter set.
 // The more the number is, the less is an error due to system resources fluctuation
 var numIterations = 25000
@@ -39,272 +38,399 @@ var maxNumInstancesPerApp = 100
 var benchmarkedData map[int]map[int]map[int]float64
 var benchmarkedAvRel map[int]map[int]map[int]float64
 
+// generateReport indicates whether an HTML report is generated at the end of the benchmarking
+var generateReport bool
+
+// SetReport enables (or disables) generation of an HTML report at the end of the benchmarking.
+// Report is stored next to the figures.
+func SetReport(enabled bool) {
+	generateReport = enabled
+}
+
 // BenchSystemModelNoParam function performs benchmarking of a Fractal MAIS System Model and does not require input parameters
 func BenchSystemModelNoParam(docker, greyScale bool) error {
-	err := BenchSystemModel(maxDepth, maxAppNumber, maxNumInstancesPerApp, numIterations, docker, greyScale)
+	return BenchSystemModelNoParamContext(context.Background(), docker, greyScale)
+}
+
+// BenchSystemModelNoParamContext is BenchSystemModelNoParam, which is interrupted, once the context is done
+func BenchSystemModelNoParamContext(ctx context.Context, docker, greyScale bool) error {
+	err := BenchSystemModelContext(ctx, sweep.Default(maxDepth, maxAppNumber, maxNumInstancesPerApp), numIterations, docker, greyScale)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-// BenchSystemModel function performs benchmarking of a Fractal MAIS System Model
-func BenchSystemModel(maxDepth int, maxAppNumber int, maxNumInstancesPerApp int, numIterations int, docker, greyScale bool) error {
-	// initializing some variables to gather statistics
-	var maxNumIncs int64 = -1
-	var appMax, depthMax, instMax int
-	var allocBytes uint64
-	var allocAppMax, allocDepthMax, allocInstMax, allocTotalInstances int64
-	// initializing map
-	benchmarkedData = make(map[int]map[int]map[int]float64, 0)
-	maxNumInst := make(map[int]map[int]map[int]float64, 0)
-	// iterating over the depth of the system
-	for depth := 1; depth <= maxDepth; depth++ {
-		benchmarkedData[depth] = make(map[int]map[int]float64, 0)
-		// iterating over the amount of apps in the system
-		for appNumber := 1; appNumber <= maxAppNumber+1; appNumber += 5 {
-			benchmarkedData[depth][appNumber] = make(map[int]float64, 0)
-			// iterating over the range of the minimum and maximum number of instances deployed by application
-			for maxNumInstances := 1; maxNumInstances <= maxNumInstancesPerApp+1; maxNumInstances += 5 {
-				log.Printf("Fractal MAIS benchmarking: %d iterations over Depth %v, App number %v, Number of instances %v\n", numIterations, depth, appNumber, maxNumInstances)
-				// setting timer to 0
-				timer = 0
-				for iteration := 0; iteration < numIterations; iteration++ {
-					// Generating a system Model
-					sm := systemmodel.SystemModel{}
-					// defining list of application names
-					names := systemmodel.GenerateAppNames(appNumber)
-					sm.InitializeSystemModel(appNumber, depth)
-					sm.CreateRandomApplications(names, 1, maxNumInstances)
-					start := time.Now()
-					sm.GenerateSystemModel() // generates FMAIS System Model without any parameters (requires additional parsing = some code refactoring, complexity stays the same)
-					duration := time.Since(start)
-					// we know that it's going to be a positive number
-					timer += uint64(duration.Microseconds()) // taking microseconds
-
-					// gather some statistics
-					// allocated bytes per this run
-					bb := gatherAllocatedBytesSizeInMb()
-					if allocBytes < bb {
-						allocBytes = bb
-						allocAppMax = int64(appNumber)
-						allocDepthMax = int64(depth)
-						allocInstMax = int64(maxNumInstances)
-						allocTotalInstances = sm.GetTotalNumberOfInstances()
-					}
-					incs := sm.GetTotalNumberOfInstances()
-					if maxNumIncs < incs {
-						maxNumIncs = incs
-						appMax = appNumber
-						depthMax = depth
-						instMax = maxNumInstances
-					}
-				}
-				log.Printf("Fractal MAIS benchmarking: Benchmarked time is %v us, measured %v us in %d operations\n", float64(timer)/float64(numIterations), timer, numIterations)
-				benchmarkedData[depth][appNumber][maxNumInstances] = float64(timer) / float64(numIterations)
-			}
-		}
-	}
-	log.Printf("Fractal MAIS benchmarking: Maximum number of instances is %v. It was for depth %v, number applications %v, instances per app %v.\n",
-		maxNumIncs, depthMax, appMax, instMax)
-	// storing data in a map
-	maxNumInst[depthMax] = make(map[int]map[int]float64, 0)
-	maxNumInst[depthMax][appMax] = make(map[int]float64, 0)
-	maxNumInst[depthMax][appMax][instMax] = float64(maxNumIncs)
-	log.Printf("Fractal MAIS benchmarking: Maximum amount of allocated memory is %v MB. It was for depth %v, number applications %v, instances per app %v."+
-		" Number of instances at this point was %v.\n",
-		allocBytes, allocDepthMax, allocAppMax, allocInstMax, allocTotalInstances)
-
-	// get current time to format a filename
-	ct := time.Now()
-	// making a string with timestamp
-	ts := ct.Format(time.DateOnly) + "_" + ct.Format(time.TimeOnly)
-	ts = strings.ReplaceAll(ts, ":", "-")
-	if docker {
-		ts = "docker_" + ts
+// BenchSystemModel function performs benchmarking of a Fractal MAIS System Model over a given parameter grid
+func BenchSystemModel(spec sweep.Spec, numIterations int, docker, greyScale bool) error {
+	return BenchSystemModelContext(context.Background(), spec, numIterations, docker, greyScale)
+}
+
+// BenchSystemModelContext function performs benchmarking of a Fractal MAIS System Model over a given parameter grid.
+// Cancellation of the context is checked between iterations. Once the context is done, partial results are stored
+// and ErrInterrupted (wrapping the error of the context) is returned.
+func BenchSystemModelContext(ctx context.Context, spec sweep.Spec, numIterations int, docker, greyScale bool) error {
+	if err := spec.Validate(); err != nil {
+		return fmt.Errorf("invalid parameter sweep: %w", err)
 	}
-	err := storedata.SaveData(benchmarkedData, "benchmark_fmais_"+ts)
+	result := storedata.NewResultDocument("FMAIS generation time", "us", numIterations, docker)
+	cp, err := openCheckpoint(ctx, "fmais", spec, numIterations, docker, map[string]*storedata.ResultDocument{
+		"benchmark_fmais_": result,
+	})
 	if err != nil {
-		log.Panicf("Fractal MAIS benchmarking: Something went wrong when storing bechmarked data... %v\n", err)
+		return err
 	}
-	err = storedata.SaveData(maxNumInst, "maxNumInstances_fmais_"+ts)
+	err = runCells(cp, spec, func(c cell) (cellResult, error) {
+		return measureSystemModel(cp, c, numIterations)
+	}, func(c cell, r cellResult) error {
+		result.AddCell(c.depth, c.apps, c.instances, r.stats)
+		cp.maximum(valueMaxInstances, "-", float64(r.maxNumIncs), c.depth, c.apps, c.instances)
+		if r.memory == nil {
+			return nil
+		}
+		cp.maximum(valueMaxPeakHeap, "MB", toMegabytes(r.memory.PeakHeap), c.depth, c.apps, c.instances)
+		return result.SetMemory(c.depth, c.apps, c.instances, *r.memory)
+	})
 	if err != nil {
-		log.Panicf("Fractal MAIS benchmarking: Something went wrong when storing bechmarked data... %v\n", err)
+		return err
+	}
+	benchmarkedData = result.Means()
+	maxInst := cp.extreme(valueMaxInstances)
+	logExtreme(cp, "maximum number of instances", maxInst)
+	peakHeap := cp.extreme(valueMaxPeakHeap)
+	if result.HasMemory() {
+		logExtreme(cp, "maximum peak heap", peakHeap)
+	}
+	cp.addValues(result, valueMaxInstances, valueMaxPeakHeap)
+
+	ts := cp.Timestamp
+	err = storedata.SaveResult(result, "benchmark_fmais_"+ts)
+	if err != nil {
+		return fmt.Errorf("couldn't store benchmarked data: %w", err)
 	}
 
 	prefix := "FMAIS"
 	if docker {
 		prefix = "Docker_" + prefix
 	}
-	err = draw.PlotTimeComplexities(benchmarkedData, maxDepth, maxAppNumber, maxNumInstancesPerApp, prefix, greyScale, false)
+	err = draw.PlotTimeComplexities(benchmarkedData, spec, prefix, greyScale, false)
 	if err != nil {
-		log.Panicf("Fractal MAIS benchmarking: Something went wrong during plotting of the results of benchmarking... %v\n", err)
+		return fmt.Errorf("couldn't plot results of the benchmarking: %w", err)
+	}
+	if result.HasMemory() {
+		err = draw.PlotMemoryComplexities(result, spec, prefix, greyScale)
+		if err != nil {
+			return fmt.Errorf("couldn't plot memory metrics: %w", err)
+		}
 	}
 
-	return nil
+	if generateReport {
+		r := report.NewReport("Fractal MAIS benchmark").
+			AddParameter("Depths", spec.Depths).
+			AddParameter("Numbers of applications", spec.Apps).
+			AddParameter("Numbers of instances per application", spec.Instances).
+			AddParameter("Iterations", numIterations).
+			AddParameter("Warm-up iterations", options.WarmUp).
+			AddParameter("Garbage collection between cells", options.GC).
+			AddParameter("Trimmed fraction of outliers (on each side)", options.Trim).
+			AddParameter("Confidence level", options.Confidence).
+			AddParameter("Execution", describeExecution(result.Execution)).
+			AddParameter("Docker", docker).
+			AddStatistic("Maximum number of instances", fmt.Sprintf("%v (depth %d, %d apps, %d instances per app)",
+				maxInst.Value, maxInst.Depth, maxInst.Apps, maxInst.Instances)).
+			AddSection("FMAIS generation time", "us", benchmarkedData)
+		if options.Seed != 0 {
+			r.SetSeed(options.Seed)
+		}
+		if result.HasMemory() {
+			r.AddStatistic("Maximum peak heap [MB]", fmt.Sprintf("%v (depth %d, %d apps, %d instances per app)",
+				peakHeap.Value, peakHeap.Depth, peakHeap.Apps, peakHeap.Instances))
+		}
+		err = addMemorySections(r, result)
+		if err != nil {
+			return err
+		}
+		err = saveReport(r, "report_fmais_"+ts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return cp.finish()
+}
+
+// cellResult structure holds results of a single measured cell
+type cellResult struct {
+	stats       storedata.Stats
+	memory      *storedata.Memory // memory metrics are gathered in the sequential mode only
+	maxNumIncs  int64             // the greatest number of instances in the FMAIS generated in the cell
+	reliability storedata.Stats   // statistics of the computed reliability (ME-ERT-CORE only)
+}
+
+// measureSystemModel measures generation of a Fractal MAIS System Model in a single cell
+func measureSystemModel(cp *checkpoint, c cell, numIterations int) (cellResult, error) {
+	slog.Debug("benchmarking a cell", "benchmark", cp.Benchmark, "iterations", numIterations, "cell", c)
+	samples := make([]float64, 0, numIterations)
+	var maxNumIncs int64 = -1
+	gatherMemory := sequential()
+	probe := newMemoryProbe()
+	prepareCell()
+	// warm-up iterations (the negative ones) are not recorded
+	for iteration := -options.WarmUp; iteration < numIterations; iteration++ {
+		if cp.interrupted() {
+			return cellResult{}, errStopped
+		}
+		// Generating a system Model
+		sm := systemmodel.SystemModel{}
+		// defining list of application names
+		names := systemmodel.GenerateAppNames(c.apps)
+		sm.InitializeSystemModel(c.apps, c.depth)
+		sm.CreateRandomApplications(names, 1, c.instances)
+		if gatherMemory && iteration == numIterations-1 {
+			// heap retained by the generated System Model is measured in the last iteration
+			probe.markBaseline()
+		}
+		probe.start()
+		start := time.Now()
+		_, err := sm.GenerateSystemModel() // generates FMAIS System Model without any parameters (requires additional parsing = some code refactoring, complexity stays the same)
+		duration := time.Since(start)
+		if err != nil {
+			return cellResult{}, fmt.Errorf("couldn't generate System Model: %w", err)
+		}
+		if iteration < 0 {
+			continue
+		}
+		probe.stop()
+		samples = append(samples, toMicroseconds(duration))
+		if gatherMemory && iteration == numIterations-1 {
+			probe.measureRetained(&sm)
+		}
+
+		// gather some statistics
+		incs := sm.GetTotalNumberOfInstances()
+		if maxNumIncs < incs {
+			maxNumIncs = incs
+		}
+	}
+	stats, err := summarize(samples)
+	if err != nil {
+		return cellResult{}, err
+	}
+	logStats(cp.Benchmark, c, stats)
+	r := cellResult{stats: stats, maxNumIncs: maxNumIncs}
+	if gatherMemory {
+		memory := probe.memory()
+		slog.Info("memory of a cell", "benchmark", cp.Benchmark, "cell", c, "allocatedBytes", memory.AllocatedBytes,
+			"allocations", memory.Allocations, "peakHeapBytes", memory.PeakHeap, "retainedHeapBytes", memory.RetainedHeap)
+		r.memory = &memory
+	}
+	return r, nil
 }
 
 // BenchMeErtCORENoParam function performs benchmarking of a ME-ERT-CORE Reliability Model and does not require input parameters
 func BenchMeErtCORENoParam(docker, greyScale bool) error {
-	err := BenchMeErtCORE(maxDepth, maxAppNumber, maxNumInstancesPerApp, numIterations, docker, greyScale)
+	return BenchMeErtCORENoParamContext(context.Background(), docker, greyScale)
+}
+
+// BenchMeErtCORENoParamContext is BenchMeErtCORENoParam, which is interrupted, once the context is done
+func BenchMeErtCORENoParamContext(ctx context.Context, docker, greyScale bool) error {
+	err := BenchMeErtCOREContext(ctx, sweep.Default(maxDepth, maxAppNumber, maxNumInstancesPerApp), numIterations, docker, greyScale)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-// BenchMeErtCORE function performs benchmarking of a ME-ERT-CORE reliability model
-func BenchMeErtCORE(maxDepth int, maxAppNumber int, maxNumInstancesPerApp int, numIterations int, docker, greyScale bool) error {
-	// initializing some variables to gather statistics
-	var maxNumIncs int64 = -1
-	var appMaxInst, depthMaxInst, instMaxInst int
-	var avRel float64
-	var maxRel float64 = -1
-	var appMax, depthMax, instMax int
-	var minRel float64 = 100000000
-	var appMin, depthMin, instMin int
-	// initializing map
-	benchmarkedData = make(map[int]map[int]map[int]float64, 0)
-	benchmarkedAvRel = make(map[int]map[int]map[int]float64, 0)
-	benchmarkedMaxRel := make(map[int]map[int]map[int]float64, 0)
-	benchmarkedMinRel := make(map[int]map[int]map[int]float64, 0)
-	maxNumInst := make(map[int]map[int]map[int]float64, 0)
-	// iterating over the depth of the system
-	for depth := 1; depth <= maxDepth; depth++ {
-		benchmarkedData[depth] = make(map[int]map[int]float64, 0)
-		benchmarkedAvRel[depth] = make(map[int]map[int]float64, 0)
-		// iterating over the amount of apps in the system
-		for appNumber := 1; appNumber <= maxAppNumber+1; appNumber += 5 {
-			benchmarkedData[depth][appNumber] = make(map[int]float64, 0)
-			benchmarkedAvRel[depth][appNumber] = make(map[int]float64, 0)
-			// iterating over the range of the minimum and maximum number of instances deployed by application
-			for maxNumInstances := 1; maxNumInstances <= maxNumInstancesPerApp+1; maxNumInstances += 5 {
-				log.Printf("ME-ERT-CORE benchmarking: %d iterations over Depth %v, App number %v, Number of instances %v\n", numIterations, depth, appNumber, maxNumInstances)
-				// setting timer to 0
-				timer = 0
-				avRel = 0.0
-				for iteration := 0; iteration < numIterations; iteration++ {
-					// Generating a system Model
-					sm := &systemmodel.SystemModel{}
-					// defining list of application names
-					names := systemmodel.GenerateAppNames(appNumber)
-					sm.InitializeSystemModel(appNumber, depth)
-					sm.CreateRandomApplications(names, 1, maxNumInstances)
-					sm.GenerateSystemModel()
-					sm.SetApplicationPrioritiesRandom()
-
-					err := sm.SetInstancePrioritiesRandom()
-					if err != nil {
-						sm.PrettyPrintApplications().PrettyPrintLayers()
-						log.Panicf("Something went wrong when setting instance Priorities: %v\n", err)
-					}
-					err = sm.SetInstanceReliabilitiesRandom()
-					if err != nil {
-						sm.PrettyPrintApplications().PrettyPrintLayers()
-						log.Panicf("Something went wrong when setting instance Reliabilities: %v\n", err)
-					}
-
-					me := meertcore.MeErtCore{
-						SystemModel: sm,
-						Reliability: -1.23456789,
-					}
-
-					// actual measurement
-					start := time.Now()
-					totalRel, err := me.ComputeReliabilityPerDefinition()
-					duration := time.Since(start)
-					if err != nil {
-						sm.PrettyPrintApplications().PrettyPrintLayers()
-						log.Panicf("ME-ERT-CORE benchmarking: an error during reliability computation occurred: %v", err)
-					}
-					// we know that it's going to be a positive number
-					timer += uint64(duration.Microseconds()) // taking microseconds
-					avRel += totalRel / float64(numIterations)
-
-					// gathering some statistics
-					if maxRel < totalRel {
-						maxRel = totalRel
-						depthMax = depth
-						appMax = appNumber
-						instMax = maxNumInstances
-					}
-					if minRel > totalRel {
-						minRel = totalRel
-						depthMin = depth
-						appMin = appNumber
-						instMin = maxNumInstances
-					}
-					incs := sm.GetTotalNumberOfInstances()
-					if maxNumIncs < incs {
-						maxNumIncs = incs
-						appMaxInst = appNumber
-						depthMaxInst = depth
-						instMaxInst = maxNumInstances
-					}
-
-				}
-				log.Printf("ME-ERT-CORE benchmarking: Benchmarked time is %v us, measured %v us in %d operations\n", float64(timer)/float64(numIterations), timer, numIterations)
-				benchmarkedData[depth][appNumber][maxNumInstances] = float64(timer) / float64(numIterations)
-				benchmarkedAvRel[depth][appNumber][maxNumInstances] = avRel
-			}
-		}
-	}
-	log.Printf("Fractal MAIS benchmarking: Maximum number of instances is %v. It was for depth %v, number applications %v, instances per app %v.\n",
-		maxNumIncs, depthMaxInst, appMaxInst, instMaxInst)
-	// storing data in a map
-	maxNumInst[depthMaxInst] = make(map[int]map[int]float64, 0)
-	maxNumInst[depthMaxInst][appMaxInst] = make(map[int]float64, 0)
-	maxNumInst[depthMaxInst][appMaxInst][instMaxInst] = float64(maxNumIncs)
-	log.Printf("ME-ERT-CORE benchmarking: Maximum measured Reliability is %v. It was for depth %v, number applications %v, instances per app %v.\n",
-		maxRel, depthMax, appMax, instMax)
-	benchmarkedMaxRel[depthMax] = make(map[int]map[int]float64, 0)
-	benchmarkedMaxRel[depthMax][appMax] = make(map[int]float64, 0)
-	benchmarkedMaxRel[depthMax][appMax][instMax] = maxRel
-	log.Printf("ME-ERT-CORE benchmarking: Minimum measured Reliability is %v. It was for depth %v, number applications %v, instances per app %v.\n",
-		minRel, depthMin, appMin, instMin)
-	benchmarkedMinRel[depthMin] = make(map[int]map[int]float64, 0)
-	benchmarkedMinRel[depthMin][appMin] = make(map[int]float64, 0)
-	benchmarkedMinRel[depthMin][appMin][instMin] = minRel
-
-	// get current time to format a filename
-	ct := time.Now()
-	// making a string with timestamp
-	ts := ct.Format(time.DateOnly) + "_" + ct.Format(time.TimeOnly)
-	ts = strings.ReplaceAll(ts, ":", "-")
-	if docker {
-		ts = "docker_" + ts
-	}
-	err := storedata.SaveData(benchmarkedData, "benchmark_meertcore_"+ts)
-	if err != nil {
-		log.Panicf("ME-ERT-CORE benchmarking: Something went wrong when storing bechmarked data... %v\n", err)
+// BenchMeErtCORE function performs benchmarking of a ME-ERT-CORE reliability model over a given parameter grid
+func BenchMeErtCORE(spec sweep.Spec, numIterations int, docker, greyScale bool) error {
+	return BenchMeErtCOREContext(context.Background(), spec, numIterations, docker, greyScale)
+}
+
+// BenchMeErtCOREContext function performs benchmarking of a ME-ERT-CORE reliability model over a given parameter grid.
+// Once the context is done, partial results are stored and ErrInterrupted is returned (see BenchSystemModelContext).
+func BenchMeErtCOREContext(ctx context.Context, spec sweep.Spec, numIterations int, docker, greyScale bool) error {
+	if err := spec.Validate(); err != nil {
+		return fmt.Errorf("invalid parameter sweep: %w", err)
 	}
-	err = storedata.SaveData(maxNumInst, "maxNumInstances_meertcore_"+ts)
+	result := storedata.NewResultDocument("ME-ERT-CORE computation time", "us", numIterations, docker)
+	resultRel := storedata.NewResultDocument("Reliability", "-", numIterations, docker)
+	cp, err := openCheckpoint(ctx, "meertcore", spec, numIterations, docker, map[string]*storedata.ResultDocument{
+		"benchmark_meertcore_":           result,
+		"benchmark_average_reliability_": resultRel,
+	})
 	if err != nil {
-		log.Panicf("ME-ERT-CORE benchmarking: Something went wrong when storing maximum number of instnace... %v\n", err)
+		return err
 	}
-	err = storedata.SaveData(benchmarkedAvRel, "benchmark_average_reliability_"+ts)
+	err = runCells(cp, spec, func(c cell) (cellResult, error) {
+		return measureMeErtCORE(cp, c, numIterations)
+	}, func(c cell, r cellResult) error {
+		result.AddCell(c.depth, c.apps, c.instances, r.stats)
+		resultRel.AddCell(c.depth, c.apps, c.instances, r.reliability)
+		cp.maximum(valueMaxInstances, "-", float64(r.maxNumIncs), c.depth, c.apps, c.instances)
+		cp.maximum(valueMaxReliability, "-", r.reliability.Max, c.depth, c.apps, c.instances)
+		cp.minimum(valueMinReliability, "-", r.reliability.Min, c.depth, c.apps, c.instances)
+		if r.memory == nil {
+			return nil
+		}
+		return result.SetMemory(c.depth, c.apps, c.instances, *r.memory)
+	})
 	if err != nil {
-		log.Panicf("ME-ERT-CORE benchmarking: Something went wrong when storing bechmarked average reliabilities data... %v\n", err)
+		return err
 	}
-	err = storedata.SaveData(benchmarkedMaxRel, "benchmark_maximum_reliability_"+ts)
+	benchmarkedData = result.Means()
+	benchmarkedAvRel = resultRel.Means()
+	maxInst := cp.extreme(valueMaxInstances)
+	logExtreme(cp, "maximum number of instances", maxInst)
+	cp.addValues(result, valueMaxInstances)
+	maxRel := cp.extreme(valueMaxReliability)
+	logExtreme(cp, "maximum measured reliability", maxRel)
+	minRel := cp.extreme(valueMinReliability)
+	logExtreme(cp, "minimum measured reliability", minRel)
+	cp.addValues(resultRel, valueMaxReliability, valueMinReliability)
+
+	ts := cp.Timestamp
+	err = storedata.SaveResult(result, "benchmark_meertcore_"+ts)
 	if err != nil {
-		log.Panicf("ME-ERT-CORE benchmarking: Something went wrong when storing bechmarked maximum reliabilities data... %v\n", err)
+		return fmt.Errorf("couldn't store benchmarked data: %w", err)
 	}
-	err = storedata.SaveData(benchmarkedMinRel, "benchmark_minimum_reliability_"+ts)
+	err = storedata.SaveResult(resultRel, "benchmark_average_reliability_"+ts)
 	if err != nil {
-		log.Panicf("ME-ERT-CORE benchmarking: Something went wrong when storing bechmarked minimum reliabilities data... %v\n", err)
+		return fmt.Errorf("couldn't store benchmarked average reliabilities: %w", err)
 	}
 
 	prefix := "MeErtCore"
 	if docker {
 		prefix = "Docker_" + prefix
 	}
-	err = draw.PlotTimeComplexities(benchmarkedData, maxDepth, maxAppNumber, maxNumInstancesPerApp, prefix, greyScale, true)
+	err = draw.PlotTimeComplexities(benchmarkedData, spec, prefix, greyScale, true)
 	if err != nil {
-		log.Panicf("ME-ERT-CORE benchmarking: Something went wrong during plotting of the results of benchmarking... %v\n", err)
+		return fmt.Errorf("couldn't plot results of the benchmarking: %w", err)
+	}
+	if result.HasMemory() {
+		err = draw.PlotMemoryComplexities(result, spec, prefix, greyScale)
+		if err != nil {
+			return fmt.Errorf("couldn't plot memory metrics: %w", err)
+		}
 	}
 
-	return nil
+	if generateReport {
+		r := report.NewReport("ME-ERT-CORE benchmark").
+			AddParameter("Depths", spec.Depths).
+			AddParameter("Numbers of applications", spec.Apps).
+			AddParameter("Numbers of instances per application", spec.Instances).
+			AddParameter("Iterations", numIterations).
+			AddParameter("Warm-up iterations", options.WarmUp).
+			AddParameter("Garbage collection between cells", options.GC).
+			AddParameter("Trimmed fraction of outliers (on each side)", options.Trim).
+			AddParameter("Confidence level", options.Confidence).
+			AddParameter("Execution", describeExecution(result.Execution)).
+			AddParameter("Docker", docker).
+			AddStatistic("Maximum number of instances", fmt.Sprintf("%v (depth %d, %d apps, %d instances per app)",
+				maxInst.Value, maxInst.Depth, maxInst.Apps, maxInst.Instances)).
+			AddStatistic("Maximum reliability", fmt.Sprintf("%v (depth %d, %d apps, %d instances per app)",
+				maxRel.Value, maxRel.Depth, maxRel.Apps, maxRel.Instances)).
+			AddStatistic("Minimum reliability", fmt.Sprintf("%v (depth %d, %d apps, %d instances per app)",
+				minRel.Value, minRel.Depth, minRel.Apps, minRel.Instances)).
+			AddSection("ME-ERT-CORE computation time", "us", benchmarkedData).
+			AddSection("Average reliability", "-", benchmarkedAvRel)
+		if options.Seed != 0 {
+			r.SetSeed(options.Seed)
+		}
+		err = addMemorySections(r, result)
+		if err != nil {
+			return err
+		}
+		err = saveReport(r, "report_meertcore_"+ts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return cp.finish()
+}
+
+// measureMeErtCORE measures computation of the reliability with ME-ERT-CORE in a single cell
+func measureMeErtCORE(cp *checkpoint, c cell, numIterations int) (cellResult, error) {
+	slog.Debug("benchmarking a cell", "benchmark", cp.Benchmark, "iterations", numIterations, "cell", c)
+	samples := make([]float64, 0, numIterations)
+	reliabilities := make([]float64, 0, numIterations)
+	var maxNumIncs int64 = -1
+	gatherMemory := sequential()
+	probe := newMemoryProbe()
+	prepareCell()
+	// warm-up iterations (the negative ones) are not recorded
+	for iteration := -options.WarmUp; iteration < numIterations; iteration++ {
+		if cp.interrupted() {
+			return cellResult{}, errStopped
+		}
+		// Generating a system Model
+		sm := &systemmodel.SystemModel{}
+		// defining list of application names
+		names := systemmodel.GenerateAppNames(c.apps)
+		sm.InitializeSystemModel(c.apps, c.depth)
+		sm.CreateRandomApplications(names, 1, c.instances)
+		_, err := sm.GenerateSystemModel()
+		if err != nil {
+			return cellResult{}, fmt.Errorf("couldn't generate System Model: %w", err)
+		}
+		sm.SetApplicationPrioritiesRandom()
+
+		err = sm.SetInstancePrioritiesRandom()
+		if err != nil {
+			sm.PrettyPrintApplications().PrettyPrintLayers()
+			return cellResult{}, fmt.Errorf("something went wrong when setting instance Priorities: %w", err)
+		}
+		err = sm.SetInstanceReliabilitiesRandom()
+		if err != nil {
+			sm.PrettyPrintApplications().PrettyPrintLayers()
+			return cellResult{}, fmt.Errorf("something went wrong when setting instance Reliabilities: %w", err)
+		}
+
+		me := meertcore.MeErtCore{
+			SystemModel: sm,
+			Reliability: -1.23456789,
+		}
+
+		// actual measurement
+		if gatherMemory && iteration == numIterations-1 {
+			probe.markBaseline()
+		}
+		probe.start()
+		start := time.Now()
+		totalRel, err := me.ComputeReliabilityPerDefinition()
+		duration := time.Since(start)
+		if err != nil {
+			sm.PrettyPrintApplications().PrettyPrintLayers()
+			return cellResult{}, fmt.Errorf("something went wrong during the reliability computation (per definition): %w", err)
+		}
+		if iteration < 0 {
+			continue
+		}
+		probe.stop()
+		samples = append(samples, toMicroseconds(duration))
+		if gatherMemory && iteration == numIterations-1 {
+			probe.measureRetained(sm)
+		}
+		reliabilities = append(reliabilities, totalRel)
+
+		// gathering some statistics
+		incs := sm.GetTotalNumberOfInstances()
+		if maxNumIncs < incs {
+			maxNumIncs = incs
+		}
+
+	}
+	stats, err := summarize(samples)
+	if err != nil {
+		return cellResult{}, err
+	}
+	logStats(cp.Benchmark, c, stats)
+	r := cellResult{stats: stats, maxNumIncs: maxNumIncs, reliability: storedata.ComputeStats(reliabilities)}
+	if gatherMemory {
+		memory := probe.memory()
+		r.memory = &memory
+	}
+	return r, nil
 }
 
 // BenchErtCore is a placeholder for future implementation of ErtCore reliability model in Go
@@ -312,128 +438,224 @@ func BenchMeErtCORE(maxDepth int, maxAppNumber int, maxNumInstancesPerApp int, n
 //
 //}
 
-func gatherAllocatedBytesSizeInMb() uint64 {
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
+// saveReport stores the HTML report next to the figures
+func saveReport(r *report.Report, fileName string) error {
+	path, err := r.Save(draw.GetDefaultOutputTarget().Dir, fileName)
+	if err != nil {
+		return fmt.Errorf("can't store the benchmark report: %w", err)
+	}
+	slog.Info("benchmark report is stored", "path", path)
+	return nil
+}
 
-	return m.Alloc / 1024 / 1024
+// DefaultOptimizedSpec returns a parameter grid, which was used originally by the benchmark of the optimized ME-ERT-CORE:
+// depths from 2 to maxDepth, numbers of applications from 6 to maxApps and numbers of instances from 1 to maxInstances
+// (both with a step of 5). Measurement FMAIS of depth 1 and with a single application can't be created, thus
+// an error is returned, if maxDepth is lower than 2 or maxApps is lower than 6. Figures show the slices of the paper.
+func DefaultOptimizedSpec(maxDepth, maxApps, maxInstances int) (sweep.Spec, error) {
+	depths, err := sweep.Range(2, maxDepth, 1)
+	if err != nil {
+		return sweep.Spec{}, fmt.Errorf("depths of the optimized benchmark (at least 2): %w", err)
+	}
+	apps, err := sweep.Range(6, maxApps, 5)
+	if err != nil {
+		return sweep.Spec{}, fmt.Errorf("numbers of applications of the optimized benchmark (at least 6): %w", err)
+	}
+	instances, err := sweep.Range(1, maxInstances, 5)
+	if err != nil {
+		return sweep.Spec{}, fmt.Errorf("numbers of instances of the optimized benchmark: %w", err)
+	}
+	return sweep.Spec{
+		Depths:    depths,
+		Apps:      apps,
+		Instances: instances,
+		Slices:    sweep.PaperSlices(),
+	}, nil
 }
 
-// BenchMeErtCoreOptimized function benchmarks optimized version of ME-ERT-CORE
-func BenchMeErtCoreOptimized(maxInstNum, maxAppNum int, docker, greyScale bool) error {
-	log.Printf("Running benchmarking for large-scale FMAIS with Optimized ME-ERT-CORE\n")
+// BenchMeErtCoreOptimized function benchmarks optimized version of ME-ERT-CORE over a given parameter grid
+func BenchMeErtCoreOptimized(spec sweep.Spec, docker, greyScale bool) error {
+	return BenchMeErtCoreOptimizedContext(context.Background(), spec, docker, greyScale)
+}
 
-	benchmarkedData = make(map[int]map[int]map[int]float64, 0)
-	benchmarkedDataOptimized := make(map[int]map[int]map[int]float64, 0)
+// BenchMeErtCoreOptimizedContext function benchmarks optimized version of ME-ERT-CORE over a given parameter grid.
+// Once the context is done, partial results are stored and ErrInterrupted is returned (see BenchSystemModelContext).
+func BenchMeErtCoreOptimizedContext(ctx context.Context, spec sweep.Spec, docker, greyScale bool) error {
+	slog.Info("running benchmarking for large-scale FMAIS with optimized ME-ERT-CORE")
+	if err := spec.Validate(); err != nil {
+		return fmt.Errorf("invalid parameter sweep: %w", err)
+	}
 
-	var timer1 uint64 // initializing timer to store time for the Optimized version of the ME-ERT-CORE computations
-	var timer2 uint64 // initializing timer to store time for the Original (per definition) version of the ME-ERT-CORE computations
+	result := storedata.NewResultDocument("ME-ERT-CORE (per definition) computation time", "us", numIterations, docker)
+	resultOptimized := storedata.NewResultDocument("ME-ERT-CORE (optimized) computation time", "us", numIterations, docker)
+	cp, err := openCheckpoint(ctx, "meertcore_optimized", spec, numIterations, docker, map[string]*storedata.ResultDocument{
+		"benchmark_meertcore_optimized_":      resultOptimized,
+		"benchmark_meertcore_per_definition_": result,
+	})
+	if err != nil {
+		return err
+	}
 
 	// initializing input data
-	app, appFailed := measurement.InitializeInputDataWide()
-
-	for depth := 2; depth <= maxDepth; depth++ {
-		benchmarkedData[depth] = make(map[int]map[int]float64, 0)
-		benchmarkedDataOptimized[depth] = make(map[int]map[int]float64, 0)
-		for appNum := 6; appNum <= maxAppNum; appNum += 5 {
-			benchmarkedData[depth][appNum] = make(map[int]float64, 0)
-			benchmarkedDataOptimized[depth][appNum] = make(map[int]float64, 0)
-			for inst := 1; inst <= maxInstNum; inst += 5 {
-				sm, err := systemmodel.CreateSystemModelWideBench(appNum, inst, depth)
-				if err != nil {
-					return err
-				}
-
-				log.Printf("ME-ERT-CORE (optimized vs per definition) benchmarking: %d iterations over FMAIS of depth %v, with %d Apps and %d instances per App\n", numIterations, sm.Depth, appNum, inst)
-				// setting timer to 0
-				timer1 = 0
-				timer2 = 0
-				for iteration := 0; iteration < numIterations; iteration++ {
-
-					meErtCore := meertcore.MeErtCore{
-						SystemModel: sm,
-						Reliability: 0.0,
-					}
-
-					rnd := rand.Intn(300)
-					// setting reliabilities for each instance
-					err = measurement.UpdateReliabilities(meErtCore.SystemModel, rnd, appFailed, app)
-					if err != nil {
-						sm.PrettyPrintApplications().PrettyPrintLayers()
-						return fmt.Errorf("something went wrong during updating of Application/VI reliabilities: %w", err)
-					}
-
-					_, err = meErtCore.SystemModel.GatherAllApplicationsReliabilities()
-					if err != nil {
-						sm.PrettyPrintApplications().PrettyPrintLayers()
-						return fmt.Errorf("something went wrong during gathering of all application reliabilities: %w", err)
-					}
-
-					// computing reliability of the FMAIS with (optimized) ME-ERT-CORE
-					start1 := time.Now()
-					_, err = meErtCore.ComputeReliabilityOptimizedSimple()
-					duration1 := time.Since(start1)
-					if err != nil {
-						sm.PrettyPrintApplications().PrettyPrintLayers()
-						return fmt.Errorf("something went wrong during the reliability computation (per optimized method): %w", err)
-					}
-					// we know that it's going to be a positive number
-					timer1 += uint64(duration1.Microseconds()) // taking microseconds
-
-					// computing reliability of the FMAIS with (per definition) ME-ERT-CORE
-					start2 := time.Now()
-					_, err = meErtCore.ComputeReliabilityPerDefinition()
-					duration2 := time.Since(start2)
-					if err != nil {
-						sm.PrettyPrintApplications().PrettyPrintLayers()
-						return fmt.Errorf("something went wrong during the reliability computation (per optimized method): %w", err)
-					}
-					// we know that it's going to be a positive number
-					timer2 += uint64(duration2.Microseconds()) // taking microseconds
-				}
-				log.Printf("ME-ERT-CORE (optimized) benchmarking: Benchmarked time is %v us, measured %v us in %d operations\n", float64(timer1)/float64(numIterations), timer1, numIterations)
-				benchmarkedDataOptimized[depth][appNum][inst] = float64(timer1) / float64(numIterations)
-				log.Printf("ME-ERT-CORE (per definition) benchmarking: Benchmarked time is %v us, measured %v us in %d operations\n", float64(timer2)/float64(numIterations), timer2, numIterations)
-				benchmarkedData[depth][appNum][inst] = float64(timer2) / float64(numIterations)
-			}
-		}
-	}
-
-	// get current time to format a filename
-	ct := time.Now()
-	// making a string with timestamp
-	ts := ct.Format(time.DateOnly) + "_" + ct.Format(time.TimeOnly)
-	ts = strings.ReplaceAll(ts, ":", "-")
-	if docker {
-		ts = "docker_" + ts
+	sc := measurement.WideScenario()
+
+	err = runCells(cp, spec, func(c cell) (optimizedCellResult, error) {
+		return measureMeErtCoreOptimized(cp, c, sc)
+	}, func(c cell, r optimizedCellResult) error {
+		resultOptimized.AddCell(c.depth, c.apps, c.instances, r.optimized.stats)
+		result.AddCell(c.depth, c.apps, c.instances, r.perDefinition.stats)
+		if r.optimized.memory == nil || r.perDefinition.memory == nil {
+			return nil
+		}
+		err := resultOptimized.SetMemory(c.depth, c.apps, c.instances, *r.optimized.memory)
+		if err != nil {
+			return err
+		}
+		return result.SetMemory(c.depth, c.apps, c.instances, *r.perDefinition.memory)
+	})
+	if err != nil {
+		return err
 	}
-	err := storedata.SaveData(benchmarkedDataOptimized, "benchmark_meertcore_optimized_"+ts)
+	benchmarkedData = result.Means()
+	benchmarkedDataOptimized := resultOptimized.Means()
+
+	ts := cp.Timestamp
+	err = storedata.SaveResult(resultOptimized, "benchmark_meertcore_optimized_"+ts)
 	if err != nil {
-		log.Panicf("ME-ERT-CORE (optimized) benchmarking: Something went wrong when storing bechmarked data... %v\n", err)
+		return fmt.Errorf("couldn't store benchmarked data (optimized): %w", err)
 	}
 
-	err = storedata.SaveData(benchmarkedData, "benchmark_meertcore_per_definition_"+ts)
+	err = storedata.SaveResult(result, "benchmark_meertcore_per_definition_"+ts)
 	if err != nil {
-		log.Panicf("ME-ERT-CORE (per definition) benchmarking: Something went wrong when storing bechmarked data... %v\n", err)
+		return fmt.Errorf("couldn't store benchmarked data (per definition): %w", err)
 	}
 
 	prefix := "MeErtCore_Optimized"
 	if docker {
 		prefix = "Docker_" + prefix
 	}
-	err = draw.PlotTimeComplexities(benchmarkedDataOptimized, maxDepth, maxAppNumber, maxNumInstancesPerApp, prefix, greyScale, true)
+	err = draw.PlotTimeComplexities(benchmarkedDataOptimized, spec, prefix, greyScale, true)
 	if err != nil {
-		log.Panicf("ME-ERT-CORE (optimized) benchmarking: Something went wrong during plotting of the results of benchmarking... %v\n", err)
+		return fmt.Errorf("couldn't plot results of the benchmarking (optimized): %w", err)
+	}
+	if resultOptimized.HasMemory() {
+		err = draw.PlotMemoryComplexities(resultOptimized, spec, prefix, greyScale)
+		if err != nil {
+			return fmt.Errorf("couldn't plot memory metrics (optimized): %w", err)
+		}
 	}
 
 	prefix = "MeErtCore_Per_Definition"
 	if docker {
 		prefix = "Docker_" + prefix
 	}
-	err = draw.PlotTimeComplexities(benchmarkedData, maxDepth, maxAppNumber, maxNumInstancesPerApp, prefix, greyScale, true)
+	err = draw.PlotTimeComplexities(benchmarkedData, spec, prefix, greyScale, true)
 	if err != nil {
-		log.Panicf("ME-ERT-CORE (per definition) benchmarking: Something went wrong during plotting of the results of benchmarking... %v\n", err)
+		return fmt.Errorf("couldn't plot results of the benchmarking (per definition): %w", err)
+	}
+	if result.HasMemory() {
+		err = draw.PlotMemoryComplexities(result, spec, prefix, greyScale)
+		if err != nil {
+			return fmt.Errorf("couldn't plot memory metrics (per definition): %w", err)
+		}
 	}
 
-	return nil
+	return cp.finish()
+}
+
+// optimizedCellResult structure holds results of both versions of ME-ERT-CORE measured in a single cell
+type optimizedCellResult struct {
+	optimized     cellResult
+	perDefinition cellResult
+}
+
+// measureMeErtCoreOptimized measures computation of the reliability with both, optimized and per definition,
+// versions of ME-ERT-CORE in a single cell
+func measureMeErtCoreOptimized(cp *checkpoint, c cell, sc *measurement.Scenario) (optimizedCellResult, error) {
+	sm, err := systemmodel.CreateSystemModelWideBench(c.apps, c.instances, c.depth)
+	if err != nil {
+		return optimizedCellResult{}, err
+	}
+
+	slog.Debug("benchmarking a cell", "benchmark", cp.Benchmark, "iterations", numIterations, "cell", c)
+	samples1 := make([]float64, 0, numIterations) // time of the Optimized version of the ME-ERT-CORE computations
+	samples2 := make([]float64, 0, numIterations) // time of the Original (per definition) version of the ME-ERT-CORE computations
+	// both computations update the same System Model, so the retained heap is not measured
+	probe1 := newMemoryProbe()
+	probe2 := newMemoryProbe()
+	prepareCell()
+	// warm-up iterations (the negative ones) are not recorded
+	for iteration := -options.WarmUp; iteration < numIterations; iteration++ {
+		if cp.interrupted() {
+			return optimizedCellResult{}, errStopped
+		}
+
+		meErtCore := meertcore.MeErtCore{
+			SystemModel: sm,
+			Reliability: 0.0,
+		}
+
+		rnd := rand.Intn(sc.Steps) + 1
+		// setting reliabilities for each instance
+		err = measurement.UpdateReliabilities(meErtCore.SystemModel, rnd, sc)
+		if err != nil {
+			sm.PrettyPrintApplications().PrettyPrintLayers()
+			return optimizedCellResult{}, fmt.Errorf("something went wrong during updating of Application/VI reliabilities: %w", err)
+		}
+
+		_, err = meErtCore.SystemModel.GatherAllApplicationsReliabilities()
+		if err != nil {
+			sm.PrettyPrintApplications().PrettyPrintLayers()
+			return optimizedCellResult{}, fmt.Errorf("something went wrong during gathering of all application reliabilities: %w", err)
+		}
+
+		// computing reliability of the FMAIS with (optimized) ME-ERT-CORE
+		probe1.start()
+		start1 := time.Now()
+		_, err = meErtCore.ComputeReliabilityOptimizedSimple()
+		duration1 := time.Since(start1)
+		if err != nil {
+			sm.PrettyPrintApplications().PrettyPrintLayers()
+			return optimizedCellResult{}, fmt.Errorf("something went wrong during the reliability computation (per optimized method): %w", err)
+		}
+		if iteration >= 0 {
+			probe1.stop()
+			samples1 = append(samples1, toMicroseconds(duration1))
+		}
+
+		// computing reliability of the FMAIS with (per definition) ME-ERT-CORE
+		probe2.start()
+		start2 := time.Now()
+		_, err = meErtCore.ComputeReliabilityPerDefinition()
+		duration2 := time.Since(start2)
+		if err != nil {
+			sm.PrettyPrintApplications().PrettyPrintLayers()
+			return optimizedCellResult{}, fmt.Errorf("something went wrong during the reliability computation (per optimized method): %w", err)
+		}
+		if iteration >= 0 {
+			probe2.stop()
+			samples2 = append(samples2, toMicroseconds(duration2))
+		}
+	}
+	stats1, err := summarize(samples1)
+	if err != nil {
+		return optimizedCellResult{}, err
+	}
+	logStats(cp.Benchmark+" (optimized)", c, stats1)
+	stats2, err := summarize(samples2)
+	if err != nil {
+		return optimizedCellResult{}, err
+	}
+	logStats(cp.Benchmark+" (per definition)", c, stats2)
+
+	r := optimizedCellResult{
+		optimized:     cellResult{stats: stats1},
+		perDefinition: cellResult{stats: stats2},
+	}
+	if sequential() {
+		memory1, memory2 := probe1.memory(), probe2.memory()
+		r.optimized.memory = &memory1
+		r.perDefinition.memory = &memory2
+	}
+	return r, nil
 }
@@ -0,0 +1,297 @@
+// Package benchmarking implements a benchmarking logic for two test cases - System Model time complexity evaluation
+// and Reliability model (ME-ERT-CORE) time complexity evaluation. This file in particular implements checkpointing
+// of the long benchmark runs, i.e., storing of the finished cells, resuming from the last checkpoint and flushing
+// of the partial results on interruption.
+package benchmarking
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/sweep"
+	"log/slog"
+	"os"
+	"reflect"
+	"sort"
+	"sync/atomic"
+)
+
+// ErrInterrupted is returned by the benchmarks, which were interrupted, i.e., their context was cancelled (e.g., by
+// Ctrl+C in the CLI) or its deadline was exceeded. The error of the context is wrapped too. Partial results are stored
+// and the benchmark can be resumed from the last checkpoint.
+var ErrInterrupted = errors.New("benchmark was interrupted")
+
+// names of the values gathered during the benchmark runs
+const (
+	valueMaxInstances   = "Maximum number of instances"
+	valueMaxPeakHeap    = "Maximum peak heap"
+	valueMaxReliability = "Maximum reliability"
+	valueMinReliability = "Minimum reliability"
+)
+
+// resume indicates whether the benchmarks continue from their last checkpoints
+var resume bool
+
+// SetResume enables (or disables) resuming of the benchmarks from their last checkpoints stored in the data directory.
+// Cells, which were finished before, are not measured again.
+func SetResume(enabled bool) {
+	resume = enabled
+}
+
+// RunHook is called, once a run of the benchmark is about to start (or resume), with the name of the benchmark and
+// the timestamp used in the names of the files of the run
+type RunHook func(benchmark, timestamp string) error
+
+// runHook is called by all benchmarks, it is not set by default
+var runHook RunHook
+
+// SetRunHook sets a hook called by all benchmarks performed afterwards, e.g., to store their configuration next to
+// their results. Nil hook disables it.
+func SetRunHook(h RunHook) {
+	runHook = h
+}
+
+// checkpoint structure holds the state of a benchmark run. It is stored in the data directory after each finished cell.
+type checkpoint struct {
+	Benchmark  string                               `json:"benchmark"`  // name of the benchmark, e.g., fmais
+	Timestamp  string                               `json:"timestamp"`  // timestamp used in the names of the files of the run
+	Spec       sweep.Spec                           `json:"spec"`       // parameter grid of the run
+	Iterations int                                  `json:"iterations"` // number of iterations per cell
+	Options    Options                              `json:"options"`    // options of the measurement
+	Docker     bool                                 `json:"docker"`
+	Results    map[string]*storedata.ResultDocument `json:"results"` // result documents of the run by the prefix of their file name
+	Extremes   map[string]storedata.Value           `json:"extremes,omitempty"`
+
+	ctx     context.Context // context of the run, once it is done, all workers stop measuring
+	stopped atomic.Bool     // once set, all workers stop measuring
+}
+
+// openCheckpoint starts a new checkpoint of the benchmark. Result documents are given by the prefix of their file name.
+// If resuming is enabled and the last checkpoint of the benchmark was made with the same parameters, its state
+// is loaded into the given result documents. The run is interrupted, once the context is done.
+func openCheckpoint(ctx context.Context, benchmark string, spec sweep.Spec, iterations int, docker bool,
+	results map[string]*storedata.ResultDocument) (*checkpoint, error) {
+	c := &checkpoint{
+		Benchmark:  benchmark,
+		Timestamp:  timestamp(docker),
+		Spec:       spec,
+		Iterations: iterations,
+		Options:    options,
+		Docker:     docker,
+		Results:    results,
+		Extremes:   make(map[string]storedata.Value, 0),
+		ctx:        ctx,
+	}
+	if resume {
+		err := c.load()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if runHook != nil {
+		err := runHook(c.Benchmark, c.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// timestamp returns the current time formatted to be used in a file name of the run
+func timestamp(docker bool) string {
+	if docker {
+		return "docker_" + storedata.Timestamp()
+	}
+	return storedata.Timestamp()
+}
+
+// fileName returns a name of the checkpoint file (without extension)
+func (c *checkpoint) fileName() string {
+	if c.Docker {
+		return "checkpoint_docker_" + c.Benchmark
+	}
+	return "checkpoint_" + c.Benchmark
+}
+
+// load loads the last checkpoint of the benchmark, if it exists
+func (c *checkpoint) load() error {
+	content, err := os.ReadFile(storedata.DataDir() + c.fileName() + ".json")
+	if errors.Is(err, os.ErrNotExist) {
+		slog.Info("no checkpoint was found, starting from scratch", "benchmark", c.Benchmark)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("can't read checkpoint of the %s benchmark: %w", c.Benchmark, err)
+	}
+	var last checkpoint
+	err = json.Unmarshal(content, &last)
+	if err != nil {
+		return fmt.Errorf("can't decode checkpoint of the %s benchmark: %w", c.Benchmark, err)
+	}
+	if !sameGrid(last.Spec, c.Spec) || last.Iterations != c.Iterations || !last.Options.sameMeasurement(c.Options) ||
+		last.Docker != c.Docker || !sameKeys(last.Results, c.Results) {
+		return fmt.Errorf("checkpoint %s was made with different parameters of the benchmark, remove it or don't resume",
+			storedata.DataDir()+c.fileName()+".json")
+	}
+
+	for prefix, result := range c.Results {
+		*result = *last.Results[prefix]
+	}
+	c.Timestamp = last.Timestamp
+	if last.Extremes != nil {
+		c.Extremes = last.Extremes
+	}
+	slog.Info("resuming from the checkpoint", "benchmark", c.Benchmark, "finishedCells", c.finishedCells())
+	return nil
+}
+
+// sameGrid checks that both specifications sweep the same grid. Slices of the figures aren't stored in the checkpoint,
+// so they aren't compared.
+func sameGrid(a, b sweep.Spec) bool {
+	return reflect.DeepEqual(a.Depths, b.Depths) && reflect.DeepEqual(a.Apps, b.Apps) &&
+		reflect.DeepEqual(a.Instances, b.Instances)
+}
+
+// sameKeys checks that both maps hold result documents with the same prefixes
+func sameKeys(a, b map[string]*storedata.ResultDocument) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if _, ok := b[k]; !ok || v == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// finishedCells returns the number of cells, which are stored in all result documents
+func (c *checkpoint) finishedCells() int {
+	n := -1
+	for _, result := range c.Results {
+		if n < 0 || len(result.Cells) < n {
+			n = len(result.Cells)
+		}
+	}
+	return max(n, 0)
+}
+
+// done checks whether the cell was finished before, i.e., it is stored in all result documents
+func (c *checkpoint) done(depth, apps, instances int) bool {
+	for _, result := range c.Results {
+		found := false
+		for _, cell := range result.Cells {
+			if cell.Depth == depth && cell.Apps == apps && cell.Instances == instances {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// maximum records a value observed in the cell, if it is the greatest one so far
+func (c *checkpoint) maximum(name, unit string, value float64, depth, apps, instances int) {
+	if v, ok := c.Extremes[name]; !ok || v.Value < value {
+		c.Extremes[name] = storedata.Value{Name: name, Unit: unit, Value: value, Depth: depth, Apps: apps, Instances: instances}
+	}
+}
+
+// minimum records a value observed in the cell, if it is the lowest one so far
+func (c *checkpoint) minimum(name, unit string, value float64, depth, apps, instances int) {
+	if v, ok := c.Extremes[name]; !ok || v.Value > value {
+		c.Extremes[name] = storedata.Value{Name: name, Unit: unit, Value: value, Depth: depth, Apps: apps, Instances: instances}
+	}
+}
+
+// extreme returns a recorded value with a given name
+func (c *checkpoint) extreme(name string) storedata.Value {
+	return c.Extremes[name]
+}
+
+// addValues adds the recorded values with given names to the result document
+func (c *checkpoint) addValues(result *storedata.ResultDocument, names ...string) {
+	for _, name := range names {
+		if v, ok := c.Extremes[name]; ok {
+			result.AddValue(v.Name, v.Unit, v.Value, v.Depth, v.Apps, v.Instances)
+		}
+	}
+}
+
+// save stores the checkpoint to the data directory. Checkpoint is written to a temporary file first,
+// so the previous checkpoint is not lost, if the run crashes during writing.
+func (c *checkpoint) save() error {
+	out, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("can't encode checkpoint of the %s benchmark: %w", c.Benchmark, err)
+	}
+	err = os.MkdirAll(storedata.DataDir(), 0755)
+	if err != nil {
+		return err
+	}
+	path := storedata.DataDir() + c.fileName() + ".json"
+	err = os.WriteFile(path+".tmp", out, 0644)
+	if err != nil {
+		return fmt.Errorf("can't store checkpoint of the %s benchmark: %w", c.Benchmark, err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// finish removes the checkpoint of the run, once its results are stored, so that the next run starts from scratch
+func (c *checkpoint) finish() error {
+	err := os.Remove(storedata.DataDir() + c.fileName() + ".json")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("can't remove checkpoint of the finished %s benchmark: %w", c.Benchmark, err)
+	}
+	return nil
+}
+
+// interrupted checks whether the context of the run is done, or the run was stopped otherwise.
+// It is safe to call it from several workers.
+func (c *checkpoint) interrupted() bool {
+	if c.stopped.Load() {
+		return true
+	}
+	if err := c.ctx.Err(); err != nil {
+		if c.stopped.CompareAndSwap(false, true) {
+			slog.Warn("stopping the benchmark", "benchmark", c.Benchmark, "cause", context.Cause(c.ctx).Error())
+		}
+		return true
+	}
+	return false
+}
+
+// stop makes all workers stop measuring
+func (c *checkpoint) stop() {
+	c.stopped.Store(true)
+}
+
+// flush stores the checkpoint together with partial results of the interrupted run and returns ErrInterrupted
+func (c *checkpoint) flush() error {
+	err := c.save()
+	if err != nil {
+		return err
+	}
+	prefixes := make([]string, 0, len(c.Results))
+	for prefix := range c.Results {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		err = storedata.SaveResult(c.Results[prefix], prefix+c.Timestamp+"_partial")
+		if err != nil {
+			return err
+		}
+	}
+	interruption := ErrInterrupted
+	if err := c.ctx.Err(); err != nil {
+		interruption = fmt.Errorf("%w (%w)", ErrInterrupted, err)
+	}
+	return fmt.Errorf("%w after %d finished cells, partial results are stored in %s, the run can be resumed from %s",
+		interruption, c.finishedCells(), storedata.DataDir(), storedata.DataDir()+c.fileName()+".json")
+}
@@ -0,0 +1,123 @@
+package benchmarking
+
+import (
+	"context"
+	"errors"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/sweep"
+	"gotest.tools/assert"
+	"os"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	defer storedata.SetDataDir(storedata.DataDir())
+	defer SetResume(resume)
+	storedata.SetDataDir(t.TempDir())
+	spec := sweep.Spec{Depths: sweep.Axis{1, 2}, Apps: sweep.Axis{1}, Instances: sweep.Axis{1}}
+
+	result := storedata.NewResultDocument("FMAIS generation time", "us", 10, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cp, err := openCheckpoint(ctx, "unittest", spec, 10, false, map[string]*storedata.ResultDocument{"benchmark_unittest_": result})
+	assert.NilError(t, err)
+	result.AddCell(1, 1, 1, storedata.ComputeStats([]float64{1, 2, 3}))
+	cp.maximum(valueMaxInstances, "-", 5, 1, 1, 1)
+	cp.maximum(valueMaxInstances, "-", 3, 2, 1, 1)
+	cp.minimum(valueMinReliability, "-", 0.5, 1, 1, 1)
+	assert.NilError(t, cp.save())
+	assert.Assert(t, cp.done(1, 1, 1))
+	assert.Assert(t, !cp.done(2, 1, 1))
+
+	// interruption flushes the partial results
+	assert.Assert(t, !cp.interrupted())
+	cancel()
+	assert.Assert(t, cp.interrupted())
+	// all workers are stopped, not only the first one, which noticed the cancellation
+	assert.Assert(t, cp.stopped.Load())
+	err = cp.flush()
+	t.Logf("Interrupted benchmark returns: %v", err)
+	assert.Assert(t, errors.Is(err, ErrInterrupted))
+	assert.Assert(t, errors.Is(err, context.Canceled))
+	partial, err := storedata.ImportResult(storedata.DataDir(), "benchmark_unittest_"+cp.Timestamp+"_partial.json")
+	assert.NilError(t, err)
+	assert.Equal(t, len(partial.Cells), 1)
+
+	// resumed benchmark continues with the finished cells and the recorded values
+	SetResume(true)
+	resumed := storedata.NewResultDocument("FMAIS generation time", "us", 10, false)
+	cp2, err := openCheckpoint(context.Background(), "unittest", spec, 10, false, map[string]*storedata.ResultDocument{"benchmark_unittest_": resumed})
+	assert.NilError(t, err)
+	assert.Equal(t, cp2.Timestamp, cp.Timestamp)
+	assert.Equal(t, len(resumed.Cells), 1)
+	assert.Assert(t, cp2.done(1, 1, 1))
+	assert.Equal(t, cp2.extreme(valueMaxInstances).Value, 5.0)
+	cp2.addValues(resumed, valueMaxInstances, valueMaxReliability)
+	assert.Equal(t, len(resumed.Values), 1)
+	assert.NilError(t, cp2.save())
+
+	// checkpoint of a different run is not resumed
+	other := sweep.Spec{Depths: sweep.Axis{1, 2, 3}, Apps: sweep.Axis{1}, Instances: sweep.Axis{1}}
+	_, err = openCheckpoint(context.Background(), "unittest", other, 10, false, map[string]*storedata.ResultDocument{"benchmark_unittest_": result})
+	assert.ErrorContains(t, err, "different parameters")
+	_, err = openCheckpoint(context.Background(), "unittest", spec, 20, false, map[string]*storedata.ResultDocument{"benchmark_unittest_": result})
+	assert.ErrorContains(t, err, "different parameters")
+
+	// run is resumed with a different parallel execution, but not with a different measurement
+	defer func(o Options) { options = o }(options)
+	parallel := options
+	parallel.Workers, parallel.ProcsPerWorker, parallel.LockOSThread = 4, 2, true
+	assert.NilError(t, SetOptions(parallel))
+	_, err = openCheckpoint(context.Background(), "unittest", spec, 10, false, map[string]*storedata.ResultDocument{"benchmark_unittest_": result})
+	assert.NilError(t, err)
+	seeded := parallel
+	seeded.Seed = 7
+	assert.NilError(t, SetOptions(seeded))
+	_, err = openCheckpoint(context.Background(), "unittest", spec, 10, false, map[string]*storedata.ResultDocument{"benchmark_unittest_": result})
+	assert.ErrorContains(t, err, "different parameters")
+}
+
+func TestResumeDefaultSpec(t *testing.T) {
+	defer storedata.SetDataDir(storedata.DataDir())
+	defer SetResume(resume)
+	storedata.SetDataDir(t.TempDir())
+	SetResume(true)
+
+	// slices of the figures of the default grid aren't stored in the checkpoint, run is resumed anyway
+	spec := sweep.Default(2, 6, 6)
+	result := storedata.NewResultDocument("FMAIS generation time", "us", 5, false)
+	cp, err := openCheckpoint(context.Background(), "fmais", spec, 5, false, map[string]*storedata.ResultDocument{"benchmark_fmais_": result})
+	assert.NilError(t, err)
+	result.AddCell(1, 1, 1, storedata.ComputeStats([]float64{1, 2, 3}))
+	assert.NilError(t, cp.save())
+
+	resumed := storedata.NewResultDocument("FMAIS generation time", "us", 5, false)
+	cp2, err := openCheckpoint(context.Background(), "fmais", sweep.Default(2, 6, 6), 5, false, map[string]*storedata.ResultDocument{"benchmark_fmais_": resumed})
+	assert.NilError(t, err)
+	assert.Equal(t, cp2.Timestamp, cp.Timestamp)
+	assert.Assert(t, cp2.done(1, 1, 1))
+}
+
+func TestResumeFinishedBenchmark(t *testing.T) {
+	defer storedata.SetDataDir(storedata.DataDir())
+	defer SetResume(resume)
+	storedata.SetDataDir(t.TempDir())
+	SetResume(true)
+
+	// checkpoint of the finished run is removed, so that resuming starts a new run instead of doing nothing
+	spec := sweep.Spec{Depths: sweep.Axis{1}, Apps: sweep.Axis{1}, Instances: sweep.Axis{1}}
+	result := storedata.NewResultDocument("FMAIS generation time", "us", 5, false)
+	cp, err := openCheckpoint(context.Background(), "fmais", spec, 5, false, map[string]*storedata.ResultDocument{"benchmark_fmais_": result})
+	assert.NilError(t, err)
+	result.AddCell(1, 1, 1, storedata.ComputeStats([]float64{1, 2, 3}))
+	assert.NilError(t, cp.save())
+	assert.NilError(t, cp.finish())
+	_, err = os.Stat(storedata.DataDir() + "checkpoint_fmais.json")
+	assert.Assert(t, os.IsNotExist(err))
+
+	resumed := storedata.NewResultDocument("FMAIS generation time", "us", 5, false)
+	cp, err = openCheckpoint(context.Background(), "fmais", spec, 5, false, map[string]*storedata.ResultDocument{"benchmark_fmais_": resumed})
+	assert.NilError(t, err)
+	assert.Equal(t, cp.finishedCells(), 0)
+	assert.Assert(t, !cp.done(1, 1, 1))
+}
@@ -0,0 +1,27 @@
+// Package benchmarking implements a benchmarking logic for two test cases - System Model time complexity evaluation
+// and Reliability model (ME-ERT-CORE) time complexity evaluation. This file in particular implements
+// structured logging of the benchmarked cells and their statistics.
+package benchmarking
+
+import (
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"log/slog"
+)
+
+// LogValue groups parameters of the cell in the log records
+func (c cell) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("depth", c.depth), slog.Int("apps", c.apps), slog.Int("instances", c.instances))
+}
+
+// logStats logs the benchmarked time of a cell
+func logStats(benchmark string, c cell, stats storedata.Stats) {
+	slog.Info("cell benchmarked", "benchmark", benchmark, "cell", c, "meanUs", stats.Mean,
+		"confidence", stats.Confidence, "ciLowerUs", stats.CILower, "ciUpperUs", stats.CIUpper,
+		"medianUs", stats.Median, "p95Us", stats.P95, "stdDevUs", stats.StdDev, "operations", stats.N)
+}
+
+// logExtreme logs an extreme value of the benchmark together with the cell, where it was reached
+func logExtreme(cp *checkpoint, msg string, v storedata.Value) {
+	slog.Info(msg, "benchmark", cp.Benchmark, "value", v.Value, "unit", v.Unit,
+		"cell", cell{depth: v.Depth, apps: v.Apps, instances: v.Instances})
+}
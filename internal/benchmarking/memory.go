@@ -0,0 +1,120 @@
+// Package benchmarking implements a benchmarking logic for two test cases - System Model time complexity evaluation
+// and Reliability model (ME-ERT-CORE) time complexity evaluation. This file in particular implements gathering of
+// memory metrics of the measured calls.
+package benchmarking
+
+import (
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/report"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"runtime"
+	"runtime/metrics"
+)
+
+// names of the runtime metrics read around the measured call. Unlike runtime.ReadMemStats, reading them
+// doesn't stop the world, so the probe doesn't disturb the measurement much.
+const (
+	metricAllocatedBytes = "/gc/heap/allocs:bytes"
+	metricAllocations    = "/gc/heap/allocs:objects"
+	metricHeap           = "/memory/classes/heap/objects:bytes"
+)
+
+// memoryProbe gathers memory metrics of the measured calls of a single cell. Runtime accounts small allocations per
+// span, so the metrics of a single call are approximate, but their mean over all calls of the cell is accurate.
+type memoryProbe struct {
+	samples     []metrics.Sample
+	bytes       uint64 // bytes allocated at the start of the measured call
+	allocations uint64 // objects allocated at the start of the measured call
+	calls       uint64 // number of the measured calls
+	totalBytes  uint64 // bytes allocated by all measured calls
+	totalAllocs uint64 // objects allocated by all measured calls
+	peakHeap    uint64 // the greatest heap size observed right after the measured call
+	baseline    uint64 // heap size after garbage collection right before the call, which result is retained
+	retained    uint64 // heap retained by the result of the call after garbage collection
+}
+
+// newMemoryProbe creates a probe for a single cell
+func newMemoryProbe() *memoryProbe {
+	return &memoryProbe{
+		samples: []metrics.Sample{{Name: metricAllocatedBytes}, {Name: metricAllocations}, {Name: metricHeap}},
+	}
+}
+
+// read reads all runtime metrics and returns allocated bytes, allocated objects and heap size
+func (m *memoryProbe) read() (uint64, uint64, uint64) {
+	metrics.Read(m.samples)
+	return m.samples[0].Value.Uint64(), m.samples[1].Value.Uint64(), m.samples[2].Value.Uint64()
+}
+
+// start is called right before the measured call
+func (m *memoryProbe) start() {
+	m.bytes, m.allocations, _ = m.read()
+}
+
+// stop is called right after the measured call
+func (m *memoryProbe) stop() {
+	bytes, allocations, heap := m.read()
+	m.calls++
+	m.totalBytes += bytes - m.bytes
+	m.totalAllocs += allocations - m.allocations
+	if m.peakHeap < heap {
+		m.peakHeap = heap
+	}
+}
+
+// markBaseline collects the garbage and stores the heap size before the call, which retained heap is measured
+func (m *memoryProbe) markBaseline() {
+	runtime.GC()
+	_, _, m.baseline = m.read()
+}
+
+// measureRetained collects the garbage and stores the heap retained by the result of the call since markBaseline
+func (m *memoryProbe) measureRetained(result any) {
+	runtime.GC()
+	_, _, heap := m.read()
+	runtime.KeepAlive(result)
+	m.retained = 0
+	if heap > m.baseline {
+		m.retained = heap - m.baseline
+	}
+}
+
+// memory returns memory metrics of the cell
+func (m *memoryProbe) memory() storedata.Memory {
+	if m.calls == 0 {
+		return storedata.Memory{}
+	}
+	return storedata.Memory{
+		AllocatedBytes: float64(m.totalBytes) / float64(m.calls),
+		Allocations:    float64(m.totalAllocs) / float64(m.calls),
+		PeakHeap:       float64(m.peakHeap),
+		RetainedHeap:   float64(m.retained),
+	}
+}
+
+// toMegabytes converts bytes to megabytes
+func toMegabytes(bytes float64) float64 {
+	return bytes / 1024 / 1024
+}
+
+// memorySectionNames are names and units of the report sections of each memory metric
+var memorySectionNames = map[string][2]string{
+	storedata.MemoryAllocatedBytes: {"Allocated memory per operation", "B"},
+	storedata.MemoryAllocations:    {"Allocations per operation", "-"},
+	storedata.MemoryPeakHeap:       {"Peak heap", "B"},
+	storedata.MemoryRetainedHeap:   {"Retained heap", "B"},
+}
+
+// addMemorySections adds a section of each memory metric of the result document to the report, if they were gathered
+func addMemorySections(r *report.Report, result *storedata.ResultDocument) error {
+	if !result.HasMemory() {
+		return nil
+	}
+	for _, metric := range storedata.MemoryMetrics {
+		data, err := result.MemoryData(metric)
+		if err != nil {
+			return err
+		}
+		r.AddSection(memorySectionNames[metric][0], memorySectionNames[metric][1], data)
+	}
+	return nil
+}
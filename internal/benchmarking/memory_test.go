@@ -0,0 +1,36 @@
+package benchmarking
+
+import (
+	"gotest.tools/assert"
+	"testing"
+)
+
+// sink keeps the allocated memory reachable
+var sink [][]byte
+
+func TestMemoryProbe(t *testing.T) {
+	defer func() { sink = nil }()
+	probe := newMemoryProbe()
+	for i := 0; i < 4; i++ {
+		if i == 3 {
+			probe.markBaseline()
+		}
+		probe.start()
+		buf := make([]byte, 1<<20)
+		probe.stop()
+		if i == 3 {
+			sink = append(sink, buf)
+			probe.measureRetained(buf)
+		}
+	}
+
+	m := probe.memory()
+	t.Logf("Memory metrics are %+v", m)
+	assert.Assert(t, m.AllocatedBytes >= 1<<20)
+	assert.Assert(t, m.Allocations >= 1)
+	assert.Assert(t, m.PeakHeap >= 1<<20)
+	assert.Assert(t, m.RetainedHeap >= 1<<20)
+
+	// nothing was measured
+	assert.Equal(t, newMemoryProbe().memory().AllocatedBytes, 0.0)
+}
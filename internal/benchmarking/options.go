@@ -0,0 +1,133 @@
+// Package benchmarking implements a benchmarking logic for two test cases - System Model time complexity evaluation
+// and Reliability model (ME-ERT-CORE) time complexity evaluation. This file in particular implements options of
+// the measurement, i.e., warm-up, garbage collection between cells, outlier trimming, confidence intervals and
+// parallel execution of the cells.
+package benchmarking
+
+import (
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/internal/measurement"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"math/rand"
+	"runtime"
+	"time"
+)
+
+// Options structure describes how the samples of a single cell (i.e., a parameter set) are measured and summarized
+type Options struct {
+	WarmUp     int     `json:"warmUp"`     // number of iterations performed before the measurement, which are not recorded
+	GC         bool    `json:"gc"`         // runs a garbage collection before each cell, so the garbage of the previous cell doesn't interfere
+	Trim       float64 `json:"trim"`       // fraction of the lowest and the highest samples discarded as outliers (e.g., 0.01 discards 1 % on each side)
+	Confidence float64 `json:"confidence"` // confidence level of the interval of the mean (e.g., 0.95)
+	// Workers is a number of cells measured in parallel. With a single worker (or zero), cells are measured sequentially,
+	// which is the reference mode. Memory metrics are gathered in the sequential mode only, because the runtime
+	// metrics are shared by all workers.
+	Workers int `json:"workers"`
+	// ProcsPerWorker is a number of processors per worker. Go can't limit a single goroutine, so GOMAXPROCS is set
+	// to Workers × ProcsPerWorker for the duration of the benchmark. Zero keeps GOMAXPROCS untouched.
+	ProcsPerWorker int   `json:"procsPerWorker"`
+	LockOSThread   bool  `json:"lockOSThread"`   // locks each worker to its own OS thread
+	Seed           int64 `json:"seed,omitempty"` // seed of the random generator of the System Models (0 keeps the generator randomly seeded)
+}
+
+// DefaultOptions returns options used by the benchmarks, unless they are set with SetOptions
+func DefaultOptions() Options {
+	return Options{
+		WarmUp:     100,
+		GC:         false,
+		Trim:       0,
+		Confidence: 0.95,
+		Workers:    1,
+	}
+}
+
+// options are used by all benchmarks
+var options = DefaultOptions()
+
+// sameMeasurement checks that both options measure and summarize the samples in the same way. Options of the parallel
+// execution (workers, processors per worker and thread locking) are not compared, so that a run can be resumed
+// with a different execution.
+func (o Options) sameMeasurement(other Options) bool {
+	return o.WarmUp == other.WarmUp && o.GC == other.GC && o.Trim == other.Trim && o.Confidence == other.Confidence &&
+		o.Seed == other.Seed
+}
+
+// Validate checks that the options are within their ranges
+func (o Options) Validate() error {
+	if o.WarmUp < 0 {
+		return fmt.Errorf("number of warm-up iterations should be non-negative, got %d", o.WarmUp)
+	}
+	if o.Trim < 0 || o.Trim >= 0.5 {
+		return fmt.Errorf("trimmed fraction should be in range [0, 0.5), got %v", o.Trim)
+	}
+	if o.Confidence <= 0 || o.Confidence >= 1 {
+		return fmt.Errorf("confidence level should be in range (0, 1), got %v", o.Confidence)
+	}
+	if o.Workers < 0 {
+		return fmt.Errorf("number of workers should be non-negative, got %d", o.Workers)
+	}
+	if o.ProcsPerWorker < 0 {
+		return fmt.Errorf("number of processors per worker should be non-negative, got %d", o.ProcsPerWorker)
+	}
+	return nil
+}
+
+// SetOptions sets options of the measurement used by all benchmarks performed afterwards. Non-zero seed seeds
+// the random generators, so that the same System Models (and their reliabilities) are generated (in the sequential
+// mode) by each run.
+func SetOptions(o Options) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	options = o
+	if o.Seed != 0 {
+		rand.Seed(o.Seed)
+		measurement.SetSeed(o.Seed)
+	}
+	return nil
+}
+
+// sequential checks whether the cells are measured one by one
+func sequential() bool {
+	return options.Workers <= 1
+}
+
+// execution returns a description of how the cells are executed with the current options
+func execution() storedata.Execution {
+	mode := "sequential"
+	if !sequential() {
+		mode = "parallel"
+	}
+	return storedata.Execution{
+		Mode:         mode,
+		Workers:      max(options.Workers, 1),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		LockOSThread: options.LockOSThread,
+	}
+}
+
+// prepareCell prepares the runtime for the measurement of the next cell
+func prepareCell() {
+	if options.GC {
+		runtime.GC()
+	}
+}
+
+// toMicroseconds converts the measured duration to microseconds keeping the nanosecond resolution
+func toMicroseconds(d time.Duration) float64 {
+	return float64(d.Nanoseconds()) / float64(time.Microsecond)
+}
+
+// summarize trims outliers from the samples and computes their statistics including the confidence interval of the mean
+func summarize(samples []float64) (storedata.Stats, error) {
+	trimmed, err := storedata.TrimSamples(samples, options.Trim)
+	if err != nil {
+		return storedata.Stats{}, err
+	}
+	stats, err := storedata.ComputeStats(trimmed).WithConfidenceInterval(options.Confidence)
+	if err != nil {
+		return storedata.Stats{}, err
+	}
+	stats.Trimmed = len(samples) - len(trimmed)
+	return stats, nil
+}
@@ -0,0 +1,35 @@
+package benchmarking
+
+import (
+	"gotest.tools/assert"
+	"testing"
+	"time"
+)
+
+func TestSetOptions(t *testing.T) {
+	defer func(o Options) { options = o }(options)
+
+	assert.ErrorContains(t, SetOptions(Options{WarmUp: -1, Confidence: 0.95}), "warm-up")
+	assert.ErrorContains(t, SetOptions(Options{Trim: 0.5, Confidence: 0.95}), "trimmed fraction")
+	assert.ErrorContains(t, SetOptions(Options{Confidence: 1}), "confidence level")
+	assert.NilError(t, SetOptions(Options{WarmUp: 10, GC: true, Trim: 0.1, Confidence: 0.99}))
+	assert.Equal(t, options.WarmUp, 10)
+}
+
+func TestSummarize(t *testing.T) {
+	defer func(o Options) { options = o }(options)
+	assert.NilError(t, SetOptions(Options{Trim: 0.1, Confidence: 0.95}))
+
+	samples := []float64{1000, 10, 11, 9, 10, 10, 11, 9, 10, 0}
+	stats, err := summarize(samples)
+	assert.NilError(t, err)
+	t.Logf("Statistics of the samples are %+v", stats)
+	assert.Equal(t, stats.N, 8)
+	assert.Equal(t, stats.Trimmed, 2)
+	assert.Equal(t, stats.Mean, 10.0)
+	assert.Equal(t, stats.Confidence, 0.95)
+	assert.Assert(t, stats.CILower < 10 && stats.CIUpper > 10)
+
+	// sub-microsecond durations are not rounded down to 0
+	assert.Equal(t, toMicroseconds(250*time.Nanosecond), 0.25)
+}
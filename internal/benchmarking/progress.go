@@ -0,0 +1,116 @@
+// Package benchmarking implements a benchmarking logic for two test cases - System Model time complexity evaluation
+// and Reliability model (ME-ERT-CORE) time complexity evaluation. This file in particular implements a progress
+// reporting of the benchmarks, i.e., how many cells of the parameter grid are done, when the benchmark is expected
+// to finish and which cell is measured right now.
+package benchmarking
+
+import (
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// Progress structure describes how far the benchmark is
+type Progress struct {
+	Benchmark string        // name of the benchmark, e.g., fmais
+	Done      int           // number of finished cells, including the ones finished before resuming
+	Total     int           // number of cells of the parameter grid
+	Elapsed   time.Duration // time elapsed since the start (or resumption) of the run
+	ETA       time.Duration // estimated remaining time, it is 0 until the first cell is finished
+	// current cell, it is not set, once all cells are finished
+	Depth     int
+	Apps      int
+	Instances int
+}
+
+// Percent returns a percentage of the finished cells
+func (p Progress) Percent() float64 {
+	if p.Total == 0 {
+		return 100
+	}
+	return 100 * float64(p.Done) / float64(p.Total)
+}
+
+// ProgressReporter is called, once a measurement of a cell starts and once all cells are finished
+type ProgressReporter func(p Progress)
+
+// progressReporter is used by all benchmarks
+var progressReporter ProgressReporter = LogProgress
+
+// SetProgressReporter sets a reporter of the progress of all benchmarks performed afterwards.
+// Nil reporter restores the default one, i.e., LogProgress.
+func SetProgressReporter(r ProgressReporter) {
+	if r == nil {
+		r = LogProgress
+	}
+	progressReporter = r
+}
+
+// LogProgress logs the progress with the logger of the package
+func LogProgress(p Progress) {
+	attrs := []any{"benchmark", p.Benchmark, "done", p.Done, "total", p.Total,
+		"percent", math.Round(p.Percent()*10) / 10, "elapsed", p.Elapsed.Round(time.Second)}
+	if p.ETA > 0 {
+		attrs = append(attrs, "eta", p.ETA.Round(time.Second))
+	}
+	if p.Done < p.Total {
+		attrs = append(attrs, "cell", cell{depth: p.Depth, apps: p.Apps, instances: p.Instances})
+	}
+	slog.Info("progress", attrs...)
+}
+
+// progressTracker tracks finished cells of a single run. It is safe to use it from several workers.
+type progressTracker struct {
+	mu          sync.Mutex
+	benchmark   string
+	total       int
+	done        int
+	doneAtStart int // cells finished before the run was resumed, they don't count into the ETA
+	start       time.Time
+}
+
+// newProgressTracker creates a tracker of a run, which has done cells finished out of total
+func newProgressTracker(benchmark string, total, done int) *progressTracker {
+	return &progressTracker{
+		benchmark:   benchmark,
+		total:       total,
+		done:        done,
+		doneAtStart: done,
+		start:       time.Now(),
+	}
+}
+
+// progress returns the current progress, ETA is extrapolated from the cells finished in this run
+func (t *progressTracker) progress() Progress {
+	elapsed := time.Since(t.start)
+	p := Progress{
+		Benchmark: t.benchmark,
+		Done:      t.done,
+		Total:     t.total,
+		Elapsed:   elapsed,
+	}
+	if finished := t.done - t.doneAtStart; finished > 0 {
+		p.ETA = elapsed / time.Duration(finished) * time.Duration(t.total-t.done)
+	}
+	return p
+}
+
+// started reports a start of the measurement of a cell
+func (t *progressTracker) started(c cell) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p := t.progress()
+	p.Depth, p.Apps, p.Instances = c.depth, c.apps, c.instances
+	progressReporter(p)
+}
+
+// finished marks a cell as finished and reports the progress, once all cells are finished
+func (t *progressTracker) finished() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.done++
+	if t.done == t.total {
+		progressReporter(t.progress())
+	}
+}
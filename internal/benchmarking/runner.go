@@ -0,0 +1,180 @@
+// Package benchmarking implements a benchmarking logic for two test cases - System Model time complexity evaluation
+// and Reliability model (ME-ERT-CORE) time complexity evaluation. This file in particular implements a runner, which
+// measures cells of the parameter grid either sequentially, or in parallel by several workers.
+package benchmarking
+
+import (
+	"errors"
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/sweep"
+	"log/slog"
+	"runtime"
+	"sync"
+)
+
+// cell identifies a single combination of the benchmark parameters
+type cell struct {
+	depth     int // depth of the FMAIS
+	apps      int // number of applications in the FMAIS
+	instances int // maximum number of instances deployed by application
+}
+
+// errStopped is returned by the measurement of a cell, which was stopped, because the run was interrupted
+var errStopped = errors.New("measurement of the cell was stopped")
+
+// measured structure holds the outcome of a measurement of a single cell
+type measured[T any] struct {
+	cell   cell
+	result T
+	err    error
+}
+
+// runCells measures all cells of the parameter grid, which were not finished before. Each measured cell is passed
+// to record from the calling goroutine (so record doesn't need any synchronization) and the checkpoint is stored.
+// Measurement should return errStopped, once the checkpoint is interrupted. Progress is reported to the progress reporter.
+func runCells[T any](cp *checkpoint, spec sweep.Spec, measure func(c cell) (T, error), record func(c cell, r T) error) error {
+	cells := make([]cell, 0, spec.Cells())
+	for _, depth := range spec.Depths {
+		for _, apps := range spec.Apps {
+			for _, instances := range spec.Instances {
+				if !cp.done(depth, apps, instances) {
+					cells = append(cells, cell{depth: depth, apps: apps, instances: instances})
+				}
+			}
+		}
+	}
+	workers := max(options.Workers, 1)
+	if options.ProcsPerWorker > 0 {
+		defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(workers * options.ProcsPerWorker))
+	}
+	e := execution()
+	for _, result := range cp.Results {
+		result.Execution = &e
+	}
+	slog.Info("measuring cells", "benchmark", cp.Benchmark, "cells", len(cells), "mode", e.Mode,
+		"workers", e.Workers, "gomaxprocs", e.GOMAXPROCS)
+
+	tracker := newProgressTracker(cp.Benchmark, spec.Cells(), spec.Cells()-len(cells))
+	track := func(c cell) (T, error) {
+		tracker.started(c)
+		return measure(c)
+	}
+	recordAndTrack := func(c cell, r T) error {
+		err := recordCell(cp, c, r, record)
+		if err == nil {
+			tracker.finished()
+		}
+		return err
+	}
+
+	var err error
+	if sequential() {
+		err = runSequential(cells, track, recordAndTrack)
+	} else {
+		err = runParallel(cp, cells, workers, track, recordAndTrack)
+	}
+	if errors.Is(err, errStopped) {
+		return cp.flush()
+	}
+	return err
+}
+
+// recordCell records a measured cell and stores the checkpoint
+func recordCell[T any](cp *checkpoint, c cell, r T, record func(c cell, r T) error) error {
+	err := record(c, r)
+	if err != nil {
+		return err
+	}
+	return cp.save()
+}
+
+// runSequential measures cells one by one in the order of the grid
+func runSequential[T any](cells []cell, measure func(c cell) (T, error), record func(c cell, r T) error) error {
+	if options.LockOSThread {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+	}
+	for _, c := range cells {
+		r, err := measure(c)
+		if err != nil {
+			return err
+		}
+		err = record(c, r)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runParallel spreads cells across the workers. Once any of the cells fails, the remaining workers are stopped,
+// cells measured so far are still recorded and the first error is returned.
+func runParallel[T any](cp *checkpoint, cells []cell, workers int, measure func(c cell) (T, error),
+	record func(c cell, r T) error) error {
+	jobs := make(chan cell)
+	results := make(chan measured[T])
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if options.LockOSThread {
+				runtime.LockOSThread()
+				defer runtime.UnlockOSThread()
+			}
+			for c := range jobs {
+				r, err := measure(c)
+				results <- measured[T]{cell: c, result: r, err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for _, c := range cells {
+			select {
+			case jobs <- c:
+			case <-done:
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var firstErr error
+	for m := range results {
+		err := m.err
+		if err == nil {
+			err = record(m.cell, m.result)
+		}
+		if err == nil {
+			continue
+		}
+		if firstErr == nil {
+			close(done)
+			cp.stop()
+		}
+		// workers stopped due to the failure return errStopped, the failure itself takes precedence
+		if firstErr == nil || errors.Is(firstErr, errStopped) {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// describeExecution returns a human-readable description of how the cells were executed
+func describeExecution(e *storedata.Execution) string {
+	if e == nil {
+		return "unknown"
+	}
+	description := fmt.Sprintf("%s (%d workers, GOMAXPROCS %d)", e.Mode, e.Workers, e.GOMAXPROCS)
+	if e.LockOSThread {
+		description += ", locked OS threads"
+	}
+	return description
+}
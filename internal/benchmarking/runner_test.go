@@ -0,0 +1,162 @@
+package benchmarking
+
+import (
+	"context"
+	"errors"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/draw"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/sweep"
+	"gotest.tools/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunCells(t *testing.T) {
+	defer storedata.SetDataDir(storedata.DataDir())
+	defer func(o Options) { options = o }(options)
+	storedata.SetDataDir(t.TempDir())
+	spec := sweep.Spec{Depths: sweep.Axis{1, 2}, Apps: sweep.Axis{1, 6, 11}, Instances: sweep.Axis{1, 6}}
+
+	for _, workers := range []int{1, 4} {
+		options.Workers = workers
+		result := storedata.NewResultDocument("unittest", "-", 1, false)
+		cp, err := openCheckpoint(context.Background(), "unittest", spec, 1, false, map[string]*storedata.ResultDocument{"benchmark_unittest_": result})
+		assert.NilError(t, err)
+		err = runCells(cp, spec, func(c cell) (float64, error) {
+			return float64(c.depth * c.apps * c.instances), nil
+		}, func(c cell, r float64) error {
+			result.AddCell(c.depth, c.apps, c.instances, storedata.Stats{Mean: r})
+			return nil
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, len(result.Cells), spec.Cells())
+		assert.Equal(t, result.Means()[2][11][6], 132.0)
+		// cells are stored in the order of the grid regardless of the order of measurement
+		assert.Equal(t, result.Cells[1].Instances, 6)
+		assert.Equal(t, result.Execution.Workers, workers)
+		t.Logf("Execution of %d workers is %s", workers, describeExecution(result.Execution))
+	}
+	assert.Equal(t, describeExecution(nil), "unknown")
+}
+
+func TestRunCellsFailure(t *testing.T) {
+	defer storedata.SetDataDir(storedata.DataDir())
+	defer func(o Options) { options = o }(options)
+	storedata.SetDataDir(t.TempDir())
+	options.Workers = 3
+	spec := sweep.Spec{Depths: sweep.Axis{1, 2, 3}, Apps: sweep.Axis{1, 6, 11}, Instances: sweep.Axis{1, 6}}
+
+	// failure of a single cell stops the remaining workers and it is reported
+	failure := errors.New("cell failed")
+	result := storedata.NewResultDocument("unittest", "-", 1, false)
+	cp, err := openCheckpoint(context.Background(), "unittest", spec, 1, false, map[string]*storedata.ResultDocument{"benchmark_unittest_": result})
+	assert.NilError(t, err)
+	err = runCells(cp, spec, func(c cell) (float64, error) {
+		if c.depth == 2 && c.apps == 6 {
+			return 0, failure
+		}
+		if cp.interrupted() {
+			return 0, errStopped
+		}
+		return 1, nil
+	}, func(c cell, r float64) error {
+		result.AddCell(c.depth, c.apps, c.instances, storedata.Stats{Mean: r})
+		return nil
+	})
+	assert.Assert(t, errors.Is(err, failure))
+	assert.Assert(t, len(result.Cells) < spec.Cells())
+
+	// interrupted run flushes the partial results
+	cp2, err := openCheckpoint(context.Background(), "unittest", spec, 1, false, map[string]*storedata.ResultDocument{"benchmark_unittest_": result})
+	assert.NilError(t, err)
+	cp2.stop()
+	err = runCells(cp2, spec, func(c cell) (float64, error) {
+		if cp2.interrupted() {
+			return 0, errStopped
+		}
+		return 1, nil
+	}, func(c cell, r float64) error {
+		return nil
+	})
+	assert.Assert(t, errors.Is(err, ErrInterrupted))
+}
+
+func TestBenchSystemModelParallel(t *testing.T) {
+	defer storedata.SetDataDir(storedata.DataDir())
+	defer func(o Options) { options = o }(options)
+	defer func(target draw.OutputTarget) { _ = draw.SetDefaultOutputTarget(target) }(draw.GetDefaultOutputTarget())
+	storedata.SetDataDir(t.TempDir())
+	outDir := t.TempDir()
+	assert.NilError(t, draw.SetDefaultOutputTarget(draw.OutputTarget{Dir: outDir, Formats: []string{"svg"}}))
+	assert.NilError(t, SetOptions(Options{WarmUp: 1, Confidence: 0.95, Seed: 7, Workers: 2, ProcsPerWorker: 1, LockOSThread: true}))
+	SetReport(true)
+	defer SetReport(false)
+
+	spec := sweep.Spec{Depths: sweep.Axis{1, 2}, Apps: sweep.Axis{1, 6}, Instances: sweep.Axis{1, 6}}
+	err := BenchSystemModel(spec, 3, false, false)
+	assert.NilError(t, err)
+
+	// the report carries the seed of the run
+	reports, err := filepath.Glob(filepath.Join(outDir, "report_fmais_*.html"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(reports), 1)
+	html, err := os.ReadFile(reports[0])
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(html), `<td class="name">Seed</td><td>7</td>`))
+
+	// checkpoint of the finished run is removed
+	_, err = os.Stat(storedata.DataDir() + "checkpoint_fmais.json")
+	assert.Assert(t, os.IsNotExist(err))
+	results, err := filepath.Glob(storedata.DataDir() + "benchmark_fmais_*.json")
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 1)
+	result, err := storedata.ImportResult(storedata.DataDir(), filepath.Base(results[0]))
+	assert.NilError(t, err)
+	assert.Equal(t, len(result.Cells), spec.Cells())
+	assert.DeepEqual(t, *result.Execution, storedata.Execution{Mode: "parallel", Workers: 2, GOMAXPROCS: 2, LockOSThread: true})
+	// memory metrics are not gathered in parallel mode
+	assert.Assert(t, !result.HasMemory())
+}
+
+func TestRunCellsProgress(t *testing.T) {
+	defer storedata.SetDataDir(storedata.DataDir())
+	defer func(o Options) { options = o }(options)
+	defer SetProgressReporter(nil)
+	storedata.SetDataDir(t.TempDir())
+	spec := sweep.Spec{Depths: sweep.Axis{1, 2}, Apps: sweep.Axis{1, 6}, Instances: sweep.Axis{1}}
+
+	for _, workers := range []int{1, 2} {
+		options.Workers = workers
+		reports := make([]Progress, 0)
+		SetProgressReporter(func(p Progress) {
+			t.Logf("Progress %.1f %% (%d/%d), ETA %v, cell %d/%d/%d", p.Percent(), p.Done, p.Total, p.ETA, p.Depth, p.Apps, p.Instances)
+			reports = append(reports, p)
+		})
+		result := storedata.NewResultDocument("unittest", "-", 1, false)
+		cp, err := openCheckpoint(context.Background(), "unittest", spec, 1, false, map[string]*storedata.ResultDocument{"benchmark_unittest_": result})
+		assert.NilError(t, err)
+		err = runCells(cp, spec, func(c cell) (float64, error) {
+			return 1, nil
+		}, func(c cell, r float64) error {
+			result.AddCell(c.depth, c.apps, c.instances, storedata.Stats{Mean: r})
+			return nil
+		})
+		assert.NilError(t, err)
+
+		// each cell is reported once it starts and the last report marks the end of the run
+		assert.Equal(t, len(reports), spec.Cells()+1)
+		assert.Equal(t, reports[0].Done, 0)
+		assert.Equal(t, reports[0].Total, spec.Cells())
+		assert.Assert(t, reports[0].Depth > 0)
+		last := reports[len(reports)-1]
+		assert.Equal(t, last.Done, spec.Cells())
+		assert.Equal(t, last.Percent(), 100.0)
+		assert.Equal(t, last.ETA, time.Duration(0))
+	}
+
+	// reports of the default reporter are readable
+	LogProgress(Progress{Benchmark: "unittest", Done: 1, Total: 3, ETA: time.Minute, Depth: 2, Apps: 6, Instances: 1})
+}
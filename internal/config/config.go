@@ -0,0 +1,199 @@
+// Package config implements a configuration file of the fractal-mais binary. Configuration covers the output
+// (directories and plot settings), the benchmarks (parameter grid, iterations, seed and measurement options) and
+// the measurement (its inputs and the simulation). Each field of the configuration corresponds to a command line
+// flag, which takes precedence over the value from the file.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Config structure represents a configuration file. Sections (and fields), which are not set, are left to the flags.
+type Config struct {
+	Output      *Output      `json:"output,omitempty" yaml:"output,omitempty"`           // directories and plot settings
+	Benchmark   *Benchmark   `json:"benchmark,omitempty" yaml:"benchmark,omitempty"`     // parameters of the benchmarks
+	Measurement *Measurement `json:"measurement,omitempty" yaml:"measurement,omitempty"` // parameters of the measurement and simulation
+}
+
+// Output section holds directories, where the results are stored, settings of the figures, of the logs and the time
+// limit of the command
+type Output struct {
+	OutDir    *string   `json:"outDir,omitempty" yaml:"outDir,omitempty" flag:"outDir"`          // directory, where the figures are stored
+	DataDir   *string   `json:"dataDir,omitempty" yaml:"dataDir,omitempty" flag:"dataDir"`       // directory, where the data are stored
+	Formats   *[]string `json:"formats,omitempty" yaml:"formats,omitempty" flag:"formats"`       // formats of the figures
+	DPI       *int      `json:"dpi,omitempty" yaml:"dpi,omitempty" flag:"dpi"`                   // resolution of the PNG figures
+	GreyScale *bool     `json:"greyScale,omitempty" yaml:"greyScale,omitempty" flag:"greyScale"` // figures are plotted in grey scale
+	Fit       *string   `json:"fit,omitempty" yaml:"fit,omitempty" flag:"fit"`                   // model fitted to the complexity figures
+	LogLevel  *string   `json:"logLevel,omitempty" yaml:"logLevel,omitempty" flag:"logLevel"`    // level of the log records
+	LogFormat *string   `json:"logFormat,omitempty" yaml:"logFormat,omitempty" flag:"logFormat"` // format of the log records (text or json)
+	Timeout   *string   `json:"timeout,omitempty" yaml:"timeout,omitempty" flag:"timeout"`       // time limit of the command (e.g., 90m)
+}
+
+// Benchmark section holds a parameter grid of the benchmarks and options of their measurement
+type Benchmark struct {
+	Depth           *int     `json:"depth,omitempty" yaml:"depth,omitempty" flag:"depth"`                               // maximum depth of the System Model
+	AppNumber       *int     `json:"appNumber,omitempty" yaml:"appNumber,omitempty" flag:"appNumber"`                   // maximum number of applications
+	MaxNumInstances *int     `json:"maxNumInstances,omitempty" yaml:"maxNumInstances,omitempty" flag:"maxNumInstances"` // maximum number of instances per application
+	Depths          *string  `json:"depths,omitempty" yaml:"depths,omitempty" flag:"depths"`                            // swept depths (e.g., 1:4)
+	Apps            *string  `json:"apps,omitempty" yaml:"apps,omitempty" flag:"apps"`                                  // swept numbers of applications
+	Instances       *string  `json:"instances,omitempty" yaml:"instances,omitempty" flag:"instances"`                   // swept numbers of instances per application
+	Iterations      *int     `json:"iterations,omitempty" yaml:"iterations,omitempty" flag:"iterations"`                // iterations per parameter set
+	Seed            *int64   `json:"seed,omitempty" yaml:"seed,omitempty" flag:"seed"`                                  // seed of the generated System Models
+	WarmUp          *int     `json:"warmUp,omitempty" yaml:"warmUp,omitempty" flag:"warmUp"`                            // warm-up iterations per parameter set
+	GC              *bool    `json:"gc,omitempty" yaml:"gc,omitempty" flag:"gc"`                                        // garbage collection before each parameter set
+	Trim            *float64 `json:"trim,omitempty" yaml:"trim,omitempty" flag:"trim"`                                  // trimmed fraction of outliers
+	Confidence      *float64 `json:"confidence,omitempty" yaml:"confidence,omitempty" flag:"confidence"`                // confidence level of the mean
+	Workers         *int     `json:"workers,omitempty" yaml:"workers,omitempty" flag:"workers"`                         // parameter sets benchmarked in parallel
+	ProcsPerWorker  *int     `json:"procsPerWorker,omitempty" yaml:"procsPerWorker,omitempty" flag:"procsPerWorker"`    // processors per worker
+	LockOSThread    *bool    `json:"lockOSThread,omitempty" yaml:"lockOSThread,omitempty" flag:"lockOSThread"`          // workers are locked to OS threads
+	Resume          *bool    `json:"resume,omitempty" yaml:"resume,omitempty" flag:"resume"`                            // benchmarks are resumed from the checkpoints
+	Report          *bool    `json:"report,omitempty" yaml:"report,omitempty" flag:"report"`                            // HTML report is generated
+	Docker          *bool    `json:"docker,omitempty" yaml:"docker,omitempty" flag:"docker"`                            // benchmarking is done in Docker container
+	Hardcoded       *bool    `json:"hardcoded,omitempty" yaml:"hardcoded,omitempty" flag:"hardcoded"`                   // hardcoded benchmarking is performed
+}
+
+// Measurement section holds inputs of the measurement and parameters of the simulation of the measurement FMAIS
+type Measurement struct {
+	Seed          *int64   `json:"seed,omitempty" yaml:"seed,omitempty" flag:"seed"`                            // seed of the generated reliabilities (and of the simulation)
+	Deviation     *float64 `json:"deviation,omitempty" yaml:"deviation,omitempty" flag:"deviation"`             // deviation of the generated reliabilities
+	WideDeviation *float64 `json:"wideDeviation,omitempty" yaml:"wideDeviation,omitempty" flag:"wideDeviation"` // deviation in the large-scale measurement
+	WideMaxApps   *int     `json:"wideMaxApps,omitempty" yaml:"wideMaxApps,omitempty" flag:"wideMaxApps"`       // maximum number of applications of the large-scale FMAIS
+	WideStep      *int     `json:"wideStep,omitempty" yaml:"wideStep,omitempty" flag:"wideStep"`                // step of the number of applications of the large-scale FMAIS
+	Scenario      *string  `json:"scenario,omitempty" yaml:"scenario,omitempty" flag:"scenario"`                // file with a scenario measured instead of the ones of the paper
+	Depth         *int     `json:"depth,omitempty" yaml:"depth,omitempty" flag:"depth"`                         // depth of the simulated FMAIS
+	Ticks         *int     `json:"ticks,omitempty" yaml:"ticks,omitempty" flag:"ticks"`                         // number of the simulated ticks
+}
+
+// Load reads a configuration from a YAML (.yaml, .yml) or JSON (.json) file. Unknown fields are reported as errors,
+// so that a misspelled field isn't silently ignored.
+func Load(fileName string) (*Config, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{}
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(content))
+		dec.KnownFields(true)
+		err = dec.Decode(c)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(content))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(c)
+	default:
+		return nil, fmt.Errorf("unknown format of the configuration file %s (expected .yaml, .yml or .json)", fileName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse configuration from %s: %w", fileName, err)
+	}
+	return c, nil
+}
+
+// Save writes the configuration to a file in YAML (.yaml, .yml) or JSON (any other extension) format
+func (c *Config) Save(fileName string) error {
+	var out []byte
+	var err error
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yaml", ".yml":
+		out, err = yaml.Marshal(c)
+	default:
+		out, err = json.MarshalIndent(c, "", " ")
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, out, 0644)
+}
+
+// Apply sets the flags, which were not set on the command line, to the values of a section (e.g., *Benchmark).
+// Fields of the section, which are not set, and fields without a corresponding flag in the flag set are skipped.
+func Apply(section any, flags *pflag.FlagSet) error {
+	if v := reflect.ValueOf(section); v.Kind() == reflect.Pointer && v.IsNil() {
+		// section is not present in the configuration
+		return nil
+	}
+	return forEachField(section, flags, func(f *pflag.Flag, field reflect.Value) error {
+		if f.Changed || field.IsNil() {
+			return nil
+		}
+		value := field.Elem()
+		var s string
+		if value.Kind() == reflect.Slice {
+			s = strings.Join(value.Interface().([]string), ",")
+		} else {
+			s = fmt.Sprint(value.Interface())
+		}
+		if err := flags.Set(f.Name, s); err != nil {
+			return fmt.Errorf("invalid value %q of %s in the configuration: %w", s, f.Name, err)
+		}
+		return nil
+	})
+}
+
+// Capture fills fields of a section (e.g., *Benchmark) with the effective values of their flags
+func Capture(section any, flags *pflag.FlagSet) error {
+	return forEachField(section, flags, func(f *pflag.Flag, field reflect.Value) error {
+		value := reflect.New(field.Type().Elem())
+		var err error
+		switch value.Elem().Kind() {
+		case reflect.String:
+			value.Elem().SetString(f.Value.String())
+		case reflect.Bool:
+			var b bool
+			b, err = strconv.ParseBool(f.Value.String())
+			value.Elem().SetBool(b)
+		case reflect.Int, reflect.Int64:
+			var i int64
+			i, err = strconv.ParseInt(f.Value.String(), 10, 64)
+			value.Elem().SetInt(i)
+		case reflect.Float64:
+			var x float64
+			x, err = strconv.ParseFloat(f.Value.String(), 64)
+			value.Elem().SetFloat(x)
+		case reflect.Slice:
+			slice, ok := f.Value.(pflag.SliceValue)
+			if !ok {
+				return fmt.Errorf("flag %s is not a slice", f.Name)
+			}
+			value.Elem().Set(reflect.ValueOf(slice.GetSlice()))
+		default:
+			return fmt.Errorf("unsupported type %s of %s", value.Elem().Type(), f.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't capture value of %s: %w", f.Name, err)
+		}
+		field.Set(value)
+		return nil
+	})
+}
+
+// forEachField calls fn for each field of a section, which has a corresponding flag in the flag set
+func forEachField(section any, flags *pflag.FlagSet, fn func(f *pflag.Flag, field reflect.Value) error) error {
+	v := reflect.ValueOf(section)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("section should be a non-nil pointer to a structure, got %T", section)
+	}
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Tag.Get("flag")
+		f := flags.Lookup(name)
+		if name == "" || f == nil {
+			continue
+		}
+		if err := fn(f, v.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
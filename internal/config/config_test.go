@@ -0,0 +1,91 @@
+package config
+
+import (
+	"github.com/spf13/pflag"
+	"gotest.tools/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "config.yaml")
+	assert.NilError(t, os.WriteFile(yamlFile, []byte(`
+output:
+  dataDir: results/
+  formats: [png, svg]
+benchmark:
+  depth: 3
+  apps: "1:51:10"
+  seed: 42
+  gc: true
+measurement:
+  deviation: 0.05
+`), 0644))
+	c, err := Load(yamlFile)
+	assert.NilError(t, err)
+	assert.Equal(t, *c.Output.DataDir, "results/")
+	assert.DeepEqual(t, *c.Output.Formats, []string{"png", "svg"})
+	assert.Equal(t, *c.Benchmark.Depth, 3)
+	assert.Equal(t, *c.Benchmark.Apps, "1:51:10")
+	assert.Equal(t, *c.Benchmark.Seed, int64(42))
+	assert.Assert(t, c.Benchmark.Iterations == nil)
+	assert.Equal(t, *c.Measurement.Deviation, 0.05)
+
+	// JSON is accepted as well and it is the same configuration once saved and loaded back
+	jsonFile := filepath.Join(dir, "config.json")
+	assert.NilError(t, c.Save(jsonFile))
+	loaded, err := Load(jsonFile)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, loaded, c)
+
+	assert.NilError(t, os.WriteFile(yamlFile, []byte("benchmark:\n  iteration: 10\n"), 0644))
+	_, err = Load(yamlFile)
+	assert.ErrorContains(t, err, "iteration")
+	_, err = Load(filepath.Join(dir, "config.toml"))
+	assert.ErrorContains(t, err, "no such file")
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "config.toml"), nil, 0644))
+	_, err = Load(filepath.Join(dir, "config.toml"))
+	assert.ErrorContains(t, err, "unknown format")
+}
+
+func TestApplyCapture(t *testing.T) {
+	var depth, iterations int
+	var seed int64
+	var formats []string
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.IntVar(&depth, "depth", 4, "")
+	flags.IntVar(&iterations, "iterations", 25000, "")
+	flags.Int64Var(&seed, "seed", 0, "")
+	flags.StringSliceVar(&formats, "formats", nil, "")
+	assert.NilError(t, flags.Parse([]string{"--depth", "2"}))
+
+	// flags set on the command line take precedence over the configuration
+	configDepth, configIterations, configFormats := 3, 100, []string{"pdf"}
+	assert.NilError(t, Apply(&Benchmark{Depth: &configDepth, Iterations: &configIterations}, flags))
+	assert.NilError(t, Apply(&Output{Formats: &configFormats}, flags))
+	assert.NilError(t, Apply((*Measurement)(nil), flags))
+	assert.Equal(t, depth, 2)
+	assert.Equal(t, iterations, 100)
+	assert.Equal(t, seed, int64(0))
+	assert.DeepEqual(t, formats, []string{"pdf"})
+
+	// effective values are captured for all fields with a flag
+	effective := &Benchmark{}
+	assert.NilError(t, Capture(effective, flags))
+	t.Logf("Effective benchmark configuration is %+v", effective)
+	assert.Equal(t, *effective.Depth, 2)
+	assert.Equal(t, *effective.Iterations, 100)
+	assert.Equal(t, *effective.Seed, int64(0))
+	assert.Assert(t, effective.Trim == nil)
+	output := &Output{}
+	assert.NilError(t, Capture(output, flags))
+	assert.DeepEqual(t, *output.Formats, []string{"pdf"})
+
+	wrongSeed := "random"
+	assert.ErrorContains(t, Apply(&struct {
+		Seed *string `flag:"seed"`
+	}{&wrongSeed}, flags), "invalid value")
+	assert.ErrorContains(t, Apply(struct{}{}, flags), "non-nil pointer")
+}
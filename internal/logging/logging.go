@@ -0,0 +1,54 @@
+// Package logging implements construction of the structured (slog) loggers used by the fractal-mais binary. Loggers
+// write either human-readable text, or JSON (one object per line), and they drop records below the chosen level.
+// Packages of the module log with the default slog logger, so that a single Configure (or slog.SetDefault) call
+// configures all of them.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Formats of the log records
+const (
+	FormatText = "text" // human-readable key=value records
+	FormatJSON = "json" // one JSON object per record
+)
+
+// ParseLevel converts a name of the level (debug, info, warn, or error) to the slog level
+func ParseLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q (expected debug, info, warn or error)", level)
+	}
+	return l, nil
+}
+
+// New creates a logger, which writes records of at least the given level in the given format to w
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	opts := &slog.HandlerOptions{Level: l}
+	switch strings.ToLower(format) {
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q (expected %s or %s)", format, FormatText, FormatJSON)
+	}
+}
+
+// Configure creates a logger with New and sets it as the default one, which is used by all packages of the module
+func Configure(w io.Writer, level, format string) error {
+	logger, err := New(w, level, format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"gotest.tools/assert"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info", FormatJSON)
+	assert.NilError(t, err)
+
+	logger.Debug("hidden")
+	logger.Info("cell measured", "depth", 3, "apps", 10)
+	t.Logf("Logged records are\n%s", buf.String())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, len(lines), 1)
+	var record map[string]any
+	assert.NilError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, record["msg"], "cell measured")
+	assert.Equal(t, record["level"], "INFO")
+	assert.Equal(t, record["depth"], float64(3))
+
+	buf.Reset()
+	logger, err = New(&buf, "DEBUG", FormatText)
+	assert.NilError(t, err)
+	logger.Debug("shown", "depth", 3)
+	assert.Assert(t, strings.Contains(buf.String(), "level=DEBUG msg=shown depth=3"), buf.String())
+
+	_, err = New(&buf, "verbose", FormatText)
+	assert.ErrorContains(t, err, "unknown log level")
+	_, err = New(&buf, "info", "xml")
+	assert.ErrorContains(t, err, "unknown log format")
+
+	level, err := ParseLevel("warn")
+	assert.NilError(t, err)
+	assert.Equal(t, level, slog.LevelWarn)
+}
+
+func TestConfigure(t *testing.T) {
+	defaultLogger := slog.Default()
+	defer slog.SetDefault(defaultLogger)
+
+	var buf bytes.Buffer
+	assert.NilError(t, Configure(&buf, "warn", FormatText))
+	slog.Info("hidden")
+	slog.Warn("stopping the benchmark", "benchmark", "fmais")
+	t.Logf("Logged records are\n%s", buf.String())
+	assert.Assert(t, !strings.Contains(buf.String(), "hidden"), buf.String())
+	assert.Assert(t, strings.Contains(buf.String(), "level=WARN msg=\"stopping the benchmark\" benchmark=fmais"), buf.String())
+
+	assert.ErrorContains(t, Configure(&buf, "info", "xml"), "unknown log format")
+}
@@ -1,8 +1,8 @@
 // Package measurement provides a measurement logic and all helper functions. This file in particular
-// specifies constants/input data used in the measurement
+// specifies constants/input data used in the measurement, i.e., reliability schedules of the scenarios of the paper
 package measurement
 
-// deviation defines a deviation of a Normal distribution
+// names of the applications of the measurement FMAIS
 const (
 	app1Name    = "App#1"
 	app2Name    = "App#2"
@@ -11,234 +11,101 @@ const (
 	appFailName = "App#1" // all other apps won't fail in large-scale measurement
 )
 
-// this variable is adjusted in each measurement scenario to match a VIaaS (one per system)
-var viName = "VI"
-
+// deviation defines a deviation of the generated reliabilities, unless a segment of the schedule sets its own
 var deviation = 0.025
 
+// minAppNumWide is the number of applications of the smallest FMAIS in the large-scale measurement
+const minAppNumWide = 10
+
+// stepsPaper is the number of steps of the scenarios of the paper
+const stepsPaper = 300
+
+// bandConfidence is the confidence level of the reliability band plotted around the measured reliability
+const bandConfidence = 0.95
+
 // app1inst1 defines reliabilities values for Instance #1 of the Application #1
-var app1inst1 = []inputData{
-	{
-		from:  1,
-		to:    50,
-		value: 0.5,
-	},
-	{
-		from:  51,
-		to:    75,
-		value: 0.35,
-	},
-	{
-		from:  76,
-		to:    300,
-		value: 0.5,
-	},
+var app1inst1 = Schedule{
+	{From: 1, To: 50, Mean: 0.5},
+	{From: 51, To: 75, Mean: 0.35},
+	{From: 76, To: 300, Mean: 0.5},
 }
 
 // app1inst2 defines reliabilities values for Instance #2 of the Application #1
-var app1inst2 = []inputData{
-	{
-		from:  1,
-		to:    25,
-		value: 0.37,
-	},
-	{
-		from:  26,
-		to:    40,
-		value: 0.27,
-	},
-	{
-		from:  41,
-		to:    300,
-		value: 0.37,
-	},
+var app1inst2 = Schedule{
+	{From: 1, To: 25, Mean: 0.37},
+	{From: 26, To: 40, Mean: 0.27},
+	{From: 41, To: 300, Mean: 0.37},
 }
 
 // app1inst3 defines reliabilities values for Instance #3 of the Application #1
-var app1inst3 = []inputData{
-	{
-		from:  1,
-		to:    100,
-		value: 0.71,
-	},
-	{
-		from:  101,
-		to:    125,
-		value: 0.59,
-	},
-	{
-		from:  126,
-		to:    300,
-		value: 0.71,
-	},
+var app1inst3 = Schedule{
+	{From: 1, To: 100, Mean: 0.71},
+	{From: 101, To: 125, Mean: 0.59},
+	{From: 126, To: 300, Mean: 0.71},
 }
 
 // app2inst1 defines reliabilities values for Instance #1 of the Application #2
-var app2inst1 = []inputData{
-	{
-		from:  1,
-		to:    130,
-		value: 0.46,
-	},
-	{
-		from:  131,
-		to:    150,
-		value: 0.24,
-	},
-	{
-		from:  151,
-		to:    300,
-		value: 0.46,
-	},
+var app2inst1 = Schedule{
+	{From: 1, To: 130, Mean: 0.46},
+	{From: 131, To: 150, Mean: 0.24},
+	{From: 151, To: 300, Mean: 0.46},
 }
 
 // app2inst2 defines reliabilities values for Instance #2 of the Application #2
-var app2inst2 = []inputData{
-	{
-		from:  1,
-		to:    160,
-		value: 0.69,
-	},
-	{
-		from:  161,
-		to:    175,
-		value: 0.0,
-	},
-	{
-		from:  176,
-		to:    300,
-		value: 0.9,
-	},
+var app2inst2 = Schedule{
+	{From: 1, To: 160, Mean: 0.69},
+	{From: 161, To: 175, Mean: 0.0},
+	{From: 176, To: 300, Mean: 0.9},
 }
 
 // app3inst1 defines reliabilities values for Instance #1 of the Application #3
-var app3inst1 = []inputData{
-	{
-		from:  1,
-		to:    190,
-		value: 0.54,
-	},
-	{
-		from:  191,
-		to:    215,
-		value: 0.38,
-	},
-	{
-		from:  216,
-		to:    300,
-		value: 0.54,
-	},
+var app3inst1 = Schedule{
+	{From: 1, To: 190, Mean: 0.54},
+	{From: 191, To: 215, Mean: 0.38},
+	{From: 216, To: 300, Mean: 0.54},
 }
 
 // app3inst2 defines reliabilities values for Instance #2 of the Application #3
-var app3inst2 = []inputData{
-	{
-		from:  1,
-		to:    230,
-		value: 0.47,
-	},
-	{
-		from:  231,
-		to:    245,
-		value: 0.33,
-	},
-	{
-		from:  246,
-		to:    300,
-		value: 0.47,
-	},
+var app3inst2 = Schedule{
+	{From: 1, To: 230, Mean: 0.47},
+	{From: 231, To: 245, Mean: 0.33},
+	{From: 246, To: 300, Mean: 0.47},
 }
 
 // app4inst1 defines reliabilities values for Instance #1 of the Application #4
-var app4inst1 = []inputData{
-	{
-		from:  1,
-		to:    250,
-		value: 0.8,
-	},
-	{
-		from:  251,
-		to:    261,
-		value: 0.0,
-	},
-	{
-		from:  262,
-		to:    300,
-		value: 0.8,
-	},
+var app4inst1 = Schedule{
+	{From: 1, To: 250, Mean: 0.8},
+	{From: 251, To: 261, Mean: 0.0},
+	{From: 262, To: 300, Mean: 0.8},
 }
 
 // viaas defines reliabilities values for VIaaS
-var viaas = []inputData{
-	{
-		from:  1,
-		to:    270,
-		value: 0.94,
-	},
-	{
-		from:  271,
-		to:    285,
-		value: 0.21,
-	},
-	{
-		from:  286,
-		to:    300,
-		value: 0.94,
-	},
+var viaas = Schedule{
+	{From: 1, To: 270, Mean: 0.94},
+	{From: 271, To: 285, Mean: 0.21},
+	{From: 286, To: 300, Mean: 0.94},
 }
 
 // appInst1 defines reliabilities values for Instance #1 of the Application (which does NOT fails)
-var appInst1 = []inputData{
-	{
-		from:  1,
-		to:    300,
-		value: 0.46,
-	},
+var appInst1 = Schedule{
+	{From: 1, To: 300, Mean: 0.46},
 }
 
 // appInst2 defines reliabilities values for Instance #2 of the Application (which does NOT fail)
-var appInst2 = []inputData{
-	{
-		from:  1,
-		to:    300,
-		value: 0.69,
-	},
+var appInst2 = Schedule{
+	{From: 1, To: 300, Mean: 0.69},
 }
 
 // appFailInst1 defines reliabilities values for Instance #1 of the Application (which FAILS)
-var appFailInst1 = []inputData{
-	{
-		from:  1,
-		to:    99,
-		value: 0.46,
-	},
-	{
-		from:  100,
-		to:    130,
-		value: 0.3,
-	},
-	{
-		from:  131,
-		to:    300,
-		value: 0.46,
-	},
+var appFailInst1 = Schedule{
+	{From: 1, To: 99, Mean: 0.46},
+	{From: 100, To: 130, Mean: 0.3},
+	{From: 131, To: 300, Mean: 0.46},
 }
 
 // appFailInst2 defines reliabilities values for Instance #2 of the Application (which FAILS)
-var appFailInst2 = []inputData{
-	{
-		from:  1,
-		to:    160,
-		value: 0.69,
-	},
-	{
-		from:  161,
-		to:    185,
-		value: 0.59,
-	},
-	{
-		from:  186,
-		to:    300,
-		value: 0.69,
-	},
+var appFailInst2 = Schedule{
+	{From: 1, To: 160, Mean: 0.69},
+	{From: 161, To: 185, Mean: 0.59},
+	{From: 186, To: 300, Mean: 0.69},
 }
@@ -2,91 +2,99 @@
 package measurement
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/draw"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/meertcore"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
-	"log"
+	"log/slog"
 	"math/rand"
+	"path/filepath"
 	"strconv"
+	"sync"
+	"time"
 )
 
-var maxAppNumWide = 1000
-var stepWide = 10
-
-// inputData is a structure to define input data values in a defined range
-type inputData struct {
-	from  int     // from which step
-	to    int     // to which step
-	value float64 // mean value in the defined interval
-}
-
-// app1 structure holds input data for Application #1
-type app1 struct {
-	inst1 map[int]float64
-	inst2 map[int]float64
-	inst3 map[int]float64
-}
-
-// app2 structure holds input data for Application #2
-type app2 struct {
-	inst1 map[int]float64
-	inst2 map[int]float64
-}
-
-// app1 structure holds input data for Application #3
-type app3 struct {
-	inst1 map[int]float64
-	inst2 map[int]float64
+// Settings structure describes inputs of the measurement, which are shared by all measured FMAIS
+type Settings struct {
+	Deviation     float64 `json:"deviation"`          // deviation of the generated reliabilities around their mean values
+	WideDeviation float64 `json:"wideDeviation"`      // deviation used in the large-scale measurement (to generate smoother results)
+	WideMaxApps   int     `json:"wideMaxApps"`        // maximum number of applications of the large-scale FMAIS
+	WideStep      int     `json:"wideStep"`           // step of the number of applications of the large-scale FMAIS
+	Seed          int64   `json:"seed,omitempty"`     // seed of the random generator of reliabilities (0 keeps the generator randomly seeded)
+	Scenario      string  `json:"scenario,omitempty"` // file with a scenario, which is measured instead of the scenarios of the paper
 }
 
-// app4 structure holds input data for Application #4
-type app4 struct {
-	inst1 map[int]float64
+// DefaultSettings returns settings used by the measurement, unless they are set with SetSettings
+func DefaultSettings() Settings {
+	return Settings{
+		Deviation:     0.025,
+		WideDeviation: 0.0025,
+		WideMaxApps:   1000,
+		WideStep:      10,
+	}
 }
 
-// vi structure holds input data for VIaaS
-type vi struct {
-	inst map[int]float64
-}
+// settings are used by the measurement
+var settings = DefaultSettings()
 
-// AppNoFail structure holds input data for Application (which does NOT fail)
-type AppNoFail struct {
-	inst1 map[int]float64
-	inst2 map[int]float64
+// Validate checks that the settings are within their ranges
+func (s Settings) Validate() error {
+	if s.Deviation < 0 || s.WideDeviation < 0 {
+		return fmt.Errorf("deviation should be non-negative, got %v and %v (large-scale)", s.Deviation, s.WideDeviation)
+	}
+	if s.WideMaxApps < minAppNumWide {
+		return fmt.Errorf("maximum number of applications of the large-scale FMAIS should be at least %d, got %d", minAppNumWide, s.WideMaxApps)
+	}
+	if s.WideStep < 1 {
+		return fmt.Errorf("step of the number of applications of the large-scale FMAIS should be positive, got %d", s.WideStep)
+	}
+	return nil
 }
 
-// AppFail structure holds input data for Application (which FAILS)
-type AppFail struct {
-	inst1 map[int]float64
-	inst2 map[int]float64
+// SetSettings sets settings used by the measurement performed afterwards
+func SetSettings(s Settings) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	settings = s
+	return nil
 }
 
 // RunMeasurement function initializes and runs measurement for all FMAIS depths
 func RunMeasurement() error {
-	// run measurement for FMAIS of depth 4
-	err := runMeasurementForDepth4(false)
-	if err != nil {
-		return err
+	return RunMeasurementContext(context.Background())
+}
+
+// RunMeasurementContext function initializes and runs measurement for all FMAIS depths per the scenarios of the paper,
+// or the scenario from the file set in the settings (Scenario) instead of them. Cancellation of the context
+// is checked before each step of the measurement. Once the context is done, reliabilities measured so far by
+// the current measurement are stored as partial results and the error of the context is returned.
+func RunMeasurementContext(ctx context.Context) error {
+	if settings.Seed != 0 {
+		SetSeed(settings.Seed)
 	}
+	deviation = settings.Deviation
 
-	// run measurement for FMAIS of depth 3
-	err = runMeasurementForDepth3(false)
-	if err != nil {
-		return err
+	// a scenario from a file replaces the scenarios of the paper
+	if settings.Scenario != "" {
+		return runScenarioFile(ctx, settings.Scenario, false)
 	}
 
-	// run measurement for FMAIS of depth 2
-	err = runMeasurementForDepth2(false)
-	if err != nil {
-		return err
+	// run measurement for FMAIS of depth 4, 3 and 2
+	for depth := 4; depth >= 2; depth-- {
+		err := runMeasurementForDepth(ctx, depth, false)
+		if err != nil {
+			return err
+		}
 	}
 
 	// re-assigning a deviation in order to generate smoother results in large-scale measurement
-	deviation = 0.1 * deviation
+	deviation = settings.WideDeviation
 	// run measurement for FMAIS of depth 4 with large number of applications
-	err = runMeasurementWide(maxAppNumWide, stepWide, false)
+	err := runMeasurementWide(ctx, settings.WideMaxApps, settings.WideStep, false)
 	if err != nil {
 		return err
 	}
@@ -94,298 +102,148 @@ func RunMeasurement() error {
 	return nil
 }
 
-// generateRandomVectorOfLength function generates a random vector of given length with random values with Normal distribution
-// and a mean value meanVal
-func generateRandomVectorOfLength(meanVal float64, length int) map[int]float64 {
-	arr := make(map[int]float64, length)
-
-	for i := 0; i < length; i++ {
-		// an elegant solution
-		rnd := generateRandomNumberWithMeanValue(meanVal)
-		arr[i] = rnd
+// stopMeasurement stores reliabilities measured so far as partial results (unless it is a test) and returns the error
+// of the context, which was done
+func stopMeasurement(ctx context.Context, name string, relArr any, test bool) error {
+	if test {
+		return fmt.Errorf("measurement %s was stopped: %w", name, ctx.Err())
 	}
-
-	return arr
-}
-
-// generateRandomNumberWithMeanValue generates random float64 number around value defined in meanVal with deviation
-func generateRandomNumberWithMeanValue(meanVal float64) float64 {
-	return (rand.Float64()*2-1)*deviation + meanVal
-}
-
-// generateInputDataForInstance generates input data for Application #1
-func generateInputDataForInstance(id []inputData) map[int]float64 {
-	arr := make(map[int]float64, 0)
-
-	for _, inData := range id {
-		newMap := generateRandomVectorOfLength(inData.value, inData.to-inData.from+1)
-		// updating map
-		for k, v := range newMap {
-			arr[inData.from+k] = v
-		}
+	err := storedata.ExportDataToJSON(storedata.DataDir(), name+"_partial", relArr, "", " ")
+	if err != nil {
+		return errors.Join(ctx.Err(), fmt.Errorf("couldn't store partial results of the measurement %s: %w", name, err))
 	}
-
-	return arr
+	slog.Warn("measurement was stopped, partial results are stored", "measurement", name,
+		"path", storedata.DataDir()+name+"_partial.json")
+	return fmt.Errorf("measurement %s was stopped: %w", name, ctx.Err())
 }
 
-// initializeInputDataDepth4 function initializes input data for the measurement with Depth 4
-func initializeInputDataDepth4() (app1, app2, app3, app4, vi) {
-
-	app1 := app1{
-		inst1: generateInputDataForInstance(app1inst1),
-		inst2: generateInputDataForInstance(app1inst2),
-		inst3: generateInputDataForInstance(app1inst3),
-	}
-
-	app2 := app2{
-		inst1: generateInputDataForInstance(app2inst1),
-		inst2: generateInputDataForInstance(app2inst2),
-	}
+// rnd generates reliabilities of the measurement, it is seeded with the seed of the settings (if it is set), so that
+// the generator shared by other packages is not reseeded
+var rnd = newRand(time.Now().UnixNano())
 
-	app3 := app3{
-		inst1: generateInputDataForInstance(app3inst1),
-		inst2: generateInputDataForInstance(app3inst2),
-	}
-
-	app4 := app4{
-		inst1: generateInputDataForInstance(app4inst1),
-	}
-
-	viaas := vi{
-		inst: generateInputDataForInstance(viaas),
-	}
+// SetSeed seeds the generator of reliabilities, so that the measurement generates the same reliabilities in each run
+func SetSeed(seed int64) {
+	rnd = newRand(seed)
+}
 
-	return app1, app2, app3, app4, viaas
+// lockedSource is a source of random numbers, which is safe for concurrent use (scenarios are updated by parallel
+// benchmark workers too)
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
 }
 
-// initializeInputDataDepth3 function initializes input data for the measurement with Depth 3
-func initializeInputDataDepth3() (app1, app2, app3, vi) {
+// Int63 returns a non-negative pseudo-random 63-bit integer
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
 
-	app1 := app1{
-		inst1: generateInputDataForInstance(app1inst1),
-		inst2: generateInputDataForInstance(app1inst2),
-		inst3: generateInputDataForInstance(app1inst3),
-	}
+// Seed seeds the source
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
 
-	app2 := app2{
-		inst1: generateInputDataForInstance(app2inst1),
-		inst2: generateInputDataForInstance(app2inst2),
-	}
+// newRand creates a random generator with a given seed, which is safe for concurrent use
+func newRand(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(seed)})
+}
 
-	app3 := app3{
-		inst1: generateInputDataForInstance(app3inst1),
-		inst2: generateInputDataForInstance(app3inst2),
-	}
+// generateRandomNumber generates random float64 number around value defined in meanVal with a given deviation
+func generateRandomNumber(r *rand.Rand, meanVal, dev float64) float64 {
+	return (r.Float64()*2-1)*dev + meanVal
+}
 
-	viaas := vi{
-		inst: generateInputDataForInstance(viaas),
+// UpdateReliabilities function updates reliability values of the applications driven by the scenario for certain step
+func UpdateReliabilities(sm *systemmodel.SystemModel, step int, sc *Scenario) error {
+	for _, name := range sc.applicationNames(sm) {
+		rels, err := sc.reliabilities(sm, name, step)
+		if err != nil {
+			return err
+		}
+		err = sm.UpdateApplicationReliability(name, rels)
+		if err != nil {
+			return fmt.Errorf("something went wrong during update of %s instances reliabilities: %w", name, err)
+		}
 	}
-
-	return app1, app2, app3, viaas
+	return nil
 }
 
-// initializeInputDataDepth2 function initializes input data for the measurement with Depth 2
-func initializeInputDataDepth2() (app1, app2, vi) {
+// runMeasurementForDepth function runs a measurement for the measurement FMAIS of a given depth
+func runMeasurementForDepth(ctx context.Context, depth int, test bool) error {
+	slog.Info("running measurement", "depth", depth)
 
-	app1 := app1{
-		inst1: generateInputDataForInstance(app1inst1),
-		inst2: generateInputDataForInstance(app1inst2),
-		inst3: generateInputDataForInstance(app1inst3),
-	}
-
-	app2 := app2{
-		inst1: generateInputDataForInstance(app2inst1),
-		inst2: generateInputDataForInstance(app2inst2),
+	sc, err := DepthScenario(depth)
+	if err != nil {
+		return err
 	}
-
-	viaas := vi{
-		inst: generateInputDataForInstance(viaas),
+	sm, err := measurementSystemModel(depth)
+	if err != nil {
+		return err
 	}
 
-	return app1, app2, viaas
+	return runScenario(ctx, sm, sc, "me-ert-core_fmais_depth_"+strconv.Itoa(depth), test)
 }
 
-// InitializeInputDataWide function initializes input data for the large-scale measurement with Depth 4
-func InitializeInputDataWide() (AppNoFail, AppFail) {
-
-	app := AppNoFail{
-		inst1: generateInputDataForInstance(appInst1),
-		inst2: generateInputDataForInstance(appInst2),
+// runScenarioFile function runs a measurement of the scenario loaded from a file
+func runScenarioFile(ctx context.Context, fileName string, test bool) error {
+	sc, err := LoadScenario(fileName)
+	if err != nil {
+		return err
 	}
-
-	appF := AppFail{
-		inst1: generateInputDataForInstance(appFailInst1),
-		inst2: generateInputDataForInstance(appFailInst2),
+	sm, err := sc.systemModel(filepath.Dir(fileName))
+	if err != nil {
+		return err
 	}
+	slog.Info("running measurement", "scenario", sc.Name, "steps", sc.Steps, "depth", sm.Depth, "apps", appCount(sm))
 
-	return app, appF
+	return runScenario(ctx, sm, sc, "me-ert-core_scenario_"+sc.Name, test)
 }
 
-// UpdateReliabilities function updates reliability values for provided apps for certain step
-func UpdateReliabilities(sm *systemmodel.SystemModel, step int, i ...interface{}) error {
-
-	for _, item := range i {
-		switch t := item.(type) {
-		case app1:
-			err := sm.UpdateApplicationReliability(app1Name, map[int64]float64{
-				1: t.inst1[step],
-				2: t.inst2[step],
-				3: t.inst3[step],
-			})
-			if err != nil {
-				return fmt.Errorf("something went wrong during update of Application #1 instances reliabilities: %w", err)
-			}
-		case app2:
-			err := sm.UpdateApplicationReliability(app2Name, map[int64]float64{
-				1: t.inst1[step],
-				2: t.inst2[step],
-			})
-			if err != nil {
-				return fmt.Errorf("something went wrong during update of Application #2 instances reliabilities: %w", err)
-			}
-		case app3:
-			err := sm.UpdateApplicationReliability(app3Name, map[int64]float64{
-				1: t.inst1[step],
-				2: t.inst2[step],
-			})
-			if err != nil {
-				return fmt.Errorf("something went wrong during update of Application #3 instances reliabilities: %w", err)
-			}
-		case app4:
-			err := sm.UpdateApplicationReliability(app4Name, map[int64]float64{
-				1: t.inst1[step],
-			})
-			if err != nil {
-				return fmt.Errorf("something went wrong during update of Application #4 instance reliabilities: %w", err)
-			}
-		case vi:
-			err := sm.UpdateApplicationReliability(viName, map[int64]float64{
-				1: t.inst[step],
-			})
-			if err != nil {
-				return fmt.Errorf("something went wrong during update of VI instance reliabilities: %w", err)
-			}
-		case AppFail:
-			rels := make(map[int64]float64, 0)
-			application, ok := sm.Applications[appFailName]
-			if !ok {
-				sm.PrettyPrintApplications()
-				return fmt.Errorf("couldn't extract application %s from application list", appFailName)
-			}
-			if application.Rules != 2 {
-				// randomly setting numbers
-				for c := 1; c <= application.Rules; c++ {
-					rels[int64(c)] = generateRandomNumberWithMeanValue(t.inst2[step])
-				}
-			} else {
-				rels = map[int64]float64{
-					1: t.inst1[step],
-					2: t.inst2[step],
-				}
-			}
-			err := sm.UpdateApplicationReliability(appFailName, rels)
-			if err != nil {
-				return fmt.Errorf("something went wrong during update of %s (Failed App) instance reliabilities: %w", appFailName, err)
-			}
-		case AppNoFail:
-			appName := "App#"
-			for a := 2; a <= len(sm.Applications)-1; a++ {
-				updVal := AppNoFail{
-					inst1: generateInputDataForInstance(appInst1),
-					inst2: generateInputDataForInstance(appInst2),
-				}
-				rels := make(map[int64]float64, 0)
-				application, ok := sm.Applications[appName+strconv.Itoa(a)]
-				if !ok {
-					sm.PrettyPrintApplications()
-					return fmt.Errorf("couldn't extract application %s from application list", appName+strconv.Itoa(a))
-				}
-				if application.Rules != 2 {
-					// randomly setting numbers
-					for c := 1; c <= application.Rules; c++ {
-						rels[int64(c)] = generateRandomNumberWithMeanValue(updVal.inst2[step])
-					}
-				} else {
-					rels = map[int64]float64{
-						1: updVal.inst1[step],
-						2: updVal.inst2[step],
-					}
-				}
-				err := sm.UpdateApplicationReliability(appName+strconv.Itoa(a), rels)
-				if err != nil {
-					return fmt.Errorf("something went wrong during update of %s instance reliabilities: %w", appName+strconv.Itoa(a), err)
-				}
-			}
-		default:
-			return fmt.Errorf("received an unexpected type: %v", t)
+// runScenario function runs a measurement of the scenario on the FMAIS, stores measured reliabilities under a given
+// name and plots them together with ME-ERT-CORE coefficients
+func runScenario(ctx context.Context, sm *systemmodel.SystemModel, sc *Scenario, name string, test bool) error {
+	relArr, err := measureScenario(ctx, sm, sc)
+	if err != nil {
+		if ctx.Err() != nil {
+			return stopMeasurement(ctx, name, relArr, test)
 		}
+		return err
 	}
-	return nil
-}
-
-// runMeasurementForDepth4 function runs a measurement for FMAIS of Depth 4
-func runMeasurementForDepth4(test bool) error {
-	log.Printf("Running measurement for FMAIS of depth 4\n")
-	// initializing a reliability map
-	relArr := make(map[int]float64, 0)
-
-	// initializing input data
-	app1, app2, app3, app4, vi := initializeInputDataDepth4()
-
-	// initialising system model
-	sm4 := systemmodel.CreateSystemModelDepth4()
-	viName = "VI#3-4"
-
-	meErtCore := meertcore.MeErtCore{
-		SystemModel: sm4,
-		Reliability: 0.0,
-	}
-
-	// running the measurement itself
-	for i := 1; i <= 300; i++ {
-		// setting reliabilities for each instance
-		err := UpdateReliabilities(meErtCore.SystemModel, i, app1, app2, app3, app4, vi)
-		if err != nil {
-			return fmt.Errorf("something went wrong during updating of Application/VI reliabilities: %w", err)
-		}
 
-		_, err = meErtCore.SystemModel.GatherAllApplicationsReliabilities()
+	if !test {
+		// exporting data to JSON
+		err := storedata.ExportDataToJSON(storedata.DataDir(), name, relArr, "", " ")
 		if err != nil {
-			return fmt.Errorf("something went wrong during gathering of all application reliabilities: %w", err)
+			return fmt.Errorf("something went wrong during storing of the data in JSON file: %w", err)
 		}
-
-		// computing reliability of the FMAIS per (optimized) ME-ERT-CORE
-		rel, err := meErtCore.ComputeReliabilityOptimizedSimple()
+		// plotting a graph for measured reliability
+		err = draw.PlotMeasuredReliability(relArr, appCount(sm), sm.Depth, false, false)
 		if err != nil {
-			return fmt.Errorf("something went wrong during the reliability computation (per optimized method): %w", err)
+			return fmt.Errorf("something went wrong during plotting of a reliability values: %w", err)
 		}
 
-		// updating reliability map
-		relArr[i] = rel
-	}
-
-	if !test {
-		// exporting data to JSON
-		err := storedata.ExportDataToJSON("data/", "me-ert-core_fmais_depth_"+strconv.Itoa(sm4.Depth),
-			relArr, "", " ")
+		// plotting a graph for expected reliability (per definition) together with its confidence band
+		mean, lower, upper, err := reliabilityBand(sm, sc, bandConfidence)
 		if err != nil {
-			log.Panicf("Something went wrong during storing of the data in JSON file... %v\n", err)
 			return err
 		}
-		// plotting a graph for measured reliability
-		err = draw.PlotMeasuredReliability(relArr, len(sm4.Applications)-1, sm4.Depth, false, false)
+		err = draw.PlotMeasuredReliabilityWithUncertainty(mean, lower, upper, appCount(sm), sm.Depth, false, false)
 		if err != nil {
-			return fmt.Errorf("something went wrong during plotting of a reliability values: %w", err)
+			return fmt.Errorf("something went wrong during plotting of a reliability values with uncertainty: %w", err)
 		}
 
 		// compute ME-ERT-CORE coefficients and plot it
-		coefs, err := computeMeErtCoreCoefficients(relArr, len(sm4.Applications)-1)
+		coefs, err := computeMeErtCoreCoefficients(relArr, sc.Steps, appCount(sm))
 		if err != nil {
 			return err
 		}
 
 		// plotting a graph for measured reliability
-		err = draw.PlotMeErtCoreCoefficients(coefs, len(sm4.Applications)-1, sm4.Depth, false, false)
+		err = draw.PlotMeErtCoreCoefficients(coefs, appCount(sm), sm.Depth, false, false)
 		if err != nil {
 			return fmt.Errorf("something went wrong during plotting of a ME-ERT-CORE coefficients: %w", err)
 		}
@@ -394,158 +252,89 @@ func runMeasurementForDepth4(test bool) error {
 	return nil
 }
 
-// runMeasurementForDepth3 function runs a measurement for FMAIS of Depth 3
-func runMeasurementForDepth3(test bool) error {
-	log.Printf("Running measurement for FMAIS of depth 3\n")
-
+// measureScenario function computes reliability of the FMAIS (per optimized ME-ERT-CORE) in each step of
+// the scenario. Once the context is done, reliabilities measured so far are returned with the error of the context.
+func measureScenario(ctx context.Context, sm *systemmodel.SystemModel, sc *Scenario) (map[int]float64, error) {
 	// initializing a reliability map
-	relArr := make(map[int]float64, 0)
-
-	// initializing input data
-	app1, app2, app3, vi := initializeInputDataDepth3()
-	viName = "VI#3-3"
-
-	// initialising system model
-	sm3 := systemmodel.CreateSystemModelDepth3()
+	relArr := make(map[int]float64, sc.Steps)
 
 	meErtCore := meertcore.MeErtCore{
-		SystemModel: sm3,
+		SystemModel: sm,
 		Reliability: 0.0,
 	}
 
 	// running the measurement itself
-	for i := 1; i <= 300; i++ {
+	for i := 1; i <= sc.Steps; i++ {
+		if ctx.Err() != nil {
+			return relArr, ctx.Err()
+		}
 		// setting reliabilities for each instance
-		err := UpdateReliabilities(meErtCore.SystemModel, i, app1, app2, app3, vi)
+		err := UpdateReliabilities(meErtCore.SystemModel, i, sc)
 		if err != nil {
-			return fmt.Errorf("something went wrong during updating of Application/VI reliabilities: %w", err)
+			return nil, fmt.Errorf("something went wrong during updating of Application/VI reliabilities: %w", err)
 		}
 
 		_, err = meErtCore.SystemModel.GatherAllApplicationsReliabilities()
 		if err != nil {
-			return fmt.Errorf("something went wrong during gathering of all application reliabilities: %w", err)
+			return nil, fmt.Errorf("something went wrong during gathering of all application reliabilities: %w", err)
 		}
 
 		// computing reliability of the FMAIS per (optimized) ME-ERT-CORE
 		rel, err := meErtCore.ComputeReliabilityOptimizedSimple()
 		if err != nil {
-			return fmt.Errorf("something went wrong during the reliability computation (per optimized method): %w", err)
+			return nil, fmt.Errorf("something went wrong during the reliability computation (per optimized method): %w", err)
 		}
 
 		// updating reliability map
 		relArr[i] = rel
 	}
 
-	if !test {
-		// exporting data to JSON
-		err := storedata.ExportDataToJSON("data/", "me-ert-core_fmais_depth_"+strconv.Itoa(sm3.Depth),
-			relArr, "", " ")
-		if err != nil {
-			log.Panicf("Something went wrong during storing of the data in JSON file... %v\n", err)
-			return err
-		}
-		// plotting a graph for measured reliability
-		err = draw.PlotMeasuredReliability(relArr, len(sm3.Applications)-1, sm3.Depth, false, false)
-		if err != nil {
-			return fmt.Errorf("something went wrong during plotting of a reliability values: %w", err)
-		}
-
-		// compute ME-ERT-CORE coefficients and plot it
-		coefs, err := computeMeErtCoreCoefficients(relArr, len(sm3.Applications)-1)
-		if err != nil {
-			return err
-		}
-
-		// plotting a graph for measured reliability
-		err = draw.PlotMeErtCoreCoefficients(coefs, len(sm3.Applications)-1, sm3.Depth, false, false)
-		if err != nil {
-			return fmt.Errorf("something went wrong during plotting of a ME-ERT-CORE coefficients: %w", err)
-		}
-	}
-
-	return nil
+	return relArr, nil
 }
 
-// runMeasurementForDepth2 function runs a measurement for FMAIS of Depth 2
-func runMeasurementForDepth2(test bool) error {
-	log.Printf("Running measurement for FMAIS of depth 2\n")
-
-	// initializing a reliability map
-	relArr := make(map[int]float64, 0)
-
-	// initializing input data
-	app1, app2, vi := initializeInputDataDepth2()
-	viName = "VI#2-1"
-
-	// initialising system model
-	sm2 := systemmodel.CreateSystemModelDepth2()
+// reliabilityBand computes an expected reliability of the FMAIS (per definition) and its confidence band in each
+// step of the scenario. Reliabilities of the driven leaf instances are generated uniformly around the mean value of their
+// segment, thus their variance is deviation^2/3. Other leaf instances are taken as point estimates.
+func reliabilityBand(sm *systemmodel.SystemModel, sc *Scenario, confidence float64) (map[int]float64, map[int]float64, map[int]float64, error) {
+	leaves, err := sc.drivenLeaves(sm)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
+	mean := make(map[int]float64, sc.Steps)
+	lower := make(map[int]float64, sc.Steps)
+	upper := make(map[int]float64, sc.Steps)
 	meErtCore := meertcore.MeErtCore{
-		SystemModel: sm2,
+		SystemModel: sm,
 		Reliability: 0.0,
 	}
-
-	// running the measurement itself
-	for i := 1; i <= 300; i++ {
-		// setting reliabilities for each instance
-		err := UpdateReliabilities(meErtCore.SystemModel, i, app1, app2, vi)
-		if err != nil {
-			return fmt.Errorf("something went wrong during updating of Application/VI reliabilities: %w", err)
-		}
-
-		_, err = meErtCore.SystemModel.GatherAllApplicationsReliabilities()
-		if err != nil {
-			return fmt.Errorf("something went wrong during gathering of all application reliabilities: %w", err)
-		}
-
-		// computing reliability of the FMAIS per (optimized) ME-ERT-CORE
-		rel, err := meErtCore.ComputeReliabilityOptimizedSimple()
-		if err != nil {
-			return fmt.Errorf("something went wrong during the reliability computation (per optimized method): %w", err)
-		}
-
-		// updating reliability map
-		relArr[i] = rel
-	}
-
-	if !test {
-		// exporting data to JSON
-		err := storedata.ExportDataToJSON("data/", "me-ert-core_fmais_depth_"+strconv.Itoa(sm2.Depth),
-			relArr, "", " ")
-		if err != nil {
-			log.Panicf("Something went wrong during storing of the data in JSON file... %v\n", err)
-			return err
-		}
-		// plotting a graph for measured reliability
-		err = draw.PlotMeasuredReliability(relArr, len(sm2.Applications)-1, sm2.Depth, false, false)
-		if err != nil {
-			return fmt.Errorf("something went wrong during plotting of a reliability values: %w", err)
-		}
-
-		// compute ME-ERT-CORE coefficients and plot it
-		coefs, err := computeMeErtCoreCoefficients(relArr, len(sm2.Applications)-1)
-		if err != nil {
-			return err
+	for i := 1; i <= sc.Steps; i++ {
+		meErtCore.Distributions = nil
+		for path, schedule := range leaves {
+			seg, ok := schedule.at(i)
+			if !ok {
+				return nil, nil, nil, fmt.Errorf("step %d is out of the scenario %s with %d steps", i, sc.Name, sc.Steps)
+			}
+			meErtCore.SetDistribution(path, meertcore.NewMeanVarianceDistribution(seg.Mean, seg.dev()*seg.dev()/3))
 		}
-
-		// plotting a graph for measured reliability
-		err = draw.PlotMeErtCoreCoefficients(coefs, len(sm2.Applications)-1, sm2.Depth, false, false)
+		interval, err := meErtCore.ComputeReliabilityInterval(confidence, 0)
 		if err != nil {
-			return fmt.Errorf("something went wrong during plotting of a ME-ERT-CORE coefficients: %w", err)
+			return nil, nil, nil, fmt.Errorf("couldn't compute reliability interval in step %d: %w", i, err)
 		}
+		mean[i], lower[i], upper[i] = interval.Mean, interval.Lower, interval.Upper
 	}
 
-	return nil
+	return mean, lower, upper, nil
 }
 
-func computeMeErtCoreCoefficients(relMap map[int]float64, appNum int) (map[int]float64, error) {
+func computeMeErtCoreCoefficients(relMap map[int]float64, steps, appNum int) (map[int]float64, error) {
 
-	if len(relMap) != 300 {
+	if len(relMap) != steps {
 		return nil, fmt.Errorf("obtained incomplete map with %d elements in it: %v", len(relMap), relMap)
 	}
 
 	res := make(map[int]float64, 0)
-	for i := 1; i <= 300; i++ {
+	for i := 1; i <= steps; i++ {
 		rel, ok := relMap[i]
 		if !ok {
 			return nil, fmt.Errorf("map entry for key %d does NOT exist, relMap is: %v", i, relMap)
@@ -563,8 +352,8 @@ func computeMeErtCoreCoefficients(relMap map[int]float64, appNum int) (map[int]f
 // runMeasurementWide function runs a measurement for a large-scale FMAIS, which contains up to 100 Applications,
 // which are identical in terms of component priority distributions. This measurement is intended to showcase the
 // application of a ME-ERT-CORE coefficient
-func runMeasurementWide(maxAppNum, step int, test bool) error {
-	log.Printf("Running measurement for large-scale FMAIS\n")
+func runMeasurementWide(ctx context.Context, maxAppNum, step int, test bool) error {
+	slog.Info("running measurement for large-scale FMAIS", "maxApps", maxAppNum, "step", step)
 
 	// initializing an overall reliability map
 	relArr := make(map[int]map[int]float64, 0)
@@ -573,13 +362,13 @@ func runMeasurementWide(maxAppNum, step int, test bool) error {
 	meErtCoreCoefs := make(map[int]map[int]float64, 0)
 
 	// initializing input data
-	app, appFailed := InitializeInputDataWide()
+	sc := WideScenario()
 
-	for a := 10; a <= maxAppNum; a = a + step {
-		log.Printf("Running large-scale measurement for FMAIS with %d Apps\n", a)
-
-		// initializing a reliability map for a current experiment
-		relMeasured := make(map[int]float64, 0)
+	for a := minAppNumWide; a <= maxAppNum; a = a + step {
+		if ctx.Err() != nil {
+			return stopMeasurement(ctx, "me-ert-core-wide_fmais_depth_"+strconv.Itoa(4), relArr, test)
+		}
+		slog.Info("running large-scale measurement", "apps", a)
 
 		// initialize FMAIS of depth 4 with up to 100 applications (5 applications per VI)
 		sm, err := systemmodel.CreateSystemModelWide(a)
@@ -587,39 +376,20 @@ func runMeasurementWide(maxAppNum, step int, test bool) error {
 			return err
 		}
 
-		meErtCore := meertcore.MeErtCore{
-			SystemModel: sm,
-			Reliability: 0.0,
-		}
-
 		// running the measurement itself
-		for i := 1; i <= 300; i++ {
-			// setting reliabilities for each instance
-			err = UpdateReliabilities(meErtCore.SystemModel, i, appFailed, app)
-			if err != nil {
-				return fmt.Errorf("something went wrong during updating of Application/VI reliabilities: %w", err)
-			}
-
-			_, err = meErtCore.SystemModel.GatherAllApplicationsReliabilities()
-			if err != nil {
-				return fmt.Errorf("something went wrong during gathering of all application reliabilities: %w", err)
-			}
-
-			// computing reliability of the FMAIS per (optimized) ME-ERT-CORE
-			rel, err := meErtCore.ComputeReliabilityOptimizedSimple()
-			if err != nil {
-				return fmt.Errorf("something went wrong during the reliability computation (per optimized method): %w", err)
+		relMeasured, err := measureScenario(ctx, sm, sc)
+		if err != nil {
+			if ctx.Err() != nil {
+				return stopMeasurement(ctx, "me-ert-core-wide_fmais_depth_"+strconv.Itoa(4), relArr, test)
 			}
-
-			// updating reliability map
-			relMeasured[i] = rel
+			return err
 		}
 
 		// updating reliability map
 		relArr[a] = relMeasured
 
 		// computing ME-ERT-CORE coefficients
-		meErtCoreCoefs[a], err = computeMeErtCoreCoefficients(relMeasured, len(sm.Applications)-1)
+		meErtCoreCoefs[a], err = computeMeErtCoreCoefficients(relMeasured, sc.Steps, appCount(sm))
 		if err != nil {
 			return fmt.Errorf("something went wrong while computing ME-ERT-CORE coefficients for %d Apps with "+
 				"following input data: %v\n%w", a, relMeasured, err)
@@ -628,37 +398,35 @@ func runMeasurementWide(maxAppNum, step int, test bool) error {
 		// plot figure
 		if !test {
 			// plotting a graph for measured reliability
-			err = draw.PlotMeasuredReliability(relMeasured, len(sm.Applications)-1, sm.Depth, false, true)
+			err = draw.PlotMeasuredReliability(relMeasured, appCount(sm), sm.Depth, false, true)
 			if err != nil {
 				return fmt.Errorf("something went wrong during plotting of a reliability values: %w", err)
 			}
 
 			// plotting a graph for measured reliability
-			err = draw.PlotMeErtCoreCoefficients(meErtCoreCoefs[a], len(sm.Applications)-1, sm.Depth, false, true)
+			err = draw.PlotMeErtCoreCoefficients(meErtCoreCoefs[a], appCount(sm), sm.Depth, false, true)
 			if err != nil {
 				return fmt.Errorf("something went wrong during plotting of a ME-ERT-CORE coefficients: %w", err)
 			}
 		}
 	}
-	log.Printf("Measurement for large-scale FMAIS has finished. Storing results...\n")
+	slog.Info("measurement for large-scale FMAIS has finished, storing results")
 
 	if !test {
 		// exporting data to JSON
-		err := storedata.ExportDataToJSON("data/", "me-ert-core-wide_fmais_depth_"+strconv.Itoa(4),
+		err := storedata.ExportDataToJSON(storedata.DataDir(), "me-ert-core-wide_fmais_depth_"+strconv.Itoa(4),
 			relArr, "", " ")
 		if err != nil {
-			log.Panicf("Something went wrong during storing of the data in JSON file... %v\n", err)
-			return err
+			return fmt.Errorf("something went wrong during storing of the data in JSON file: %w", err)
 		}
 
-		err = storedata.ExportDataToJSON("data/", "me-ert-core-wide-coefs_fmais_depth_"+strconv.Itoa(4),
+		err = storedata.ExportDataToJSON(storedata.DataDir(), "me-ert-core-wide-coefs_fmais_depth_"+strconv.Itoa(4),
 			meErtCoreCoefs, "", " ")
 		if err != nil {
-			log.Panicf("Something went wrong during storing of the data in JSON file... %v\n", err)
-			return err
+			return fmt.Errorf("something went wrong during storing of the data in JSON file: %w", err)
 		}
 	}
-	log.Printf("Results are stored. Measurement is finished.\n")
+	slog.Info("results are stored, measurement is finished")
 
 	return nil
 }
@@ -1,16 +1,22 @@
 package measurement
 
 import (
+	"context"
+	"errors"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/meertcore"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
 	"gotest.tools/assert"
 	"math"
+	"math/rand"
+	"os"
 	"testing"
 )
 
 func TestUpdateReliabilities(t *testing.T) {
 	// initializing input data
-	app1, app2, vi := initializeInputDataDepth2()
+	sc, err := DepthScenario(2)
+	assert.NilError(t, err)
 
 	// initialising system model
 	sm2 := systemmodel.CreateSystemModelDepth2()
@@ -28,58 +34,42 @@ func TestUpdateReliabilities(t *testing.T) {
 	assert.Equal(t, rel, 0.77)
 
 	// setting initial reliabilities (in step #1)
-	err = UpdateReliabilities(meErtCore.SystemModel, 1, app1, app2, vi)
+	err = UpdateReliabilities(meErtCore.SystemModel, 1, sc)
 	assert.NilError(t, err)
 
 	// verifying that they were set successfully
-	// Application #1, instance 1
-	inst, err = meErtCore.SystemModel.GetInstance("App#2-1-1")
-	assert.NilError(t, err)
-	rel, err = inst.GetReliability()
-	assert.NilError(t, err)
-	assert.Assert(t, (rel < app1.inst1[1]+deviation) && (rel > app1.inst1[1]-deviation))
-
-	// Application #1, instance 2
-	inst, err = meErtCore.SystemModel.GetInstance("App#2-1-2")
-	assert.NilError(t, err)
-	rel, err = inst.GetReliability()
-	assert.NilError(t, err)
-	assert.Assert(t, (rel < app1.inst2[1]+deviation) && (rel > app1.inst2[1]-deviation))
-
-	// Application #1, instance 3
-	inst, err = meErtCore.SystemModel.GetInstance("App#2-1-3")
-	assert.NilError(t, err)
-	rel, err = inst.GetReliability()
-	assert.NilError(t, err)
-	assert.Assert(t, (rel < app1.inst3[1]+deviation) && (rel > app1.inst3[1]-deviation))
-
-	// Application #2, instance 1
-	inst, err = meErtCore.SystemModel.GetInstance("App#2-2-1")
-	assert.NilError(t, err)
-	rel, err = inst.GetReliability()
-	assert.NilError(t, err)
-	assert.Assert(t, (rel < app2.inst1[1]+deviation) && (rel > app2.inst1[1]-deviation))
-
-	// Application #2, instance 2
-	inst, err = meErtCore.SystemModel.GetInstance("App#2-2-2")
-	assert.NilError(t, err)
-	rel, err = inst.GetReliability()
-	assert.NilError(t, err)
-	assert.Assert(t, (rel < app2.inst2[1]+deviation) && (rel > app2.inst2[1]-deviation))
+	tc := map[string]Schedule{
+		"App#2-1-1": app1inst1,
+		"App#2-1-2": app1inst2,
+		"App#2-1-3": app1inst3,
+		"App#2-2-1": app2inst1,
+		"App#2-2-2": app2inst2,
+	}
+	for name, schedule := range tc {
+		inst, err = meErtCore.SystemModel.GetInstance(name)
+		assert.NilError(t, err)
+		rel, err = inst.GetReliability()
+		assert.NilError(t, err)
+		seg, ok := schedule.at(1)
+		assert.Assert(t, ok)
+		assert.Assert(t, (rel <= seg.Mean+deviation) && (rel >= seg.Mean-deviation), "%s has reliability %v", name, rel)
+	}
 
 	// iterating a bit more and checking critical points for us
 	for i := 2; i <= 100; i++ {
 		// setting reliabilities for each instance
-		err := UpdateReliabilities(meErtCore.SystemModel, i, app1, app2, vi)
+		err := UpdateReliabilities(meErtCore.SystemModel, i, sc)
 		assert.NilError(t, err)
-		// Application #1, instance 1
+		// Application #1, instance 2
 		if i == 60 {
 			inst, err := meErtCore.SystemModel.GetInstance("App#2-1-2")
 			assert.NilError(t, err)
 			rel, err := inst.GetReliability()
 			assert.NilError(t, err)
 			t.Logf("Reliability is %v", rel)
-			assert.Assert(t, (rel < app1.inst2[60]+deviation) && (rel > app1.inst2[60]-deviation))
+			seg, ok := app1inst2.at(60)
+			assert.Assert(t, ok)
+			assert.Assert(t, (rel <= seg.Mean+deviation) && (rel >= seg.Mean-deviation))
 		}
 		// Application #1, instance 2
 		if i == 30 {
@@ -87,31 +77,46 @@ func TestUpdateReliabilities(t *testing.T) {
 			assert.NilError(t, err)
 			rel, err := inst.GetReliability()
 			assert.NilError(t, err)
-			assert.Assert(t, (rel < 0.27+deviation) && (rel > 0.27-deviation))
+			assert.Assert(t, (rel <= 0.27+deviation) && (rel >= 0.27-deviation))
 		}
 
 	}
+
+	// steps out of the scenario are reported
+	err = UpdateReliabilities(meErtCore.SystemModel, 301, sc)
+	assert.ErrorContains(t, err, "step 301 is out of the scenario")
 }
 
 func TestMeasurementDepth4(t *testing.T) {
-	err := runMeasurementForDepth4(true)
+	err := runMeasurementForDepth(context.Background(), 4, true)
 	assert.NilError(t, err)
 }
 
 func TestMeasurementDepth3(t *testing.T) {
-	err := runMeasurementForDepth3(true)
+	err := runMeasurementForDepth(context.Background(), 3, true)
 	assert.NilError(t, err)
 }
 
 func TestMeasurementDepth2(t *testing.T) {
-	err := runMeasurementForDepth2(true)
+	err := runMeasurementForDepth(context.Background(), 2, true)
 	assert.NilError(t, err)
 }
 
-func TestGenerateRandomVectorOfLength(t *testing.T) {
-	v := generateRandomVectorOfLength(0.5, 10)
-	t.Logf("Generated vector is %v", v)
-	t.Logf("Maximum float64 is %v", math.MaxFloat64)
+func TestMeasurementCancelled(t *testing.T) {
+	defer storedata.SetDataDir(storedata.DataDir())
+	storedata.SetDataDir(t.TempDir())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// cancelled measurement stores partial results and reports the cancellation
+	err := RunMeasurementContext(ctx)
+	t.Logf("Cancelled measurement returns: %v", err)
+	assert.Assert(t, errors.Is(err, context.Canceled))
+	_, err = os.Stat(storedata.DataDir() + "me-ert-core_fmais_depth_4_partial.json")
+	assert.NilError(t, err)
+
+	err = runMeasurementWide(ctx, 10, 10, true)
+	assert.Assert(t, errors.Is(err, context.Canceled))
 }
 
 func TestComputeMeErtCoreReliabilities(t *testing.T) {
@@ -123,18 +128,45 @@ func TestComputeMeErtCoreReliabilities(t *testing.T) {
 		4: 0.3569875654,
 	}
 
-	_, err := computeMeErtCoreCoefficients(relMap, 4)
+	_, err := computeMeErtCoreCoefficients(relMap, 300, 4)
 	assert.ErrorContains(t, err, "obtained incomplete map")
 
-	v := generateInputDataForInstance(app1inst1)
-	t.Logf("Computed coefficients are: %v", v)
-	coefs, err := computeMeErtCoreCoefficients(v, 4)
+	v := make(map[int]float64, 300)
+	for i := 1; i <= 300; i++ {
+		seg, ok := app1inst1.at(i)
+		assert.Assert(t, ok)
+		v[i] = seg.generate(rnd)
+	}
+	t.Logf("Generated reliabilities are: %v", v)
+	coefs, err := computeMeErtCoreCoefficients(v, 300, 4)
 	assert.NilError(t, err)
 	t.Logf("Computed coefficients are: %v", coefs)
 }
 
+func TestSetSeed(t *testing.T) {
+	generate := func() []float64 {
+		v := make([]float64, 0, 10)
+		for i := 0; i < 10; i++ {
+			v = append(v, generateRandomNumber(rnd, 0.5, 0.1))
+		}
+		return v
+	}
+
+	SetSeed(42)
+	v1 := generate()
+	// generator shared by other packages doesn't interfere with the measurement
+	rand.Float64()
+	SetSeed(42)
+	v2 := generate()
+	t.Logf("Generated reliabilities are: %v", v1)
+	assert.DeepEqual(t, v1, v2)
+	for _, v := range v1 {
+		assert.Assert(t, v >= 0.4 && v <= 0.6)
+	}
+}
+
 func TestMeasurementWide(t *testing.T) {
-	err := runMeasurementWide(10, 10, true)
+	err := runMeasurementWide(context.Background(), 10, 10, true)
 	assert.NilError(t, err)
 }
 
@@ -147,7 +179,7 @@ func TestMeasurementWide2(t *testing.T) {
 	tc = append(tc, 1000) // FMAIS of depth 4 with 1000 Apps
 
 	// initializing input data
-	app, appFailed := InitializeInputDataWide()
+	sc := WideScenario()
 
 	// iterating over test cases
 	for _, val := range tc {
@@ -163,7 +195,7 @@ func TestMeasurementWide2(t *testing.T) {
 
 		///// step 1
 		// setting reliabilities for each instance
-		err = UpdateReliabilities(meErtCore.SystemModel, 1, appFailed, app)
+		err = UpdateReliabilities(meErtCore.SystemModel, 1, sc)
 		assert.NilError(t, err)
 
 		_, err = meErtCore.SystemModel.GatherAllApplicationsReliabilities()
@@ -177,7 +209,7 @@ func TestMeasurementWide2(t *testing.T) {
 
 		///// step 101
 		// setting reliabilities for each instance
-		err = UpdateReliabilities(meErtCore.SystemModel, 101, appFailed, app)
+		err = UpdateReliabilities(meErtCore.SystemModel, 101, sc)
 		assert.NilError(t, err)
 
 		_, err = meErtCore.SystemModel.GatherAllApplicationsReliabilities()
@@ -191,7 +223,7 @@ func TestMeasurementWide2(t *testing.T) {
 
 		///// step 150
 		// setting reliabilities for each instance
-		err = UpdateReliabilities(meErtCore.SystemModel, 150, appFailed, app)
+		err = UpdateReliabilities(meErtCore.SystemModel, 150, sc)
 		assert.NilError(t, err)
 
 		_, err = meErtCore.SystemModel.GatherAllApplicationsReliabilities()
@@ -205,7 +237,7 @@ func TestMeasurementWide2(t *testing.T) {
 
 		///// step 170
 		// setting reliabilities for each instance
-		err = UpdateReliabilities(meErtCore.SystemModel, 170, appFailed, app)
+		err = UpdateReliabilities(meErtCore.SystemModel, 170, sc)
 		assert.NilError(t, err)
 
 		_, err = meErtCore.SystemModel.GatherAllApplicationsReliabilities()
@@ -219,7 +251,7 @@ func TestMeasurementWide2(t *testing.T) {
 
 		///// step 200
 		// setting reliabilities for each instance
-		err = UpdateReliabilities(meErtCore.SystemModel, 200, appFailed, app)
+		err = UpdateReliabilities(meErtCore.SystemModel, 200, sc)
 		assert.NilError(t, err)
 
 		_, err = meErtCore.SystemModel.GatherAllApplicationsReliabilities()
@@ -237,14 +269,14 @@ func TestSmWideBench(t *testing.T) {
 	deviation = 0
 
 	numApps := 100
-	app, appFailed := InitializeInputDataWide()
+	sc := WideScenario()
 
 	sm, err := systemmodel.CreateSystemModelWideBench(numApps, 2, 4)
 	assert.NilError(t, err)
 	assert.Equal(t, len(sm.Applications)-1, numApps)
 	assert.Equal(t, sm.Depth, 4)
 
-	err = UpdateReliabilities(sm, 101, appFailed, app)
+	err = UpdateReliabilities(sm, 101, sc)
 	assert.NilError(t, err)
 
 	_, err = sm.GatherAllApplicationsReliabilities()
@@ -255,7 +287,7 @@ func TestSmWideBench2(t *testing.T) {
 	deviation = 0.05
 
 	numApps := 100
-	app, appFailed := InitializeInputDataWide()
+	sc := WideScenario()
 
 	sm, err := systemmodel.CreateSystemModelWideBench(numApps, 26, 4)
 	assert.NilError(t, err)
@@ -266,7 +298,7 @@ func TestSmWideBench2(t *testing.T) {
 		Reliability: 0.0,
 	}
 
-	err = UpdateReliabilities(meErtCore.SystemModel, 101, appFailed, app)
+	err = UpdateReliabilities(meErtCore.SystemModel, 101, sc)
 	assert.NilError(t, err)
 
 	_, err = meErtCore.SystemModel.GatherAllApplicationsReliabilities()
@@ -285,7 +317,7 @@ func TestSmWideBench3(t *testing.T) {
 	deviation = 0.05
 
 	numApps := 6
-	app, appFailed := InitializeInputDataWide()
+	sc := WideScenario()
 
 	sm, err := systemmodel.CreateSystemModelWideBench(numApps, 6, 4)
 	assert.NilError(t, err)
@@ -296,7 +328,7 @@ func TestSmWideBench3(t *testing.T) {
 		Reliability: 0.0,
 	}
 
-	err = UpdateReliabilities(meErtCore.SystemModel, 101, appFailed, app)
+	err = UpdateReliabilities(meErtCore.SystemModel, 101, sc)
 	assert.NilError(t, err)
 
 	_, err = meErtCore.SystemModel.GatherAllApplicationsReliabilities()
@@ -315,7 +347,7 @@ func TestSmWideBench4(t *testing.T) {
 	deviation = 0.05
 
 	numApps := 1
-	app, appFailed := InitializeInputDataWide()
+	sc := WideScenario()
 
 	sm, err := systemmodel.CreateSystemModelWideBench(numApps, 1, 3)
 	assert.NilError(t, err)
@@ -326,7 +358,7 @@ func TestSmWideBench4(t *testing.T) {
 		Reliability: 0.0,
 	}
 
-	err = UpdateReliabilities(meErtCore.SystemModel, 101, appFailed, app)
+	err = UpdateReliabilities(meErtCore.SystemModel, 101, sc)
 	assert.NilError(t, err)
 
 	_, err = meErtCore.SystemModel.GatherAllApplicationsReliabilities()
@@ -340,3 +372,43 @@ func TestSmWideBench4(t *testing.T) {
 	assert.NilError(t, err)
 	t.Logf("Reliability computed with ME-ERT-CORE (per definition) is %v\n", meErtCore.Reliability)
 }
+
+func TestSetSettings(t *testing.T) {
+	defer func(s Settings) { settings = s }(settings)
+
+	assert.ErrorContains(t, SetSettings(Settings{Deviation: -0.1, WideMaxApps: 100, WideStep: 10}), "deviation")
+	assert.ErrorContains(t, SetSettings(Settings{WideMaxApps: 5, WideStep: 10}), "at least 10")
+	assert.ErrorContains(t, SetSettings(Settings{WideMaxApps: 100}), "should be positive")
+	assert.NilError(t, SetSettings(Settings{Deviation: 0.01, WideMaxApps: 100, WideStep: 10, Seed: 42}))
+	assert.Equal(t, settings.Seed, int64(42))
+}
+
+func TestReliabilityBand(t *testing.T) {
+	defer func(d float64) { deviation = d }(deviation)
+	sc, err := DepthScenario(4)
+	assert.NilError(t, err)
+	sm := systemmodel.CreateSystemModelDepth4()
+
+	deviation = 0.025
+	mean, lower, upper, err := reliabilityBand(sm, sc, 0.95)
+	assert.NilError(t, err)
+	assert.Equal(t, len(lower), sc.Steps)
+	assert.Equal(t, len(upper), sc.Steps)
+	for i := 1; i <= sc.Steps; i++ {
+		assert.Assert(t, lower[i] < mean[i] && mean[i] < upper[i], "step %d: %v [%v, %v]", i, mean[i], lower[i], upper[i])
+	}
+
+	// without any deviation, the band collapses to the reliability computed per definition
+	deviation = 0
+	mean, lower, upper, err = reliabilityBand(sm, sc, 0.95)
+	assert.NilError(t, err)
+	for _, step := range []int{1, 60, 170, 280} {
+		assert.NilError(t, UpdateReliabilities(sm, step, sc))
+		meErtCore := meertcore.MeErtCore{SystemModel: sm}
+		rel, err := meErtCore.ComputeReliabilityPerDefinition()
+		assert.NilError(t, err)
+		t.Logf("Step %d: band is [%v, %v], reliability is %v", step, lower[step], upper[step], rel)
+		assert.Equal(t, lower[step], upper[step])
+		assert.Assert(t, math.Abs(mean[step]-rel) < 1e-9)
+	}
+}
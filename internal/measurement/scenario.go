@@ -0,0 +1,388 @@
+// Package measurement provides a measurement logic and all helper functions. This file in particular implements
+// measurement scenarios, i.e., piecewise schedules of the mean reliability (and its deviation) of each instance of
+// each application, which drive reliabilities of a System Model step by step.
+package measurement
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"gopkg.in/yaml.v3"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// anyName matches any application (or instance), which has no schedule of its own in the scenario
+const anyName = "*"
+
+// Segment defines a mean reliability of an instance in a range of steps
+type Segment struct {
+	From      int      `json:"from" yaml:"from"`                               // first step of the segment
+	To        int      `json:"to" yaml:"to"`                                   // last step of the segment (inclusive)
+	Mean      float64  `json:"mean" yaml:"mean"`                               // mean value of the reliability in the segment
+	Deviation *float64 `json:"deviation,omitempty" yaml:"deviation,omitempty"` // deviation around the mean value (the one of the settings, if not set)
+}
+
+// Schedule is a piecewise schedule of the reliability of an instance. Its segments follow each other and cover all
+// steps of the scenario.
+type Schedule []Segment
+
+// Scenario structure describes how reliabilities of the instances of a System Model evolve during the measurement.
+// Applications are referenced by their names (VIaaS by the name of its instance, e.g., VI#3-4) and instances by
+// their numbers. Application (or instance) "*" matches any application (or instance) without a schedule of its own.
+// If the scenario is shared, all instances of an application with a different number of instances than the number of
+// its numbered schedules are generated around a single reliability of the application, which is generated from its
+// schedule "*" in each step.
+type Scenario struct {
+	Name         string                         `json:"name" yaml:"name"`                                   // name of the scenario, it is a part of the names of the results
+	Steps        int                            `json:"steps" yaml:"steps"`                                 // number of steps of the measurement
+	Depth        int                            `json:"depth,omitempty" yaml:"depth,omitempty"`             // depth of the measurement FMAIS (2, 3 or 4) driven by the scenario
+	SystemModel  string                         `json:"systemModel,omitempty" yaml:"systemModel,omitempty"` // JSON file with the FMAIS driven by the scenario
+	Applications map[string]map[string]Schedule `json:"applications" yaml:"applications"`                   // schedules of the instances of each application
+	Shared       bool                           `json:"shared,omitempty" yaml:"shared,omitempty"`           // instances of the applications, which don't match their numbered schedules, share schedule "*"
+}
+
+// DepthScenario returns the scenario of the paper for the measurement FMAIS of a given depth (2, 3 or 4)
+func DepthScenario(depth int) (*Scenario, error) {
+	sc := &Scenario{
+		Name:  "fmais_depth_" + strconv.Itoa(depth),
+		Steps: stepsPaper,
+		Depth: depth,
+		Applications: map[string]map[string]Schedule{
+			app1Name: {"1": app1inst1, "2": app1inst2, "3": app1inst3},
+			app2Name: {"1": app2inst1, "2": app2inst2},
+		},
+	}
+	switch depth {
+	case 2:
+		sc.Applications["VI#2-1"] = map[string]Schedule{"1": viaas}
+	case 3:
+		sc.Applications[app3Name] = map[string]Schedule{"1": app3inst1, "2": app3inst2}
+		sc.Applications["VI#3-3"] = map[string]Schedule{"1": viaas}
+	case 4:
+		sc.Applications[app3Name] = map[string]Schedule{"1": app3inst1, "2": app3inst2}
+		sc.Applications[app4Name] = map[string]Schedule{"1": app4inst1}
+		sc.Applications["VI#3-4"] = map[string]Schedule{"1": viaas}
+	default:
+		return nil, fmt.Errorf("only measurement FMAIS of depth 2, 3 or 4 is available, got %d", depth)
+	}
+	return sc, nil
+}
+
+// WideScenario returns the scenario of the paper for the large-scale FMAIS, where only Application #1 fails.
+// Applications with other than two instances are generated around the reliability of Instance #2 with all their
+// instances.
+func WideScenario() *Scenario {
+	return &Scenario{
+		Name:   "fmais_wide",
+		Steps:  stepsPaper,
+		Shared: true,
+		Applications: map[string]map[string]Schedule{
+			appFailName: {"1": appFailInst1, "2": appFailInst2, anyName: appFailInst2},
+			anyName:     {"1": appInst1, "2": appInst2, anyName: appInst2},
+		},
+	}
+}
+
+// LoadScenario reads a scenario from a YAML (.yaml, .yml) or JSON (.json) file and validates it
+func LoadScenario(fileName string) (*Scenario, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	sc := &Scenario{}
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(content))
+		dec.KnownFields(true)
+		err = dec.Decode(sc)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(content))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(sc)
+	default:
+		return nil, fmt.Errorf("unknown format of the scenario file %s (expected .yaml, .yml or .json)", fileName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse scenario from %s: %w", fileName, err)
+	}
+	if err := sc.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario in %s: %w", fileName, err)
+	}
+	return sc, nil
+}
+
+// Validate checks that the scenario is named, and that the schedule of each instance covers all its steps
+func (sc *Scenario) Validate() error {
+	if sc.Name == "" || strings.ContainsAny(sc.Name, `/\`) {
+		return fmt.Errorf("scenario should have a name without path separators, got %q", sc.Name)
+	}
+	if sc.Steps < 1 {
+		return fmt.Errorf("scenario %s should have a positive number of steps, got %d", sc.Name, sc.Steps)
+	}
+	if len(sc.Applications) == 0 {
+		return fmt.Errorf("scenario %s doesn't define any application", sc.Name)
+	}
+	for _, app := range sortedKeys(sc.Applications) {
+		instances := sc.Applications[app]
+		for _, inst := range sortedKeys(instances) {
+			if n, err := strconv.Atoi(inst); inst != anyName && (err != nil || n < 1) {
+				return fmt.Errorf("instance %q of %s should be a positive number or %s", inst, app, anyName)
+			}
+			if err := instances[inst].validate(sc.Steps); err != nil {
+				return fmt.Errorf("schedule of instance %s of %s: %w", inst, app, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks that segments of the schedule follow each other and cover steps from 1 to steps
+func (s Schedule) validate(steps int) error {
+	next := 1
+	for _, seg := range s {
+		if seg.From != next {
+			return fmt.Errorf("segment [%d, %d] should start at step %d", seg.From, seg.To, next)
+		}
+		if seg.To < seg.From {
+			return fmt.Errorf("segment [%d, %d] ends before it starts", seg.From, seg.To)
+		}
+		if seg.Mean < 0 || seg.Mean > 1 {
+			return fmt.Errorf("mean reliability %v of the segment [%d, %d] should be within [0, 1]", seg.Mean, seg.From, seg.To)
+		}
+		if seg.Deviation != nil && *seg.Deviation < 0 {
+			return fmt.Errorf("deviation %v of the segment [%d, %d] should be non-negative", *seg.Deviation, seg.From, seg.To)
+		}
+		next = seg.To + 1
+	}
+	if next != steps+1 {
+		return fmt.Errorf("schedule covers steps from 1 to %d, but the scenario has %d steps", next-1, steps)
+	}
+	return nil
+}
+
+// at returns a segment of the schedule, which covers a given step
+func (s Schedule) at(step int) (Segment, bool) {
+	for _, seg := range s {
+		if step >= seg.From && step <= seg.To {
+			return seg, true
+		}
+	}
+	return Segment{}, false
+}
+
+// dev returns a deviation of the reliability in the segment
+func (seg Segment) dev() float64 {
+	if seg.Deviation != nil {
+		return *seg.Deviation
+	}
+	return deviation
+}
+
+// generate generates a random reliability around the mean value of the segment with a given generator
+func (seg Segment) generate(r *rand.Rand) float64 {
+	return generateRandomNumber(r, seg.Mean, seg.dev())
+}
+
+// systemModel returns the FMAIS driven by the scenario. Relative path to the System Model file is resolved against dir.
+func (sc *Scenario) systemModel(dir string) (*systemmodel.SystemModel, error) {
+	if sc.SystemModel == "" {
+		return measurementSystemModel(sc.Depth)
+	}
+	if sc.Depth != 0 {
+		return nil, fmt.Errorf("scenario %s should set either depth, or systemModel, not both", sc.Name)
+	}
+	fileName := sc.SystemModel
+	if !filepath.IsAbs(fileName) {
+		fileName = filepath.Join(dir, fileName)
+	}
+	return systemmodel.LoadSystemModel(fileName)
+}
+
+// measurementSystemModel returns the measurement FMAIS of a given depth
+func measurementSystemModel(depth int) (*systemmodel.SystemModel, error) {
+	switch depth {
+	case 2:
+		return systemmodel.CreateSystemModelDepth2(), nil
+	case 3:
+		return systemmodel.CreateSystemModelDepth3(), nil
+	case 4:
+		return systemmodel.CreateSystemModelDepth4(), nil
+	default:
+		return nil, fmt.Errorf("only measurement FMAIS of depth 2, 3 or 4 is available, got %d", depth)
+	}
+}
+
+// applicationNames returns names of the applications of the System Model, which are driven by the scenario, in
+// a stable order (so that a seeded measurement is reproducible)
+func (sc *Scenario) applicationNames(sm *systemmodel.SystemModel) []string {
+	names := make([]string, 0, len(sc.Applications))
+	for _, name := range sortedKeys(sc.Applications) {
+		if name != anyName {
+			names = append(names, name)
+		}
+	}
+	if _, ok := sc.Applications[anyName]; !ok {
+		return names
+	}
+	others := make([]string, 0, len(sm.Applications))
+	for name, app := range sm.Applications {
+		if _, ok := sc.Applications[name]; ok || !app.State || isVI(name) {
+			continue
+		}
+		others = append(others, name)
+	}
+	sort.Strings(others)
+	return append(names, others...)
+}
+
+// reliabilities generates reliabilities of all instances of an application in a given step
+func (sc *Scenario) reliabilities(sm *systemmodel.SystemModel, name string, step int) (map[int64]float64, error) {
+	schedules, ok := sc.Applications[name]
+	if !ok {
+		schedules = sc.Applications[anyName]
+	}
+
+	// VIaaS is referenced by the name of its instance, so only its listed instances are updated
+	numbers := make([]int, 0, len(schedules))
+	if app, ok := sm.Applications[name]; ok {
+		for n := 1; n <= app.Rules; n++ {
+			numbers = append(numbers, n)
+		}
+	} else if isVI(name) {
+		for _, inst := range sortedKeys(schedules) {
+			if n, err := strconv.Atoi(inst); err == nil {
+				numbers = append(numbers, n)
+			}
+		}
+	} else {
+		return nil, fmt.Errorf("%s: %w", name, systemmodel.ErrAppNotFound)
+	}
+
+	rels := make(map[int64]float64, len(numbers))
+	if schedule, ok := sc.sharedSchedule(schedules, len(numbers)); ok {
+		seg, ok := schedule.at(step)
+		if !ok {
+			return nil, fmt.Errorf("step %d is out of the scenario %s with %d steps", step, sc.Name, sc.Steps)
+		}
+		mean := seg.generate(rnd)
+		for _, n := range numbers {
+			rels[int64(n)] = generateRandomNumber(rnd, mean, seg.dev())
+		}
+		return rels, nil
+	}
+	for _, n := range numbers {
+		schedule, ok := schedules[strconv.Itoa(n)]
+		if !ok {
+			schedule, ok = schedules[anyName]
+		}
+		if !ok {
+			return nil, fmt.Errorf("scenario %s has no schedule for instance %d of %s", sc.Name, n, name)
+		}
+		seg, ok := schedule.at(step)
+		if !ok {
+			return nil, fmt.Errorf("step %d is out of the scenario %s with %d steps", step, sc.Name, sc.Steps)
+		}
+		rels[int64(n)] = seg.generate(rnd)
+	}
+	return rels, nil
+}
+
+// sharedSchedule returns schedule "*" of an application with a given number of instances, if all its instances share it
+func (sc *Scenario) sharedSchedule(schedules map[string]Schedule, instances int) (Schedule, bool) {
+	schedule, ok := schedules[anyName]
+	if !sc.Shared || !ok {
+		return nil, false
+	}
+	numbered := 0
+	for inst := range schedules {
+		if inst != anyName {
+			numbered++
+		}
+	}
+	return schedule, instances != numbered
+}
+
+// drivenLeaves returns schedules of the leaf instances of the System Model, which are driven by the scenario. Leaves
+// are referenced by their paths, since VIs of a single deployment share their name.
+func (sc *Scenario) drivenLeaves(sm *systemmodel.SystemModel) (map[string]Schedule, error) {
+	paths := sm.InstancePaths()
+	driven := make(map[string]bool, len(sc.Applications))
+	for _, name := range sc.applicationNames(sm) {
+		driven[name] = true
+	}
+
+	leaves := make(map[string]Schedule)
+	for _, layer := range sm.Layers {
+		for _, inst := range layer.Instances {
+			if len(inst.Relations) != 0 {
+				continue
+			}
+			name, err := inst.GetAppName()
+			if err != nil {
+				return nil, err
+			}
+			if !driven[name] {
+				continue
+			}
+			schedules, ok := sc.Applications[name]
+			if !ok {
+				schedules = sc.Applications[anyName]
+			}
+			if app, ok := sm.Applications[name]; ok {
+				if schedule, ok := sc.sharedSchedule(schedules, app.Rules); ok {
+					leaves[paths[inst]] = schedule
+					continue
+				}
+			}
+			// VIaaS is updated with the reliability of its instance #1
+			number := int64(1)
+			if !inst.IsVI() {
+				number, err = inst.GetInstanceNumber()
+				if err != nil {
+					return nil, err
+				}
+			}
+			schedule, ok := schedules[strconv.FormatInt(number, 10)]
+			if !ok {
+				schedule, ok = schedules[anyName]
+			}
+			if ok {
+				leaves[paths[inst]] = schedule
+			}
+		}
+	}
+	return leaves, nil
+}
+
+// isVI returns true, if the name belongs to VIaaS (either the application, or its instance)
+func isVI(name string) bool {
+	return strings.HasPrefix(name, "VI")
+}
+
+// appCount returns the number of applications of the System Model, VIaaS is not counted
+func appCount(sm *systemmodel.SystemModel) int {
+	count := 0
+	for name := range sm.Applications {
+		if !isVI(name) {
+			count++
+		}
+	}
+	return count
+}
+
+// sortedKeys returns keys of a map in ascending order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,170 @@
+package measurement
+
+import (
+	"context"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"gotest.tools/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadScenario(t *testing.T) {
+	defer func(d float64) { deviation = d }(deviation)
+	deviation = 0
+	dir := t.TempDir()
+
+	// scenario drives measurement FMAIS of depth 2, Application #2 follows the wildcard schedule
+	fileName := filepath.Join(dir, "scenario.json")
+	err := os.WriteFile(fileName, []byte(`{
+ "name": "app1-outage",
+ "steps": 10,
+ "depth": 2,
+ "applications": {
+  "App#1": {"1": [{"from": 1, "to": 4, "mean": 0.9}, {"from": 5, "to": 10, "mean": 0.1}], "*": [{"from": 1, "to": 10, "mean": 0.8}]},
+  "*": {"*": [{"from": 1, "to": 10, "mean": 0.6}]},
+  "VI#2-1": {"1": [{"from": 1, "to": 10, "mean": 0.95, "deviation": 0}]}
+ }
+}`), 0644)
+	assert.NilError(t, err)
+	sc, err := LoadScenario(fileName)
+	assert.NilError(t, err)
+	sm, err := sc.systemModel(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, sm.Depth, 2)
+
+	err = UpdateReliabilities(sm, 5, sc)
+	assert.NilError(t, err)
+	for name, expected := range map[string]float64{"App#2-1-1": 0.1, "App#2-1-3": 0.8, "App#2-2-1": 0.6, "VI#2-1": 0.95} {
+		inst, err := sm.GetInstance(name)
+		assert.NilError(t, err)
+		rel, err := inst.GetReliability()
+		assert.NilError(t, err)
+		t.Logf("%s has reliability %v", name, rel)
+		assert.Equal(t, rel, expected, name)
+	}
+	assert.NilError(t, runScenarioFile(context.Background(), fileName, true))
+
+	// the same scenario in YAML drives the FMAIS loaded from a file (relative to the scenario)
+	err = systemmodel.CreateSystemModelDepth3().SaveSystemModel(filepath.Join(dir, "fmais.json"))
+	assert.NilError(t, err)
+	fileName = filepath.Join(dir, "scenario.yaml")
+	err = os.WriteFile(fileName, []byte(`name: fmais-file
+steps: 20
+systemModel: fmais.json
+applications:
+  "*":
+    1:
+      - {from: 1, to: 10, mean: 0.7}
+      - {from: 11, to: 20, mean: 0.2, deviation: 0.05}
+    "*":
+      - {from: 1, to: 20, mean: 0.5}
+`), 0644)
+	assert.NilError(t, err)
+	sc, err = LoadScenario(fileName)
+	assert.NilError(t, err)
+	assert.Equal(t, len(sc.Applications["*"]), 2)
+	assert.NilError(t, runScenarioFile(context.Background(), fileName, true))
+}
+
+func TestScenarioValidate(t *testing.T) {
+	segment := func(from, to int, mean float64) Segment {
+		return Segment{From: from, To: to, Mean: mean}
+	}
+	negative := -0.1
+	tc := map[string]Scenario{
+		"should have a name":         {Steps: 10, Applications: map[string]map[string]Schedule{"App#1": {"1": {segment(1, 10, 0.5)}}}},
+		"positive number of steps":   {Name: "s", Applications: map[string]map[string]Schedule{"App#1": {"1": {segment(1, 10, 0.5)}}}},
+		"doesn't define any":         {Name: "s", Steps: 10},
+		"should be a positive":       {Name: "s", Steps: 10, Applications: map[string]map[string]Schedule{"App#1": {"0": {segment(1, 10, 0.5)}}}},
+		"should start at step 6":     {Name: "s", Steps: 10, Applications: map[string]map[string]Schedule{"App#1": {"1": {segment(1, 5, 0.5), segment(7, 10, 0.5)}}}},
+		"ends before it starts":      {Name: "s", Steps: 10, Applications: map[string]map[string]Schedule{"App#1": {"1": {segment(1, 0, 0.5)}}}},
+		"should be within [0, 1]":    {Name: "s", Steps: 10, Applications: map[string]map[string]Schedule{"App#1": {"1": {segment(1, 10, 1.5)}}}},
+		"covers steps from 1 to 5":   {Name: "s", Steps: 10, Applications: map[string]map[string]Schedule{"App#1": {"1": {segment(1, 5, 0.5)}}}},
+		"should be non-negative":     {Name: "s", Steps: 10, Applications: map[string]map[string]Schedule{"App#1": {"1": {{From: 1, To: 10, Mean: 0.5, Deviation: &negative}}}}},
+		"name without path separato": {Name: "../s", Steps: 10, Applications: map[string]map[string]Schedule{"App#1": {"1": {segment(1, 10, 0.5)}}}},
+	}
+	for expected, sc := range tc {
+		err := sc.Validate()
+		t.Logf("Validation returns: %v", err)
+		assert.ErrorContains(t, err, expected)
+	}
+
+	// paper scenarios are valid and cover all applications of the measurement FMAIS
+	for depth := 2; depth <= 4; depth++ {
+		sc, err := DepthScenario(depth)
+		assert.NilError(t, err)
+		assert.NilError(t, sc.Validate())
+	}
+	assert.NilError(t, WideScenario().Validate())
+	_, err := DepthScenario(5)
+	assert.ErrorContains(t, err, "depth 2, 3 or 4")
+
+	// FMAIS should be set exactly once
+	sc := WideScenario()
+	_, err = sc.systemModel(".")
+	assert.ErrorContains(t, err, "got 0")
+	sc.Depth, sc.SystemModel = 2, "fmais.json"
+	_, err = sc.systemModel(".")
+	assert.ErrorContains(t, err, "either depth, or systemModel")
+
+	// scenario referencing unknown application is reported
+	sc, err = DepthScenario(4)
+	assert.NilError(t, err)
+	err = UpdateReliabilities(systemmodel.CreateSystemModelDepth2(), 1, sc)
+	assert.ErrorContains(t, err, "App#3")
+}
+
+func TestWideScenario(t *testing.T) {
+	defer func(d float64) { deviation = d }(deviation)
+	deviation = 0.01
+	sc := WideScenario()
+
+	// applications with two instances follow the schedules of their instances
+	sm, err := systemmodel.CreateSystemModelWideBench(10, 2, 4)
+	assert.NilError(t, err)
+	for _, app := range []string{appFailName, "App#2"} {
+		rels, err := sc.reliabilities(sm, app, 170)
+		assert.NilError(t, err)
+		t.Logf("Reliabilities of %s are %v", app, rels)
+		assert.Equal(t, len(rels), 2)
+		assert.Assert(t, rels[1] >= 0.46-deviation && rels[1] <= 0.46+deviation)
+	}
+
+	// all instances of other applications are generated around the (generated) reliability of Instance #2
+	sm, err = systemmodel.CreateSystemModelWideBench(10, 6, 4)
+	assert.NilError(t, err)
+	for app, mean := range map[string]float64{appFailName: 0.59, "App#2": 0.69} {
+		rels, err := sc.reliabilities(sm, app, 170)
+		assert.NilError(t, err)
+		t.Logf("Reliabilities of %s are %v", app, rels)
+		assert.Equal(t, len(rels), 6)
+		for _, rel := range rels {
+			assert.Assert(t, rel >= mean-2*deviation && rel <= mean+2*deviation, "%s has reliability %v", app, rel)
+		}
+	}
+	leaves, err := sc.drivenLeaves(sm)
+	assert.NilError(t, err)
+	for name, schedule := range leaves {
+		seg, ok := schedule.at(170)
+		assert.Assert(t, ok)
+		assert.Assert(t, seg.Mean == 0.59 || seg.Mean == 0.69, "%s follows mean %v", name, seg.Mean)
+	}
+}
+
+func TestLoadScenarioErrors(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "scenario.json")
+	assert.NilError(t, os.WriteFile(fileName, []byte(`{"name": "s", "steps": 1, "typo": 1}`), 0644))
+	_, err := LoadScenario(fileName)
+	assert.ErrorContains(t, err, "unknown field")
+
+	assert.NilError(t, os.WriteFile(fileName, []byte(`{"name": "s", "steps": 1}`), 0644))
+	_, err = LoadScenario(fileName)
+	assert.ErrorContains(t, err, "doesn't define any application")
+
+	fileName = filepath.Join(dir, "scenario.txt")
+	assert.NilError(t, os.WriteFile(fileName, []byte(`name: s`), 0644))
+	_, err = LoadScenario(fileName)
+	assert.ErrorContains(t, err, "unknown format")
+}
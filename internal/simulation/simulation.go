@@ -0,0 +1,381 @@
+// Package simulation implements a discrete-event simulation of the Fractal MAIS evolution over time. The System Model
+// is advanced by a virtual clock. At each tick Applications deploy or undeploy, instances fail and recover,
+// and ME-ERT-CORE reliability of the System Model is sampled.
+package simulation
+
+import (
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/draw"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/meertcore"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"log/slog"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Config structure holds parameters of the simulation. Time is measured in ticks of the virtual clock.
+type Config struct {
+	Ticks              int     // number of ticks to simulate
+	Seed               int64   // seed of the random number generator, the same seed reproduces the same run
+	ChurnRate          float64 // per tick rate of (un)deployment, Application deploys with ChurnRate*Probability and undeploys with ChurnRate*(1-Probability)
+	MaxDepth           int     // Applications are (re-)deployed only up to this depth, 0 stands for depth of the System Model
+	DefaultMTBF        float64 // Mean Time Between Failures used for instances, which (and their Application) do not define it
+	DefaultMTTR        float64 // Mean Time To Repair used for instances, which (and their Application) do not define it
+	NominalReliability float64 // reliability of an operational instance, which has no reliability of its own (e.g., newly deployed)
+}
+
+// Sample structure holds the state of the System Model sampled at a single tick
+type Sample struct {
+	Tick         int     `json:"tick"`                 // value of the virtual clock
+	Reliability  float64 `json:"reliability"`          // ME-ERT-CORE reliability (per definition)
+	Availability float64 `json:"availability"`         // share of operational instances among instances with no relations
+	Instances    int64   `json:"instances"`            // total number of instances in the System Model
+	Deployed     int     `json:"deployedApplications"` // number of deployed Applications (VI excluded)
+	Failed       int     `json:"failedInstances"`      // number of failed instances
+}
+
+// Simulator structure carries the state of the simulation
+type Simulator struct {
+	config      Config
+	systemModel *systemmodel.SystemModel
+	meErtCore   *meertcore.MeErtCore
+	rnd         *rand.Rand
+	clock       int
+	nominal     map[*systemmodel.Instance]float64 // reliability of the instances, when they are operational
+	failed      map[*systemmodel.Instance]bool    // instances, which are currently failed
+}
+
+// DefaultConfig returns a configuration of the simulation with reasonable defaults
+func DefaultConfig() Config {
+	return Config{
+		Ticks:              300,
+		Seed:               1,
+		ChurnRate:          0.05,
+		DefaultMTBF:        100,
+		DefaultMTTR:        5,
+		NominalReliability: 0.9,
+	}
+}
+
+// NewSimulator creates a simulator for a copy of the provided System Model, which stays intact. Reliability
+// of all instances with no relations should be set, it is used as their reliability when they are operational.
+func NewSimulator(sm *systemmodel.SystemModel, config Config) (*Simulator, error) {
+	if config.Ticks < 1 {
+		return nil, fmt.Errorf("number of ticks should be positive, got %d", config.Ticks)
+	}
+	if config.ChurnRate < 0 || config.ChurnRate > 1 {
+		return nil, fmt.Errorf("churn rate should be within [0, 1], got %v", config.ChurnRate)
+	}
+	if config.DefaultMTBF <= 0 || config.DefaultMTTR <= 0 {
+		return nil, fmt.Errorf("default MTBF and MTTR should be positive, got %v and %v", config.DefaultMTBF, config.DefaultMTTR)
+	}
+
+	s := &Simulator{
+		config:      config,
+		systemModel: sm.Clone(),
+		rnd:         rand.New(rand.NewSource(config.Seed)),
+		nominal:     make(map[*systemmodel.Instance]float64, sm.GetTotalNumberOfInstances()),
+		failed:      make(map[*systemmodel.Instance]bool, 0),
+	}
+	if s.config.MaxDepth == 0 {
+		s.config.MaxDepth = s.systemModel.Depth
+	}
+	s.meErtCore = &meertcore.MeErtCore{
+		SystemModel: s.systemModel,
+		Reliability: 0.0,
+	}
+
+	// remembering reliability of the operational instances
+	for _, layer := range s.systemModel.Layers {
+		for _, inst := range layer.Instances {
+			if len(inst.Relations) != 0 {
+				continue
+			}
+			rel, err := inst.GetReliability()
+			if err != nil {
+				return nil, err
+			}
+			s.nominal[inst] = rel
+		}
+	}
+	s.systemModel.Subscribe(s.onChange)
+
+	return s, nil
+}
+
+// SystemModel returns the simulated System Model in its current state
+func (s *Simulator) SystemModel() *systemmodel.SystemModel {
+	return s.systemModel
+}
+
+// Run advances the virtual clock for a configured number of ticks and returns sample for each tick
+func (s *Simulator) Run() ([]Sample, error) {
+	samples := make([]Sample, 0, s.config.Ticks)
+	for s.clock < s.config.Ticks {
+		sample, err := s.Step()
+		if err != nil {
+			return nil, fmt.Errorf("tick %d: %w", s.clock, err)
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+// Step advances the virtual clock by one tick: Applications are (un)deployed, instances fail or recover
+// and reliability of the System Model is sampled
+func (s *Simulator) Step() (Sample, error) {
+	s.clock++
+
+	err := s.churn()
+	if err != nil {
+		return Sample{}, err
+	}
+	err = s.failAndRecover()
+	if err != nil {
+		return Sample{}, err
+	}
+	up, leaves := s.applyReliabilities()
+
+	rel, err := s.meErtCore.ComputeReliabilityPerDefinition()
+	if err != nil {
+		return Sample{}, err
+	}
+
+	deployed := 0
+	for name, app := range s.systemModel.Applications {
+		if app.State && !strings.HasPrefix(name, "VI") {
+			deployed++
+		}
+	}
+
+	return Sample{
+		Tick:         s.clock,
+		Reliability:  rel,
+		Availability: float64(up) / float64(leaves),
+		Instances:    s.systemModel.GetTotalNumberOfInstances(),
+		Deployed:     deployed,
+		Failed:       len(s.failed),
+	}, nil
+}
+
+// onChange forgets the state of the instances, which were removed from the System Model. The state is kept by
+// the instances themselves (same-named VIs fail on their own), thus renamed instances keep it and deployed
+// instances are operational with the nominal reliability.
+func (s *Simulator) onChange(event systemmodel.ChangeEvent) {
+	if event.Type != systemmodel.InstanceRemoved {
+		return
+	}
+	present := make(map[*systemmodel.Instance]bool, len(s.nominal))
+	for _, layer := range s.systemModel.Layers {
+		for _, inst := range layer.Instances {
+			present[inst] = true
+		}
+	}
+	for inst := range s.nominal {
+		if !present[inst] {
+			delete(s.nominal, inst)
+		}
+	}
+	for inst := range s.failed {
+		if !present[inst] {
+			delete(s.failed, inst)
+		}
+	}
+}
+
+// churn deploys Applications, which are not deployed, and undeploys deployed Applications with regard
+// to their deployment probability. VIs are not (un)deployed.
+func (s *Simulator) churn() error {
+	if s.config.ChurnRate == 0 {
+		return nil
+	}
+
+	// sorting names to keep the simulation reproducible
+	names := make([]string, 0, len(s.systemModel.Applications))
+	for name := range s.systemModel.Applications {
+		if !strings.HasPrefix(name, "VI") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		app := s.systemModel.Applications[name]
+		if !app.State {
+			if s.rnd.Float64() >= s.config.ChurnRate*float64(app.Probability) {
+				continue
+			}
+			vis := s.eligibleVIs()
+			if len(vis) == 0 {
+				continue
+			}
+			_, err := s.systemModel.DeployApplicationAt(vis[s.rnd.Intn(len(vis))], name)
+			if err != nil {
+				return err
+			}
+		} else {
+			if s.rnd.Float64() >= s.config.ChurnRate*(1-float64(app.Probability)) {
+				continue
+			}
+			err := s.undeploy(name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// eligibleVIs returns paths of all VIs (including root instance), which can deploy an Application
+// without exceeding maximum depth. Paths are used, since VIs deployed at once share the same name.
+func (s *Simulator) eligibleVIs() []string {
+	vis := make([]string, 0)
+	paths := s.systemModel.InstancePaths()
+	for level := 1; level < s.config.MaxDepth && level <= len(s.systemModel.Layers); level++ {
+		for _, inst := range s.systemModel.Layers[level].Instances {
+			if inst.IsVI() {
+				vis = append(vis, paths[inst])
+			}
+		}
+	}
+	return vis
+}
+
+// undeploy removes all instances of an Application from the System Model. Instances are removed by their paths
+// in the reverse order, so that removal of a same-named sibling does not change paths of the remaining instances.
+func (s *Simulator) undeploy(appName string) error {
+	instances := make([]string, 0)
+	paths := s.systemModel.InstancePaths()
+	for level := 1; level <= len(s.systemModel.Layers); level++ {
+		for _, inst := range s.systemModel.Layers[level].Instances {
+			if !inst.IsApp() {
+				continue
+			}
+			name, err := inst.GetAppName()
+			if err != nil {
+				return err
+			}
+			if name == appName {
+				instances = append(instances, paths[inst])
+			}
+		}
+	}
+	for i := len(instances) - 1; i >= 0; i-- {
+		err := s.systemModel.RemoveInstance(instances[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// failAndRecover changes the state of each instance (except root) with regard to its MTBF and MTTR. Time to failure
+// and time to repair are exponentially distributed, thus probability of the change within a tick is 1 - exp(-1/MTxx).
+func (s *Simulator) failAndRecover() error {
+	for level := 2; level <= len(s.systemModel.Layers); level++ {
+		for _, inst := range s.systemModel.Layers[level].Instances {
+			mtbf, mttr, err := s.failureParameters(inst)
+			if err != nil {
+				return err
+			}
+			if s.failed[inst] {
+				if s.rnd.Float64() < 1-math.Exp(-1/mttr) {
+					delete(s.failed, inst)
+				}
+			} else if s.rnd.Float64() < 1-math.Exp(-1/mtbf) {
+				s.failed[inst] = true
+			}
+		}
+	}
+	return nil
+}
+
+// failureParameters returns MTBF and MTTR of an instance. If the instance does not define them, they are taken
+// from its Application, or from the defaults.
+func (s *Simulator) failureParameters(inst *systemmodel.Instance) (float64, float64, error) {
+	mtbf, mttr := s.config.DefaultMTBF, s.config.DefaultMTTR
+	app, err := s.systemModel.GetApplication(inst)
+	if err != nil {
+		return 0, 0, err
+	}
+	if v, err := inst.GetMTBF(); err == nil {
+		mtbf = v
+	} else if v, err := app.GetMTBF(); err == nil {
+		mtbf = v
+	}
+	if v, err := inst.GetMTTR(); err == nil {
+		mttr = v
+	} else if v, err := app.GetMTTR(); err == nil {
+		mttr = v
+	}
+	return mtbf, mttr, nil
+}
+
+// applyReliabilities sets reliability of all instances with no relations. Instance, which has failed
+// (or which is deployed by a failed VI), has reliability 0. Number of operational instances with no relations
+// and total number of instances with no relations are returned.
+func (s *Simulator) applyReliabilities() (int, int) {
+	up, leaves := 0, 0
+	var walk func(inst *systemmodel.Instance, failed bool)
+	walk = func(inst *systemmodel.Instance, failed bool) {
+		failed = failed || s.failed[inst]
+		if len(inst.Relations) != 0 {
+			for _, rel := range inst.Relations {
+				walk(rel, failed)
+			}
+			return
+		}
+		leaves++
+		if failed {
+			inst.SetReliability(0)
+			return
+		}
+		up++
+		rel, ok := s.nominal[inst]
+		if !ok {
+			rel = s.config.NominalReliability
+		}
+		inst.SetReliability(rel)
+	}
+	walk(s.systemModel.Layers[1].Instances[0], false)
+	return up, leaves
+}
+
+// RunSimulation simulates evolution of the System Model, stores the time series in the data/ directory
+// and plots it to the figures/ directory
+func RunSimulation(sm *systemmodel.SystemModel, config Config, greyScale bool) ([]Sample, error) {
+	slog.Info("running simulation", "depth", sm.Depth, "ticks", config.Ticks)
+	s, err := NewSimulator(sm, config)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := s.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	// exporting data to JSON
+	fileName := fmt.Sprintf("simulation_fmais_depth_%d_ticks_%d", sm.Depth, config.Ticks)
+	err = storedata.ExportDataToJSON(storedata.DataDir(), fileName, samples, "", " ")
+	if err != nil {
+		return nil, err
+	}
+
+	// plotting a graph for the simulated reliability and availability
+	rels := make(map[int]float64, len(samples))
+	availability := make(map[int]float64, len(samples))
+	for _, sample := range samples {
+		rels[sample.Tick] = sample.Reliability
+		availability[sample.Tick] = sample.Availability
+	}
+	err = draw.PlotSimulation(rels, availability, sm.Depth, config.Ticks, greyScale)
+	if err != nil {
+		return nil, fmt.Errorf("something went wrong during plotting of a simulated reliability: %w", err)
+	}
+
+	return samples, nil
+}
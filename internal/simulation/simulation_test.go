@@ -0,0 +1,130 @@
+package simulation
+
+import (
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/meertcore"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestSimulation(t *testing.T) {
+	sm := systemmodel.CreateSystemModelDepth4()
+	numInstances := sm.GetTotalNumberOfInstances()
+	// letting Applications come and go
+	for _, app := range sm.Applications {
+		app.Probability = 0.5
+	}
+	sm.Applications["App#4"].SetMTBF(10).SetMTTR(10)
+
+	config := DefaultConfig()
+	config.Ticks = 200
+	config.ChurnRate = 0.2
+	s, err := NewSimulator(sm, config)
+	assert.NilError(t, err)
+	samples, err := s.Run()
+	assert.NilError(t, err)
+	assert.Equal(t, len(samples), 200)
+
+	failures, churn := 0, false
+	for _, sample := range samples {
+		assert.Assert(t, sample.Reliability >= 0 && sample.Reliability <= 1)
+		assert.Assert(t, sample.Availability >= 0 && sample.Availability <= 1)
+		failures += sample.Failed
+		if sample.Instances != numInstances {
+			churn = true
+		}
+	}
+	t.Logf("Last sample is %+v", samples[len(samples)-1])
+	assert.Assert(t, failures > 0)
+	assert.Assert(t, churn)
+
+	// the same seed reproduces the same run
+	s, err = NewSimulator(sm, config)
+	assert.NilError(t, err)
+	again, err := s.Run()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, again, samples)
+
+	// original System Model stays intact
+	assert.Equal(t, sm.GetTotalNumberOfInstances(), numInstances)
+}
+
+func TestSimulationNoFailures(t *testing.T) {
+	sm := systemmodel.CreateSystemModelDepth4()
+	me := meertcore.MeErtCore{
+		SystemModel: sm.Clone(),
+	}
+	baseline, err := me.ComputeReliabilityPerDefinition()
+	assert.NilError(t, err)
+
+	config := DefaultConfig()
+	config.Ticks = 10
+	config.ChurnRate = 0
+	config.DefaultMTBF = 1e300
+	s, err := NewSimulator(sm, config)
+	assert.NilError(t, err)
+	samples, err := s.Run()
+	assert.NilError(t, err)
+	for _, sample := range samples {
+		assert.Equal(t, sample.Reliability, baseline)
+		assert.Equal(t, sample.Availability, 1.0)
+		assert.Equal(t, sample.Failed, 0)
+	}
+}
+
+func TestSimulationEverythingFails(t *testing.T) {
+	config := DefaultConfig()
+	config.Ticks = 5
+	config.DefaultMTBF = 1e-9
+	config.DefaultMTTR = 1e300
+	s, err := NewSimulator(systemmodel.CreateSystemModelDepth3(), config)
+	assert.NilError(t, err)
+	samples, err := s.Run()
+	assert.NilError(t, err)
+	for _, sample := range samples {
+		assert.Equal(t, sample.Reliability, 0.0)
+		assert.Equal(t, sample.Availability, 0.0)
+	}
+}
+
+func TestSimulationSameNamedVIs(t *testing.T) {
+	// VIs of a single deployment share their name, but fail on their own
+	sm := systemmodel.CreateSystemModelDepth4()
+	vis, err := sm.DeployApplicationAt("VI#3-4", "VI")
+	assert.NilError(t, err)
+	for _, vi := range vis {
+		vi.SetReliability(0.9)
+	}
+	s, err := NewSimulator(sm, DefaultConfig())
+	assert.NilError(t, err)
+	var failed *systemmodel.Instance
+	for inst, path := range s.SystemModel().InstancePaths() {
+		if path == "MAIS/VI#2-2/VI#3-4/VI#4-2[2]" {
+			failed = inst
+		}
+	}
+	assert.Assert(t, failed != nil)
+	s.failed[failed] = true
+	up, leaves := s.applyReliabilities()
+	t.Logf("%d of %d instances with no relations are operational", up, leaves)
+	assert.Equal(t, up, leaves-1)
+
+	// state of a removed instance is forgotten, its same-named sibling keeps its own
+	assert.NilError(t, s.SystemModel().RemoveInstance("MAIS/VI#2-2/VI#3-4/VI#4-2[2]"))
+	assert.Equal(t, len(s.failed), 0)
+	_, ok := s.nominal[failed]
+	assert.Assert(t, !ok)
+	assert.Equal(t, len(s.nominal), leaves-1)
+}
+
+func TestNewSimulatorErrors(t *testing.T) {
+	config := DefaultConfig()
+	config.Ticks = 0
+	_, err := NewSimulator(systemmodel.CreateSystemModelDepth2(), config)
+	assert.ErrorContains(t, err, "number of ticks should be positive")
+
+	config = DefaultConfig()
+	config.DefaultMTTR = 0
+	_, err = NewSimulator(systemmodel.CreateSystemModelDepth2(), config)
+	assert.ErrorContains(t, err, "should be positive")
+}
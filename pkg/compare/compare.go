@@ -0,0 +1,243 @@
+// Package compare implements a comparison of two benchmark runs. Means of each cell of the parameter grid, which is
+// present in both runs, are compared with Welch's t-test, and the cells are classified as regressions, improvements,
+// or unchanged ones. Classified cells are summarized by depth, number of applications and number of instances.
+package compare
+
+import (
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+)
+
+// Verdict is a classification of a single cell of the parameter grid
+type Verdict string
+
+// verdicts of the compared cells
+const (
+	Regression  Verdict = "regression"  // the new run is significantly slower
+	Improvement Verdict = "improvement" // the new run is significantly faster
+	Unchanged   Verdict = "unchanged"   // the difference is either insignificant, or below the threshold
+)
+
+// Options structure describes when the difference of the means is considered as a regression or an improvement
+type Options struct {
+	Alpha     float64 // significance level of Welch's t-test (e.g., 0.05)
+	Threshold float64 // minimal relative change of the mean (e.g., 0.05 is 5 %), smaller changes are considered as noise
+}
+
+// DefaultOptions returns options of the comparison used, unless they are set explicitly
+func DefaultOptions() Options {
+	return Options{
+		Alpha:     0.05,
+		Threshold: 0.05,
+	}
+}
+
+// Validate checks that the options are within their ranges
+func (o Options) Validate() error {
+	if o.Alpha <= 0 || o.Alpha >= 1 {
+		return fmt.Errorf("significance level should be in range (0, 1), got %v", o.Alpha)
+	}
+	if o.Threshold < 0 {
+		return fmt.Errorf("threshold of the relative change should be non-negative, got %v", o.Threshold)
+	}
+	return nil
+}
+
+// CellDiff structure holds a comparison of a single cell of the parameter grid
+type CellDiff struct {
+	Depth     int
+	Apps      int
+	Instances int
+	Old       storedata.Stats
+	New       storedata.Stats
+	Ratio     float64 // ratio of the new mean to the old mean, i.e., a value greater than 1 means slowdown
+	Tested    bool    // indicates that the significance was tested, it can't be tested for bare data cubes (means only)
+	PValue    float64 // two-sided p-value of Welch's t-test, it is NaN, unless tested
+	Verdict   Verdict
+}
+
+// Summary structure counts cells by their verdict
+type Summary struct {
+	Cells        int
+	Regressions  int
+	Improvements int
+	Unchanged    int
+	GeoMeanRatio float64 // geometric mean of the ratios of all cells
+	logRatios    float64
+}
+
+// add adds a cell to the summary
+func (s Summary) add(d CellDiff) Summary {
+	s.Cells++
+	switch d.Verdict {
+	case Regression:
+		s.Regressions++
+	case Improvement:
+		s.Improvements++
+	default:
+		s.Unchanged++
+	}
+	s.logRatios += math.Log(d.Ratio)
+	s.GeoMeanRatio = math.Exp(s.logRatios / float64(s.Cells))
+	return s
+}
+
+// Comparison structure holds a comparison of two benchmark runs
+type Comparison struct {
+	Options     Options
+	Cells       []CellDiff // compared cells in the order of the grid
+	Total       Summary
+	ByDepth     map[int]Summary
+	ByApps      map[int]Summary
+	ByInstances map[int]Summary
+}
+
+// Compare compares all cells, which are present in both result documents
+func Compare(old, new *storedata.ResultDocument, opts Options) (*Comparison, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if old.Unit != "" && new.Unit != "" && old.Unit != new.Unit {
+		return nil, fmt.Errorf("can't compare results measured in different units (%s and %s)", old.Unit, new.Unit)
+	}
+	oldCells := make(map[[3]int]storedata.Stats, len(old.Cells))
+	for _, c := range old.Cells {
+		oldCells[[3]int{c.Depth, c.Apps, c.Instances}] = c.Stats
+	}
+
+	c := &Comparison{
+		Options:     opts,
+		Cells:       make([]CellDiff, 0),
+		ByDepth:     make(map[int]Summary, 0),
+		ByApps:      make(map[int]Summary, 0),
+		ByInstances: make(map[int]Summary, 0),
+	}
+	for _, cell := range new.Cells {
+		o, ok := oldCells[[3]int{cell.Depth, cell.Apps, cell.Instances}]
+		if !ok {
+			continue
+		}
+		if o.Mean <= 0 || cell.Mean <= 0 {
+			return nil, fmt.Errorf("can't compare non-positive means of depth %d, %d apps and %d instances",
+				cell.Depth, cell.Apps, cell.Instances)
+		}
+		d := compareCell(o, cell.Stats, opts)
+		d.Depth, d.Apps, d.Instances = cell.Depth, cell.Apps, cell.Instances
+		c.Cells = append(c.Cells, d)
+		c.Total = c.Total.add(d)
+		c.ByDepth[d.Depth] = c.ByDepth[d.Depth].add(d)
+		c.ByApps[d.Apps] = c.ByApps[d.Apps].add(d)
+		c.ByInstances[d.Instances] = c.ByInstances[d.Instances].add(d)
+	}
+	if len(c.Cells) == 0 {
+		return nil, fmt.Errorf("compared results don't have any cell of the parameter grid in common")
+	}
+	return c, nil
+}
+
+// compareCell compares statistics of a single cell
+func compareCell(old, new storedata.Stats, opts Options) CellDiff {
+	d := CellDiff{
+		Old:     old,
+		New:     new,
+		Ratio:   new.Mean / old.Mean,
+		PValue:  math.NaN(),
+		Verdict: Unchanged,
+	}
+	_, _, p, err := storedata.WelchTTest(old, new)
+	if err == nil {
+		d.Tested = true
+		d.PValue = p
+	}
+	if math.Abs(d.Ratio-1) < opts.Threshold || (d.Tested && d.PValue >= opts.Alpha) {
+		return d
+	}
+	if d.Ratio > 1 {
+		d.Verdict = Regression
+	} else {
+		d.Verdict = Improvement
+	}
+	return d
+}
+
+// Regressions returns the cells, which were classified as regressions
+func (c *Comparison) Regressions() []CellDiff {
+	regressions := make([]CellDiff, 0)
+	for _, d := range c.Cells {
+		if d.Verdict == Regression {
+			regressions = append(regressions, d)
+		}
+	}
+	return regressions
+}
+
+// Ratios returns the ratios of all compared cells as a data cube, i.e., map[depth]map[apps]map[instances]ratio
+func (c *Comparison) Ratios() map[int]map[int]map[int]float64 {
+	data := make(map[int]map[int]map[int]float64, 0)
+	for _, d := range c.Cells {
+		if _, ok := data[d.Depth]; !ok {
+			data[d.Depth] = make(map[int]map[int]float64, 0)
+		}
+		if _, ok := data[d.Depth][d.Apps]; !ok {
+			data[d.Depth][d.Apps] = make(map[int]float64, 0)
+		}
+		data[d.Depth][d.Apps][d.Instances] = d.Ratio
+	}
+	return data
+}
+
+// WriteTable writes a text table of all compared cells followed by the summaries
+func (c *Comparison) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Depth\tApps\tInstances\tOld mean\tNew mean\tRatio\tp-value\tVerdict\t")
+	for _, d := range c.Cells {
+		p := "n/a"
+		if d.Tested {
+			p = fmt.Sprintf("%.4f", d.PValue)
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%.3f\t%.3f\t%.3f\t%s\t%s\t\n", d.Depth, d.Apps, d.Instances, d.Old.Mean, d.New.Mean,
+			d.Ratio, p, d.Verdict)
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	for _, group := range []struct {
+		name      string
+		summaries map[int]Summary
+	}{
+		{"Depth", c.ByDepth},
+		{"Apps", c.ByApps},
+		{"Instances", c.ByInstances},
+	} {
+		fmt.Fprintln(w)
+		writeSummaries(tw, group.name, group.summaries)
+		err = tw.Flush()
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(w, "\nTotal: %d cells, %d regressions, %d improvements, %d unchanged, geometric mean of ratios %.3f "+
+		"(alpha %v, threshold %v)\n", c.Total.Cells, c.Total.Regressions, c.Total.Improvements, c.Total.Unchanged,
+		c.Total.GeoMeanRatio, c.Options.Alpha, c.Options.Threshold)
+	return nil
+}
+
+// writeSummaries writes summaries of a single parameter ordered by its value
+func writeSummaries(w io.Writer, name string, summaries map[int]Summary) {
+	keys := make([]int, 0, len(summaries))
+	for k := range summaries {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	fmt.Fprintf(w, "%s\tCells\tRegressions\tImprovements\tUnchanged\tGeo. mean ratio\t\n", name)
+	for _, k := range keys {
+		s := summaries[k]
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%.3f\t\n", k, s.Cells, s.Regressions, s.Improvements, s.Unchanged, s.GeoMeanRatio)
+	}
+}
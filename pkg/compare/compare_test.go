@@ -0,0 +1,80 @@
+package compare
+
+import (
+	"bytes"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"gotest.tools/assert"
+	"math"
+	"strings"
+	"testing"
+)
+
+// samples returns samples with a given mean and a small spread
+func samples(mean float64) []float64 {
+	return []float64{mean - 2, mean - 1, mean, mean, mean + 1, mean + 2}
+}
+
+func TestCompare(t *testing.T) {
+	old := storedata.NewResultDocument("FMAIS generation time", "us", 6, false).
+		AddCell(1, 1, 1, storedata.ComputeStats(samples(100))).
+		AddCell(1, 6, 1, storedata.ComputeStats(samples(100))).
+		AddCell(2, 1, 1, storedata.ComputeStats(samples(100))).
+		AddCell(2, 6, 1, storedata.ComputeStats(samples(100))).
+		AddCell(3, 1, 1, storedata.ComputeStats(samples(100)))
+	new := storedata.NewResultDocument("FMAIS generation time", "us", 6, false).
+		AddCell(1, 1, 1, storedata.ComputeStats(samples(150))). // significant slowdown
+		AddCell(1, 6, 1, storedata.ComputeStats(samples(50))).  // significant speedup
+		AddCell(2, 1, 1, storedata.ComputeStats(samples(102))). // below the threshold
+		AddCell(2, 6, 1, storedata.ComputeStats([]float64{10, 250, 10, 250, 10, 250}))
+	c, err := Compare(old, new, DefaultOptions())
+	assert.NilError(t, err)
+	// the cell of depth 3 is not in the new run
+	assert.Equal(t, len(c.Cells), 4)
+	assert.Equal(t, c.Cells[0].Verdict, Regression)
+	assert.Equal(t, c.Cells[0].Ratio, 1.5)
+	assert.Equal(t, c.Cells[1].Verdict, Improvement)
+	assert.Equal(t, c.Cells[2].Verdict, Unchanged)
+	// the change is large, but not significant
+	assert.Equal(t, c.Cells[3].Verdict, Unchanged)
+	assert.Assert(t, c.Cells[3].PValue > 0.05)
+
+	assert.Equal(t, c.Total.Regressions, 1)
+	assert.Equal(t, c.Total.Improvements, 1)
+	assert.Equal(t, c.Total.Unchanged, 2)
+	assert.Equal(t, c.ByDepth[1].Cells, 2)
+	assert.Assert(t, math.Abs(c.ByDepth[1].GeoMeanRatio-math.Sqrt(0.75)) < 1e-9)
+	assert.Equal(t, c.ByApps[6].Improvements, 1)
+	assert.Equal(t, c.ByInstances[1].Cells, 4)
+	assert.Equal(t, len(c.Regressions()), 1)
+	assert.Equal(t, c.Ratios()[1][6][1], 0.5)
+
+	var buf bytes.Buffer
+	assert.NilError(t, c.WriteTable(&buf))
+	t.Logf("Comparison table:\n%s", buf.String())
+	assert.Assert(t, strings.Contains(buf.String(), "regression"))
+	assert.Assert(t, strings.Contains(buf.String(), "Total: 4 cells, 1 regressions, 1 improvements, 2 unchanged"))
+}
+
+func TestCompareMeansOnly(t *testing.T) {
+	// bare data cubes hold means only, so the significance is not tested
+	old, err := storedata.ImportResult("../../data/", "benchmark_fmais_docker_2023-04-09_02-24-28.json")
+	assert.NilError(t, err)
+	new, err := storedata.ImportResult("../../data/", "benchmark_fmais_docker_2023-04-24_03-53-55.json")
+	assert.NilError(t, err)
+	c, err := Compare(old, new, Options{Alpha: 0.05, Threshold: 0.1})
+	assert.NilError(t, err)
+	assert.Assert(t, !c.Cells[0].Tested)
+	assert.Assert(t, math.IsNaN(c.Cells[0].PValue))
+	t.Logf("Comparison of the Docker runs: %+v", c.Total)
+
+	_, err = Compare(old, new, Options{Alpha: 0, Threshold: 0.1})
+	assert.ErrorContains(t, err, "significance level")
+	// units are not known for bare data cubes
+	timed := storedata.NewResultDocument("FMAIS generation time", "us", 1, false).AddCell(1, 1, 1, storedata.Stats{Mean: 1})
+	other := storedata.NewResultDocument("Reliability", "-", 1, false).AddCell(1, 1, 1, storedata.Stats{Mean: 1})
+	_, err = Compare(timed, other, DefaultOptions())
+	assert.ErrorContains(t, err, "different units")
+	other = storedata.NewResultDocument("FMAIS generation time", "us", 1, false).AddCell(9, 1, 1, storedata.Stats{Mean: 1})
+	_, err = Compare(old, other, DefaultOptions())
+	assert.ErrorContains(t, err, "in common")
+}
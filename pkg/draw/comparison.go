@@ -0,0 +1,39 @@
+// Package draw implements a set of helper functions to draw SystemModel. This file in particular implements plotting
+// of the comparison of two benchmark runs, i.e., ratios of the new to the old measured times.
+package draw
+
+import (
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/sweep"
+	"strings"
+)
+
+// PlotComparison plots ratios of the new to the old measured times (values above 1 mean slowdown) of the compared
+// cells. Dependency on the number of applications (with the greatest number of instances per application) and on
+// the number of instances per application (with the greatest number of applications) is plotted for representative
+// depths of the FMAIS.
+func PlotComparison(ratios map[int]map[int]map[int]float64, spec sweep.Spec, prefix string, greyScale bool) error {
+	s := spec.Intersect(sweep.FromData(ratios))
+	if err := s.Validate(); err != nil {
+		return fmt.Errorf("compared data don't cover the parameter grid: %w", err)
+	}
+	// lines are extracted in the same way as for the time complexity, i.e., values are divided by 1000
+	ratios = scaleData(ratios, 1000)
+	fileName := strings.ToLower(prefix) + "_time-ratio"
+	figureName := fmt.Sprintf("%s\nRatio of the new to the old time on ", prefix)
+
+	d := Draw{}
+	d.InitializeDrawStruct()
+	d.SetOutputFileName(fmt.Sprintf("%s-apps-number-%d-inst", fileName, s.Instances.Max())).
+		SetFigureName(figureName + "the App number").SetYaxisName("Ratio new/old [-]").SetXaxisName("Number of Applications [-]")
+	lines := getLinesForAppNumber(ratios, s.Depths.Pick(3), s.Apps, []int{s.Instances.Max()})
+	err := d.plotTimeComplexity(lines, greyScale, false, false, false, false)
+	if err != nil {
+		return err
+	}
+
+	d.SetOutputFileName(fmt.Sprintf("%s-instances-per-app-%d-apps", fileName, s.Apps.Max())).
+		SetFigureName(figureName + "instances per App").SetXaxisName("Instances (per App) [-]")
+	lines = getLinesForInstances(ratios, s.Depths.Pick(3), []int{s.Apps.Max()}, s.Instances)
+	return d.plotTimeComplexity(lines, greyScale, false, false, false, false)
+}
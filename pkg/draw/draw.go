@@ -18,18 +18,21 @@ const linewidth = refScale * unitLines
 
 // Draw structure holds all necessary information for plotting a figure of SystemModel
 type Draw struct {
-	Rendered       bool      // indicates whether a figure was rendered or not
-	OutputFileName string    // an output file name, where the figure would be saved
-	FigureName     string    // carries a figure name
-	XaxisName      string    // carries X-axis name
-	YaxisName      string    // carries Y-axis name
-	xmin           *float64  // optional: sets a minimum boundary for X-axis
-	xmax           *float64  // optional: sets a maximum boundary for X-axis
-	ymin           *float64  // optional: sets a minimum boundary for Y-axis
-	ymax           *float64  // optional: sets a maximum boundary for Y-axis
-	gridOn         bool      // enable grid on the figure
-	XLength        vg.Length // sets length of an X-axis (in Inches, Cm or mm)
-	YLength        vg.Length // sets length of an Y-axis (in Inches, Cm or mm)
+	Rendered       bool         // indicates whether a figure was rendered or not
+	OutputFileName string       // an output file name, where the figure would be saved
+	FigureName     string       // carries a figure name
+	XaxisName      string       // carries X-axis name
+	YaxisName      string       // carries Y-axis name
+	xmin           *float64     // optional: sets a minimum boundary for X-axis
+	xmax           *float64     // optional: sets a maximum boundary for X-axis
+	ymin           *float64     // optional: sets a minimum boundary for Y-axis
+	ymax           *float64     // optional: sets a maximum boundary for Y-axis
+	gridOn         bool         // enable grid on the figure
+	XLength        vg.Length    // sets length of an X-axis (in Inches, Cm or mm)
+	YLength        vg.Length    // sets length of an Y-axis (in Inches, Cm or mm)
+	layout         Layout       // placement of the nodes in the figure of SystemModel (tidy tree by default)
+	target         OutputTarget // directory, formats and resolution of the rendered figure
+	heatMap        bool         // colour the nodes of SystemModel by their reliability and set edge width by priority
 }
 
 // InitializeDrawStruct initializes a Draw structure
@@ -37,7 +40,7 @@ func (d *Draw) InitializeDrawStruct() *Draw {
 	d.Rendered = false
 	d.gridOn = false // enabling grid by default
 	d.SetOutputFileName("Default").SetFigureName("").SetXaxisName("").
-		SetYaxisName("").SetXLength(20 * vg.Inch).SetYLength(20 * vg.Inch)
+		SetYaxisName("").SetXLength(20 * vg.Inch).SetYLength(20 * vg.Inch).SetOutputTarget(defaultTarget)
 	return d
 }
 
@@ -101,11 +104,25 @@ func (d *Draw) SetYLength(yl vg.Length) *Draw {
 	return d
 }
 
+// SetLayout sets a layout used to place the nodes in the figure of SystemModel
+func (d *Draw) SetLayout(layout Layout) *Draw {
+	d.layout = layout
+	return d
+}
+
+// SetHeatMap enables rendering of SystemModel as a reliability heat map. Reliabilities (and priorities) should be
+// already set, e.g., by ME-ERT-CORE.
+func (d *Draw) SetHeatMap(heatMap bool) *Draw {
+	d.heatMap = heatMap
+	return d
+}
+
 // Coordinates is a structure that carries all information about the nodes and their coordinates in
 // the systemmodel.SystemModel structure
 type Coordinates struct {
-	Points map[string]*Coordinate // this map contains as a key Name of the node and it's coordinates..
+	Points map[string]*Coordinate // this map contains as a key path of the node (see SystemModel.InstancePaths) and it's coordinates..
 	Labels plotter.XYLabels       // this is to hold a name of the instance and plot it on the graph (per node)
+	Extent float64                // width of the layout measured in distances between neighbouring nodes
 }
 
 // Coordinate structure is a hybrid structure between systemmodel.SystemModel and the custom plotter.XYer structure.
@@ -119,7 +136,9 @@ type Coordinate struct {
 // coordinates of each node
 func (ds *Coordinates) ConvertSystemModelToDrawStruct(sm *systemmodel.SystemModel) {
 	ds.Points = make(map[string]*Coordinate, sm.GetTotalNumberOfInstances())
+	paths := sm.InstancePaths()
 	//ds.Labels = make(plotter.XYLabels, 0)
+	ds.Extent = float64(sm.GetTheGreatestNumberOfInstancesPerLayer())
 	for i := 1; i <= len(sm.Layers); i++ {
 		layer := sm.Layers[i]
 		j := 1
@@ -134,7 +153,7 @@ func (ds *Coordinates) ConvertSystemModelToDrawStruct(sm *systemmodel.SystemMode
 			dp := &Coordinate{
 				Coordinates: data,
 			}
-			ds.Points[v.Name] = dp
+			ds.Points[paths[v]] = dp
 			// adding labels to figure
 			ds.Labels.XYs = append(ds.Labels.XYs, data[0])
 			ds.Labels.Labels = append(ds.Labels.Labels, v.Name)
@@ -242,7 +261,7 @@ func AddScattersSquare(plt *plot.Plot, vs ...interface{}) error {
 			items = append(items, item1{name: "App", value: app})
 
 		default:
-			panic(fmt.Sprintf("plotutil: AddScattersSquare accepts only map[string]*Coordinate type, got %T", t))
+			return fmt.Errorf("AddScattersSquare accepts only map[string]*Coordinate type, got %T", t)
 		}
 	}
 	plt.Add(ps...)
@@ -315,7 +334,7 @@ func AddScattersAndLines(plt *plot.Plot, greyScale bool, vs ...interface{}) erro
 			}
 
 		default:
-			panic(fmt.Sprintf("plotutil: AddScattersAndLines accepts only map[string]*plotter.XYs type, got %T", t))
+			return fmt.Errorf("AddScattersAndLines accepts only map[string]plotter.XYs type, got %T", t)
 		}
 	}
 	plt.Add(ps...)
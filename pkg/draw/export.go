@@ -0,0 +1,208 @@
+// Package draw implements a set of helper functions to draw SystemModel. This file in particular implements export
+// of the SystemModel to the graph description languages (Graphviz DOT and Mermaid), which can be viewed
+// with a standard tooling.
+package draw
+
+import (
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// colours of the nodes in the exported graph
+const (
+	rootColor = "#f4a261" // root instance, MAIS
+	viColor   = "#8ecae6" // VIs
+	appColor  = "#b7e4c7" // Application instances
+)
+
+// exportNode structure holds a node of the exported graph
+type exportNode struct {
+	id     string                  // unique identifier of the node
+	label  []string                // lines of the node label
+	kind   string                  // kind of the node - root, vi or app
+	merged []*systemmodel.Instance // instances represented by a collapsed node
+}
+
+// exportGraph structure holds the SystemModel converted to a graph, which is ready for the export
+type exportGraph struct {
+	nodes []*exportNode
+	edges [][2]string // pairs of node IDs (from, to)
+}
+
+// ExportDOT writes the SystemModel to the writer in the Graphviz DOT format. Nodes are coloured by their type and
+// labelled with their priority and reliability (if set). If collapseApps is true, all instances of an Application
+// deployed by the same VI are drawn as a single node.
+func ExportDOT(sm *systemmodel.SystemModel, w io.Writer, collapseApps bool) error {
+	g := convertSystemModelToGraph(sm, collapseApps)
+
+	var sb strings.Builder
+	sb.WriteString("digraph FMAIS {\n")
+	sb.WriteString("\trankdir=TB;\n")
+	sb.WriteString("\tnode [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	for _, n := range g.nodes {
+		fmt.Fprintf(&sb, "\t%s [label=%s, fillcolor=\"%s\"];\n",
+			strconv.Quote(n.id), strconv.Quote(strings.Join(n.label, "\n")), n.color())
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(&sb, "\t%s -> %s;\n", strconv.Quote(e[0]), strconv.Quote(e[1]))
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// ExportMermaid writes the SystemModel to the writer as a Mermaid flowchart. Nodes are coloured by their type and
+// labelled with their priority and reliability (if set). If collapseApps is true, all instances of an Application
+// deployed by the same VI are drawn as a single node.
+func ExportMermaid(sm *systemmodel.SystemModel, w io.Writer, collapseApps bool) error {
+	g := convertSystemModelToGraph(sm, collapseApps)
+
+	// Mermaid allows only a limited set of characters in node IDs
+	ids := make(map[string]string, len(g.nodes))
+	for idx, n := range g.nodes {
+		ids[n.id] = "n" + strconv.Itoa(idx)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	for _, n := range g.nodes {
+		// '#' starts an entity code in Mermaid, thus it is escaped (together with quotes)
+		label := strings.NewReplacer("#", "#35;", "\"", "#quot;").Replace(strings.Join(n.label, "<br/>"))
+		fmt.Fprintf(&sb, "\t%s[\"%s\"]:::%s\n", ids[n.id], label, n.kind)
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(&sb, "\t%s --> %s\n", ids[e[0]], ids[e[1]])
+	}
+	fmt.Fprintf(&sb, "\tclassDef root fill:%s\n", rootColor)
+	fmt.Fprintf(&sb, "\tclassDef vi fill:%s\n", viColor)
+	fmt.Fprintf(&sb, "\tclassDef app fill:%s\n", appColor)
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// color returns a fill colour of the node with regard to its kind
+func (n *exportNode) color() string {
+	switch n.kind {
+	case "root":
+		return rootColor
+	case "vi":
+		return viColor
+	default:
+		return appColor
+	}
+}
+
+// convertSystemModelToGraph converts SystemModel to the graph. Layers and instances are traversed in order,
+// thus the output is stable for the same SystemModel. Nodes are identified by the instance paths, since the VIs
+// deployed at once share the same name, the name is kept in the label.
+func convertSystemModelToGraph(sm *systemmodel.SystemModel, collapseApps bool) *exportGraph {
+	g := &exportGraph{
+		nodes: make([]*exportNode, 0, sm.GetTotalNumberOfInstances()),
+		edges: make([][2]string, 0, sm.GetTotalNumberOfInstances()),
+	}
+	// collapsed nodes are identified by the parent and the Application name
+	collapsed := make(map[string]*exportNode, 0)
+	paths := sm.InstancePaths()
+
+	for i := 1; i <= len(sm.Layers); i++ {
+		for _, inst := range sm.Layers[i].Instances {
+			if inst.IsApp() && collapseApps {
+				// collapsed Application instances are added, when their parent is processed
+				continue
+			}
+			g.nodes = append(g.nodes, &exportNode{
+				id:    paths[inst],
+				label: instanceLabel(inst),
+				kind:  instanceKind(inst),
+			})
+
+			for _, rel := range inst.Relations {
+				if !rel.IsApp() || !collapseApps {
+					g.edges = append(g.edges, [2]string{paths[inst], paths[rel]})
+					continue
+				}
+				appName, err := rel.GetAppName()
+				if err != nil {
+					appName = rel.Name
+				}
+				id := paths[inst] + "/" + appName
+				n, ok := collapsed[id]
+				if !ok {
+					n = &exportNode{
+						id:     id,
+						kind:   "app",
+						merged: make([]*systemmodel.Instance, 0),
+					}
+					collapsed[id] = n
+					g.nodes = append(g.nodes, n)
+					g.edges = append(g.edges, [2]string{paths[inst], id})
+				}
+				n.merged = append(n.merged, rel)
+			}
+		}
+	}
+	for _, n := range collapsed {
+		n.label = collapsedLabel(n.merged)
+	}
+
+	return g
+}
+
+// instanceLabel composes a label of the instance from its name, priority and reliability
+func instanceLabel(inst *systemmodel.Instance) []string {
+	label := []string{inst.Name}
+	if p, err := inst.GetPriority(); err == nil {
+		label = append(label, "P: "+strconv.FormatFloat(p, 'g', 4, 64))
+	}
+	if r, err := inst.GetReliability(); err == nil {
+		label = append(label, "R: "+strconv.FormatFloat(r, 'g', 4, 64))
+	}
+	return label
+}
+
+// collapsedLabel composes a label of the node, which represents all instances of an Application deployed
+// by the same VI. Priority is a sum of the instance priorities and reliability is their weighted sum
+// (i.e., a contribution of the Application), if all of them are set.
+func collapsedLabel(instances []*systemmodel.Instance) []string {
+	appName, err := instances[0].GetAppName()
+	if err != nil {
+		appName = instances[0].Name
+	}
+	label := []string{fmt.Sprintf("%s (%d instances)", appName, len(instances))}
+
+	var priority, reliability float64
+	for _, inst := range instances {
+		p, err := inst.GetPriority()
+		if err != nil {
+			return label
+		}
+		priority += p
+	}
+	label = append(label, "P: "+strconv.FormatFloat(priority, 'g', 4, 64))
+	for _, inst := range instances {
+		p, _ := inst.GetPriority()
+		r, err := inst.GetReliability()
+		if err != nil {
+			return label
+		}
+		reliability += r * p
+	}
+	label = append(label, "R: "+strconv.FormatFloat(reliability, 'g', 4, 64))
+	return label
+}
+
+// instanceKind returns a kind of the instance - root, vi or app
+func instanceKind(inst *systemmodel.Instance) string {
+	if inst.IsApp() {
+		return "app"
+	}
+	if strings.HasPrefix(inst.Name, "MAIS") {
+		return "root"
+	}
+	return "vi"
+}
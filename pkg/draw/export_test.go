@@ -0,0 +1,96 @@
+package draw
+
+import (
+	"bytes"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"gotest.tools/assert"
+	"strings"
+	"testing"
+)
+
+func TestExportDOT(t *testing.T) {
+	sm := systemmodel.CreateSystemModelDepth3()
+
+	var buf bytes.Buffer
+	err := ExportDOT(sm, &buf, false)
+	assert.NilError(t, err)
+	out := buf.String()
+	t.Logf("Exported graph is\n%s", out)
+
+	assert.Assert(t, strings.HasPrefix(out, "digraph FMAIS {"))
+	assert.Equal(t, strings.Count(out, "->"), int(sm.GetTotalNumberOfInstances())-1)
+	assert.Assert(t, strings.Contains(out, `"MAIS" -> "MAIS/VI#2-1";`))
+	assert.Assert(t, strings.Contains(out, `"MAIS/App#2-1-1" [label="App#2-1-1\nP: 0.41\nR: 0.77", fillcolor="`+appColor+`"];`))
+	assert.Assert(t, strings.Contains(out, `"MAIS" [label="MAIS", fillcolor="`+rootColor+`"];`))
+
+	// the output is stable
+	var again bytes.Buffer
+	err = ExportDOT(sm, &again, false)
+	assert.NilError(t, err)
+	assert.Equal(t, again.String(), out)
+}
+
+func TestExportDOTCollapsed(t *testing.T) {
+	sm := systemmodel.CreateSystemModelDepth3()
+
+	var buf bytes.Buffer
+	err := ExportDOT(sm, &buf, true)
+	assert.NilError(t, err)
+	out := buf.String()
+	t.Logf("Exported graph is\n%s", out)
+
+	// three instances of App#1 are collapsed into a single node
+	assert.Assert(t, !strings.Contains(out, "App#2-1-1"))
+	assert.Assert(t, strings.Contains(out, `"MAIS" -> "MAIS/App#1";`))
+	assert.Assert(t, strings.Contains(out, `App#1 (3 instances)\nP: 1\nR: `))
+}
+
+func TestExportMermaid(t *testing.T) {
+	sm := systemmodel.CreateSystemModelDepth3()
+
+	var buf bytes.Buffer
+	err := ExportMermaid(sm, &buf, false)
+	assert.NilError(t, err)
+	out := buf.String()
+	t.Logf("Exported graph is\n%s", out)
+
+	assert.Assert(t, strings.HasPrefix(out, "flowchart TD\n"))
+	assert.Equal(t, strings.Count(out, "-->"), int(sm.GetTotalNumberOfInstances())-1)
+	assert.Assert(t, strings.Contains(out, "\tn0[\"MAIS\"]:::root\n"))
+	assert.Assert(t, strings.Contains(out, "\tn1[\"VI#35;2-1<br/>P: 0.25\"]:::vi\n"))
+	assert.Assert(t, strings.Contains(out, "\tn0 --> n1\n"))
+	assert.Assert(t, strings.Contains(out, "classDef vi fill:"+viColor))
+}
+
+func TestExportGeneratedSystemModel(t *testing.T) {
+	sm := &systemmodel.SystemModel{}
+	sm.InitializeSystemModel(10, 4)
+	sm.CreateRandomApplications(systemmodel.GenerateAppNames(10), 1, 10)
+	_, err := sm.GenerateSystemModel()
+	assert.NilError(t, err)
+	total := int(sm.GetTotalNumberOfInstances())
+
+	// VIs deployed at once share the same name, each of them is still a node of its own
+	var dot bytes.Buffer
+	err = ExportDOT(sm, &dot, false)
+	assert.NilError(t, err)
+	assert.Equal(t, len(declaredNodes(dot.String(), " [label=")), total)
+	assert.Equal(t, strings.Count(dot.String(), "->"), total-1)
+
+	var mermaid bytes.Buffer
+	err = ExportMermaid(sm, &mermaid, false)
+	assert.NilError(t, err)
+	assert.Equal(t, len(declaredNodes(mermaid.String(), "[\"")), total)
+	assert.Equal(t, strings.Count(mermaid.String(), "-->"), total-1)
+}
+
+// declaredNodes returns a set of node IDs declared in the exported graph, ID precedes the separator on the line
+func declaredNodes(out, separator string) map[string]bool {
+	nodes := make(map[string]bool)
+	for _, line := range strings.Split(out, "\n") {
+		if idx := strings.Index(line, separator); idx != -1 {
+			nodes[strings.TrimSpace(line[:idx])] = true
+		}
+	}
+	return nodes
+}
@@ -4,11 +4,15 @@ package draw
 import (
 	"fmt"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/sweep"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/plotutil"
 	"gonum.org/v1/plot/vg"
+	"image/color"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -27,12 +31,13 @@ const fontsizeLegend = refScale * unitLabels / 2
 //	To avoid duplication of the nodes, create a custom structure, which carries coordinates (X, Y) information, name of the node (which is unique)
 //	and the status (were coordinates (X, Y) already created?).
 func (d *Draw) DrawSystemModel(sm *systemmodel.SystemModel) error {
-	// Adjusting length of an x and Y axis
-	maxItemNumber := sm.GetTheGreatestNumberOfInstancesPerLayer()
-	d.XLength = 0.25 * vg.Centimeter * (4 / 3) * vg.Length(maxItemNumber)
-	if d.XLength < 20*vg.Inch {
-		d.XLength = 20 * vg.Inch
+	// converting SystemModel to plotter-friendly structure
+	ds := Coordinates{}
+	ds.ConvertSystemModelToDrawStructWithLayout(sm, d.layout)
+	if d.heatMap {
+		return d.drawReliabilityHeatMap(sm, &ds)
 	}
+	d.adjustFigureSize(&ds)
 
 	// creating new figure
 	p := plot.New()
@@ -47,22 +52,19 @@ func (d *Draw) DrawSystemModel(sm *systemmodel.SystemModel) error {
 		p.Add(plotter.NewGrid())
 	}
 
-	// converting SystemModel to plotter-friendly structure
-	ds := Coordinates{}
-	ds.ConvertSystemModelToDrawStruct(sm)
-
 	// adding lines between nodes
+	paths := sm.InstancePaths()
 	for i := 1; i <= len(sm.Layers); i++ {
 		layer := sm.Layers[i]
 		for _, v := range layer.Instances {
 			// creating a placeholder for a line
 			line := make(plotter.XYs, 2)
 			// extracting coordinates of the originate node
-			line[0].X, line[0].Y = ds.Points[v.Name].Coordinates.XY(0)
+			line[0].X, line[0].Y = ds.Points[paths[v]].Coordinates.XY(0)
 			// iterating over relations
 			for _, val := range v.Relations {
 				// extracting coordinate of the child node
-				line[1].X, line[1].Y = ds.Points[val.Name].Coordinates.XY(0)
+				line[1].X, line[1].Y = ds.Points[paths[val]].Coordinates.XY(0)
 				// adding a line to the graph
 				err := plotutil.AddLines(p, line)
 				if err != nil {
@@ -84,124 +86,86 @@ func (d *Draw) DrawSystemModel(sm *systemmodel.SystemModel) error {
 	if err != nil {
 		return err
 	}
-	// Save the plot to a PNG file
-	// ToDo - implement a relative path to enable execution out of everywhere in the system..
-	if err := p.Save(d.XLength, d.YLength, "figures/"+d.OutputFileName+".png"); err != nil {
+	// Save the plot to a PNG file (by default)
+	if err := d.save(p, "png"); err != nil {
 		return err
 	}
 	d.Rendered = true
 	return nil
 }
 
-// PlotTimeComplexities plots all measured data (i.e., produces various figures)
-func PlotTimeComplexities(tc map[int]map[int]map[int]float64, maxDepth int, maxAppNumber int, maxNumInstancesPerApp int, prefix string, greyScale, meertcore bool) error {
+// adjustFigureSize adjusts length of an X and Y axis, so the nodes of the SystemModel fit in the figure
+func (d *Draw) adjustFigureSize(ds *Coordinates) {
+	d.XLength = 0.25 * vg.Centimeter * (4 / 3) * vg.Length(ds.Extent)
+	if d.layout == LayoutRadial {
+		// nodes of the last layer are spread over the circumference
+		d.XLength /= math.Pi
+	}
+	if d.XLength < 20*vg.Inch {
+		d.XLength = 20 * vg.Inch
+	}
+	if d.layout == LayoutRadial {
+		d.YLength = d.XLength
+	}
+}
+
+// PlotTimeComplexities plots all measured data (i.e., produces various figures). Slices of the data are selected by
+// the parameter grid of the benchmark (see sweep.Slices), only values present in the data are taken into account.
+func PlotTimeComplexities(tc map[int]map[int]map[int]float64, spec sweep.Spec, prefix string, greyScale, meertcore bool) error {
 	// Firstly, convert the data into simple (X,Y) thing.
 	// We want to plot and showcase following dependencies:
 	// 1) Time complexity of the System Model based on its depth
-	//		- Take into account system with 26, 56 and 96 apps
-	//  	- Also, take into account 26, 56 and 96 instances per application
-	//  	- This figure will have 9 curves/graphs
+	//		- Take into account system with 26, 51, 76 and 96 apps (by default)
+	//  	- Produce a figure for 26, 56 and 96 instances per application (by default)
 	// 2) Time complexity of the System Model based on the number of applications
-	//  	- Fix the level of System Model to 2, 3 and 4
-	//  	- Fix the number of instances per application to 26, 56 and 96
-	//		- This will produce 9 curves
+	//  	- Fix the level of System Model to 2, 3 and 4 (by default)
+	//  	- Produce a figure for 26, 56 and 96 instances per application (by default)
 	// 3) Time complexity of the System Model based on the number of instances deployed per application
-	//		- Fix the level of System Model to 2, 3 and 4
-	//		- Take into account system with 26, 51, 76, and 96 apps
-	//		- This will produce 12 curves as well
+	//		- Fix the level of System Model to 2, 3 and 4 (by default)
+	//		- Produce a figure for the lowest and 26, 56 and 96 apps (by default)
+	spec = spec.Intersect(sweep.FromData(tc))
+	if err := spec.Validate(); err != nil {
+		return fmt.Errorf("benchmarked data don't cover the parameter grid: %w", err)
+	}
+	depths := spec.Depths.Slice(spec.Slices.Depths, 3)
+	apps := spec.Apps.Slice(spec.Slices.CurveApps, 4)
+	instances := spec.Instances.Slice(spec.Slices.Instances, 3)
 
 	figureName := prefix + " Time Complexity\nDependency "
 
 	// plotting time complexity dependency based on depth
 	depthFigure := Draw{}
 	depthFigure.InitializeDrawStruct()
-	depthFigure.SetOutputFileName(strings.ToLower(prefix) + "_time-complexity-depth-26-inst").SetFigureName(figureName + "on the number of layers").
-		SetYaxisName("Time [ms]").SetXaxisName("Layers [-]")
-	var depthArr []int
-	for i := 1; i <= maxDepth; i++ {
-		depthArr = append(depthArr, i)
-	}
-	lines := getLinesForDepth(tc, depthArr, []int{26, 51, 76, 96}, []int{26})
-	err := depthFigure.plotTimeComplexity(lines, greyScale, meertcore, false, true, false)
-	if err != nil {
-		return err
-	}
-
-	depthFigure.SetOutputFileName(strings.ToLower(prefix) + "_time-complexity-depth-56-inst").SetFigureName(figureName + "on the number of layers")
-	lines = getLinesForDepth(tc, depthArr, []int{26, 51, 76, 96}, []int{56})
-	err = depthFigure.plotTimeComplexity(lines, greyScale, meertcore, false, true, false)
-	if err != nil {
-		return err
-	}
-
-	depthFigure.SetOutputFileName(strings.ToLower(prefix) + "_time-complexity-depth-96-inst").SetFigureName(figureName + "on the number of layers")
-	lines = getLinesForDepth(tc, depthArr, []int{26, 51, 76, 96}, []int{96})
-	err = depthFigure.plotTimeComplexity(lines, greyScale, meertcore, false, true, false)
-	if err != nil {
-		return err
+	depthFigure.SetFigureName(figureName + "on the number of layers").SetYaxisName("Time [ms]").SetXaxisName("Layers [-]")
+	for _, inst := range instances {
+		depthFigure.SetOutputFileName(fmt.Sprintf("%s_time-complexity-depth-%d-inst", strings.ToLower(prefix), inst))
+		lines := getLinesForDepth(tc, spec.Depths, apps, []int{inst})
+		err := depthFigure.plotTimeComplexity(lines, greyScale, meertcore, false, true, false)
+		if err != nil {
+			return err
+		}
 	}
 
 	//////// plotting dependencies for applications number
-	depthFigure.SetOutputFileName(strings.ToLower(prefix) + "_time-complexity-apps-number-26-inst").SetFigureName(figureName + "on the App number").
-		SetYaxisName("Time [ms]").SetXaxisName("Number of Applications [-]")
-	// iterating over the amount of apps in the system
-	var appArr []int
-	for appNumber := 1; appNumber <= maxAppNumber; appNumber += 5 {
-		appArr = append(appArr, appNumber)
-	}
-	lines = getLinesForAppNumber(tc, []int{2, 3, 4}, appArr, []int{26})
-	err = depthFigure.plotTimeComplexity(lines, greyScale, meertcore, true, false, false)
-	if err != nil {
-		return err
-	}
-
-	depthFigure.SetOutputFileName(strings.ToLower(prefix) + "_time-complexity-apps-number-56-inst").SetFigureName(figureName + "on the App number")
-	lines = getLinesForAppNumber(tc, []int{2, 3, 4}, appArr, []int{56})
-	err = depthFigure.plotTimeComplexity(lines, greyScale, meertcore, true, false, false)
-	if err != nil {
-		return err
-	}
-
-	depthFigure.SetOutputFileName(strings.ToLower(prefix) + "_time-complexity-apps-number-96-inst").SetFigureName(figureName + "on the App number")
-	lines = getLinesForAppNumber(tc, []int{2, 3, 4}, appArr, []int{96})
-	err = depthFigure.plotTimeComplexity(lines, greyScale, meertcore, true, false, false)
-	if err != nil {
-		return err
+	depthFigure.SetFigureName(figureName + "on the App number").SetYaxisName("Time [ms]").SetXaxisName("Number of Applications [-]")
+	for _, inst := range instances {
+		depthFigure.SetOutputFileName(fmt.Sprintf("%s_time-complexity-apps-number-%d-inst", strings.ToLower(prefix), inst))
+		lines := getLinesForAppNumber(tc, depths, spec.Apps, []int{inst})
+		err := depthFigure.plotTimeComplexity(lines, greyScale, meertcore, true, false, false)
+		if err != nil {
+			return err
+		}
 	}
 
 	//////// plotting dependencies for instances per application
-	var instArr []int
-	for instNumber := 1; instNumber <= maxNumInstancesPerApp; instNumber += 5 {
-		instArr = append(instArr, instNumber)
-	}
-
-	depthFigure.SetOutputFileName(strings.ToLower(prefix) + "_time-complexity-instances-per-app-1-apps").SetFigureName(figureName + "on instances per App")
-	lines = getLinesForInstances(tc, []int{2, 3, 4}, []int{1}, instArr)
-	err = depthFigure.plotTimeComplexity(lines, greyScale, meertcore, false, false, true)
-	if err != nil {
-		return err
-	}
-
-	depthFigure.SetOutputFileName(strings.ToLower(prefix) + "_time-complexity-instances-per-app-26-apps").SetFigureName(figureName + "on instances per App").
-		SetYaxisName("Time [ms]").SetXaxisName("Instances (per App) [-]")
-	lines = getLinesForInstances(tc, []int{2, 3, 4}, []int{26}, instArr)
-	err = depthFigure.plotTimeComplexity(lines, greyScale, meertcore, false, false, true)
-	if err != nil {
-		return err
-	}
-
-	depthFigure.SetOutputFileName(strings.ToLower(prefix) + "_time-complexity-instances-per-app-56-apps").SetFigureName(figureName + "on instances per App")
-	lines = getLinesForInstances(tc, []int{2, 3, 4}, []int{56}, instArr)
-	err = depthFigure.plotTimeComplexity(lines, greyScale, meertcore, false, false, true)
-	if err != nil {
-		return err
-	}
-
-	depthFigure.SetOutputFileName(strings.ToLower(prefix) + "_time-complexity-instances-per-app-96-apps").SetFigureName(figureName + "on instances per App")
-	lines = getLinesForInstances(tc, []int{2, 3, 4}, []int{96}, instArr)
-	err = depthFigure.plotTimeComplexity(lines, greyScale, meertcore, false, false, true)
-	if err != nil {
-		return err
+	depthFigure.SetFigureName(figureName + "on instances per App").SetYaxisName("Time [ms]").SetXaxisName("Instances (per App) [-]")
+	for _, app := range sweep.List(append([]int{spec.Apps.Min()}, spec.Apps.Slice(spec.Slices.Apps, 3)...)...) {
+		depthFigure.SetOutputFileName(fmt.Sprintf("%s_time-complexity-instances-per-app-%d-apps", strings.ToLower(prefix), app))
+		lines := getLinesForInstances(tc, depths, []int{app}, spec.Instances)
+		err := depthFigure.plotTimeComplexity(lines, greyScale, meertcore, false, false, true)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -221,13 +185,15 @@ func (d *Draw) plotTimeComplexity(lines map[string]plotter.XYs, greyScale, meert
 	if err != nil {
 		return err
 	}
-
-	// Save the plot to a PNG file
-	// ToDo - implement a relative path to enable execution out of everywhere in the system..
-	if err := p.Save(d.XLength, d.YLength, "figures/"+d.OutputFileName+".eps"); err != nil {
-		return err
+	if fitOverlay != "" {
+		err = addFittedCurves(p, greyScale, lines)
+		if err != nil {
+			return err
+		}
 	}
-	if err := p.Save(d.XLength, d.YLength, "figures/"+d.OutputFileName+".png"); err != nil {
+
+	// Save the plot to EPS and PNG files (by default)
+	if err := d.save(p, "eps", "png"); err != nil {
 		return err
 	}
 	d.Rendered = true
@@ -304,12 +270,13 @@ func getLinesForInstances(tc map[int]map[int]map[int]float64, depth []int, appNu
 	return lines
 }
 
-// getLinesForReliability converts measure ME-ERT-CORE reliability to plotter-friendly data
+// getLinesForReliability converts measure ME-ERT-CORE reliability to plotter-friendly data. Steps of the measurement
+// are numbered from 1.
 func getLinesForReliability(tc map[int]float64, apps, depth int) (map[string]plotter.XYs, error) {
 	lines := make(map[string]plotter.XYs, 0)
-	line := make(plotter.XYs, 0)
+	line := make(plotter.XYs, 0, len(tc))
 
-	for i := 1; i <= 300; i++ {
+	for i := 1; i <= len(tc); i++ {
 		val, ok := tc[i]
 		if !ok {
 			return nil, fmt.Errorf("couldn't extract key %d from map %v", i, tc)
@@ -332,16 +299,11 @@ func PlotFigures(greyScale bool, fileNames ...string) error {
 	for _, fileName := range fileNames {
 		// ToDo - make a workaround with relative path..
 		// read the data first
-		data, err := storedata.ImportData("data/", fileName)
-		if err != nil {
-			return err
-		}
-		// parse SystemModel data
-		depth, apps, instances, err := systemmodel.GetSystemModelParameters(data)
+		result, err := storedata.ImportResult(storedata.DataDir(), fileName)
 		if err != nil {
 			return err
 		}
-
+		data := result.Means()
 		// cut out the file extension
 		name := strings.ReplaceAll(fileName, ".json", "")
 		name = strings.ReplaceAll(name, ".csv", "")
@@ -354,11 +316,19 @@ func PlotFigures(greyScale bool, fileNames ...string) error {
 			prefix = "FMAIS"
 		}
 
-		// plot figures
-		err = PlotTimeComplexities(data, depth, apps, instances, prefix, greyScale, meertcore)
+		// plot figures, the committed figures show the slices of the paper
+		spec := sweep.FromData(data)
+		spec.Slices = sweep.PaperSlices()
+		err = PlotTimeComplexities(data, spec, prefix, greyScale, meertcore)
 		if err != nil {
 			return err
 		}
+		if result.HasMemory() {
+			err = PlotMemoryComplexities(result, sweep.FromData(data), prefix, greyScale)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -394,6 +364,134 @@ func PlotMeasuredReliability(rels map[int]float64, apps, depth int, greyScale, w
 	return nil
 }
 
+// PlotMeasuredReliabilityWithUncertainty plots reliability values obtained from measurement together with
+// an uncertainty band (e.g., a confidence interval) given by its lower and upper bounds
+func PlotMeasuredReliabilityWithUncertainty(rels, lower, upper map[int]float64, apps, depth int, greyScale, wide bool) error {
+	// setting figure name
+	figureName := "Measured ME-ERT-CORE Reliability values"
+	fileName := fmt.Sprintf("measurement_meertcore_uncertainty_depth_%d_apps_%d", depth, apps)
+	if wide {
+		fileName = fmt.Sprintf("measurement_meertcore_wide_uncertainty_depth_%d_apps_%d", depth, apps)
+	}
+
+	// initializing structure for the Figure
+	figure := Draw{}
+	figure.InitializeDrawStruct().SetFigureName(figureName).SetOutputFileName(fileName).
+		SetXaxisName("Time [s]").SetYaxisName("Reliability [-]").
+		SetYmin(0).SetYmax(1)
+
+	// converting measured reliability and its bounds to XY data
+	line, err := getLinesForReliability(rels, apps, depth)
+	if err != nil {
+		return err
+	}
+	band, err := getUncertaintyBand(lower, upper)
+	if err != nil {
+		return err
+	}
+
+	p := figure.initializeAndSetPlotter(true, false, false, false)
+
+	// adding grid to the figure
+	if figure.gridOn {
+		p.Add(plotter.NewGrid())
+	}
+
+	// adding uncertainty band first, so it stays below the curve
+	polygon, err := plotter.NewPolygon(band)
+	if err != nil {
+		return err
+	}
+	polygon.LineStyle.Width = 0
+	polygon.Color = color.Gray{Y: 220}
+	if !greyScale {
+		polygon.Color = color.RGBA{R: 158, G: 202, B: 225, A: 255}
+	}
+	p.Add(polygon)
+	p.Legend.Add("Confidence interval", polygon)
+
+	// adding plotters for gathered lines to the figure
+	err = AddScattersAndLines(p, greyScale, line)
+	if err != nil {
+		return err
+	}
+
+	// Save the plot to EPS and PNG files (by default)
+	if err := figure.save(p, "eps", "png"); err != nil {
+		return err
+	}
+	figure.Rendered = true
+
+	return nil
+}
+
+// getUncertaintyBand converts lower and upper bounds of the measured reliability to a closed polygon
+func getUncertaintyBand(lower, upper map[int]float64) (plotter.XYs, error) {
+	steps := len(upper)
+	band := make(plotter.XYs, 0, 2*steps)
+
+	// going forward along the upper bound..
+	for i := 1; i <= steps; i++ {
+		val, ok := upper[i]
+		if !ok {
+			return nil, fmt.Errorf("couldn't extract key %d from map of upper bounds %v", i, upper)
+		}
+		band = append(band, plotter.XY{X: float64(i), Y: val})
+	}
+	// ..and back along the lower bound
+	for i := steps; i >= 1; i-- {
+		val, ok := lower[i]
+		if !ok {
+			return nil, fmt.Errorf("couldn't extract key %d from map of lower bounds %v", i, lower)
+		}
+		band = append(band, plotter.XY{X: float64(i), Y: val})
+	}
+
+	return band, nil
+}
+
+// PlotSimulation plots reliability and availability of the FMAIS sampled during the simulation
+func PlotSimulation(rels, availability map[int]float64, depth, ticks int, greyScale bool) error {
+	// setting figure name
+	figureName := "Simulated ME-ERT-CORE Reliability and Availability"
+	fileName := fmt.Sprintf("simulation_fmais_depth_%d_ticks_%d", depth, ticks)
+
+	// initializing structure for the Figure
+	figure := Draw{}
+	figure.InitializeDrawStruct().SetFigureName(figureName).SetOutputFileName(fileName).
+		SetXaxisName("Time [ticks]").SetYaxisName("Reliability, Availability [-]").
+		SetYmin(0).SetYmax(1)
+
+	// converting simulated time series to XY data
+	lines := map[string]plotter.XYs{
+		fmt.Sprintf("Reliability; %d layers", depth):  getLineForTimeSeries(rels),
+		fmt.Sprintf("Availability; %d layers", depth): getLineForTimeSeries(availability),
+	}
+
+	// plotting data
+	err := figure.plotMeasuredReliability(lines, greyScale)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// getLineForTimeSeries converts time series of an arbitrary length to plotter-friendly data sorted by time
+func getLineForTimeSeries(ts map[int]float64) plotter.XYs {
+	keys := make([]int, 0, len(ts))
+	for k := range ts {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	line := make(plotter.XYs, 0, len(keys))
+	for _, k := range keys {
+		line = append(line, plotter.XY{X: float64(k), Y: ts[k]})
+	}
+	return line
+}
+
 // PlotMeasuredReliabilityJoint plots multiple reliability values obtained from measurement
 func PlotMeasuredReliabilityJoint(tc map[string]map[int]float64, apps, depth []int, greyScale bool) error {
 	linesFMAIS := make(map[string]plotter.XYs, 0)
@@ -443,12 +541,8 @@ func (d *Draw) plotMeasuredReliability(lines map[string]plotter.XYs, greyScale b
 		return err
 	}
 
-	// Save the plot to a PNG file
-	// ToDo - implement a relative path to enable execution out of everywhere in the system..
-	if err := p.Save(d.XLength, d.YLength, "figures/"+d.OutputFileName+".eps"); err != nil {
-		return err
-	}
-	if err := p.Save(d.XLength, d.YLength, "figures/"+d.OutputFileName+".png"); err != nil {
+	// Save the plot to EPS and PNG files (by default)
+	if err := d.save(p, "eps", "png"); err != nil {
 		return err
 	}
 	d.Rendered = true
@@ -544,12 +638,12 @@ func PlotMeErtCoreCoefficients(rels map[int]float64, apps, depth int, greyScale,
 func PlotJointFigure(greyScale, meertcore bool, fileNames ...string) error {
 	if !meertcore {
 		dataArr := make(map[string]map[int]map[int]map[int]float64, len(fileNames))
-		var lastPrefix string
+		var spec sweep.Spec
 
 		for _, fileName := range fileNames {
 			// ToDo - make a workaround with relative path..
 			// read the data first
-			data, err := storedata.ImportData("data/", fileName)
+			data, err := storedata.ImportData(storedata.DataDir(), fileName)
 			if err != nil {
 				return err
 			}
@@ -564,17 +658,17 @@ func PlotJointFigure(greyScale, meertcore bool, fileNames ...string) error {
 				prefix = "(PD);"
 			}
 			dataArr[prefix] = data
-			lastPrefix = prefix
-		}
-
-		// parse SystemModel data
-		_, apps, instances, err := systemmodel.GetSystemModelParameters(dataArr[lastPrefix])
-		if err != nil {
-			return err
+			// only slices present in all data files can be compared
+			if len(dataArr) == 1 {
+				spec = sweep.FromData(data)
+				spec.Slices = sweep.PaperSlices()
+			} else {
+				spec = spec.Intersect(sweep.FromData(data))
+			}
 		}
 
 		// plot figures
-		err = PlotTimeComplexitiesJoint(dataArr, apps, instances, greyScale)
+		err := PlotTimeComplexitiesJoint(dataArr, spec, greyScale)
 		if err != nil {
 			return err
 		}
@@ -587,7 +681,7 @@ func PlotJointFigure(greyScale, meertcore bool, fileNames ...string) error {
 		for _, fileName := range fileNames {
 			// read the data first
 			// assuming only JSON files at input
-			data, err := storedata.ImportDataMeErtCore("data/", fileName)
+			data, err := storedata.ImportDataMeErtCore(storedata.DataDir(), fileName)
 			if err != nil {
 				return err
 			}
@@ -605,38 +699,32 @@ func PlotJointFigure(greyScale, meertcore bool, fileNames ...string) error {
 	return nil
 }
 
-// PlotTimeComplexitiesJoint function plots time complexity curves for all input data files
-func PlotTimeComplexitiesJoint(tc map[string]map[int]map[int]map[int]float64, maxAppNumber int,
-	maxNumInstancesPerApp int, greyScale bool,
-) error {
+// PlotTimeComplexitiesJoint function plots time complexity curves for all input data files. Curves are plotted for
+// the deepest selected FMAIS and the lowest and the greatest selected numbers of instances (or apps), see sweep.Slices.
+func PlotTimeComplexitiesJoint(tc map[string]map[int]map[int]map[int]float64, spec sweep.Spec, greyScale bool) error {
+	if err := spec.Validate(); err != nil {
+		return fmt.Errorf("benchmarked data don't share the parameter grid: %w", err)
+	}
+	depth := spec.Depths.Slice(spec.Slices.Depths, 1).Max()
+
 	// we need to extract and plot time complexity dependency on the number of apps and number of instances per apps
 	linesApp := make(map[string]plotter.XYs, 0)
 	linesInstApp := make(map[string]plotter.XYs, 0)
 
 	// gathering plotters first
 	for key, value := range tc {
-		// iterating over the amount of apps in the system
-		var appArr []int
-		for appNumber := 6; appNumber <= maxAppNumber; appNumber += 5 {
-			appArr = append(appArr, appNumber)
+		for _, inst := range spec.Instances.Slice(spec.Slices.Instances, 2).Ends() {
+			lines := getLinesForAppNumber(value, []int{depth}, spec.Apps, []int{inst})
+			linesApp[fmt.Sprintf("%s FMAIS; %d layers with %d instances per App", key, depth, inst)] =
+				lines["FMAIS; "+strconv.Itoa(depth)+" layers with "+strconv.Itoa(inst)+" instances (per App)"]
 		}
-		lines := getLinesForAppNumber(value, []int{4}, appArr, []int{26})
-		linesApp[key+" FMAIS; 4 layers with 26 instances per App"] = lines["FMAIS; "+strconv.Itoa(4)+" layers with "+strconv.Itoa(26)+" instances (per App)"]
-
-		lines = getLinesForAppNumber(value, []int{4}, appArr, []int{96})
-		linesApp[key+" FMAIS; 4 layers with 96 instances per App"] = lines["FMAIS; "+strconv.Itoa(4)+" layers with "+strconv.Itoa(96)+" instances (per App)"]
 
 		//////// plotting dependencies for instances per application
-		var instArr []int
-		for instNumber := 1; instNumber <= maxNumInstancesPerApp; instNumber += 5 {
-			instArr = append(instArr, instNumber)
+		for _, app := range spec.Apps.Slice(spec.Slices.Apps, 2).Ends() {
+			lines := getLinesForInstances(value, []int{depth}, []int{app}, spec.Instances)
+			linesInstApp[fmt.Sprintf("%s\nFMAIS; %d layers with %d Apps", key, depth, app)] =
+				lines["FMAIS; "+strconv.Itoa(depth)+" layers with "+strconv.Itoa(app)+" Apps"]
 		}
-
-		lines = getLinesForInstances(value, []int{4}, []int{26}, instArr)
-		linesInstApp[key+"\nFMAIS; 4 layers with 26 Apps"] = lines["FMAIS; "+strconv.Itoa(4)+" layers with "+strconv.Itoa(26)+" Apps"]
-
-		lines = getLinesForInstances(value, []int{4}, []int{96}, instArr)
-		linesInstApp[key+"\nFMAIS; 4 layers with 96 Apps"] = lines["FMAIS; "+strconv.Itoa(4)+" layers with "+strconv.Itoa(96)+" Apps"]
 	}
 
 	// plotting time complexity dependency based on depth
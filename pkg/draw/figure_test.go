@@ -2,7 +2,10 @@ package draw
 
 import (
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/sweep"
 	"gotest.tools/assert"
+	"os"
+	"path/filepath"
 	"sort"
 	"testing"
 )
@@ -29,3 +32,115 @@ func TestGetLinesForInstances(t *testing.T) {
 		t.Logf("Line for key %v is %v", key, line)
 	}
 }
+
+func TestGetUncertaintyBand(t *testing.T) {
+	lower := make(map[int]float64, 300)
+	upper := make(map[int]float64, 300)
+	for i := 1; i <= 300; i++ {
+		lower[i] = 0.4
+		upper[i] = 0.6
+	}
+
+	band, err := getUncertaintyBand(lower, upper)
+	assert.NilError(t, err)
+	assert.Equal(t, len(band), 600)
+	// polygon goes forward along the upper bound and back along the lower bound
+	assert.Equal(t, band[0].X, 1.0)
+	assert.Equal(t, band[0].Y, 0.6)
+	assert.Equal(t, band[599].X, 1.0)
+	assert.Equal(t, band[599].Y, 0.4)
+
+	delete(lower, 150)
+	_, err = getUncertaintyBand(lower, upper)
+	assert.ErrorContains(t, err, "couldn't extract key 150")
+}
+
+func TestGetLineForTimeSeries(t *testing.T) {
+	line := getLineForTimeSeries(map[int]float64{
+		3: 0.7,
+		1: 0.9,
+		2: 0.8,
+	})
+	assert.Equal(t, len(line), 3)
+	for i, xy := range line {
+		assert.Equal(t, xy.X, float64(i+1))
+	}
+	assert.Equal(t, line[2].Y, 0.7)
+}
+
+func TestPlotTimeComplexities(t *testing.T) {
+	defer func(target OutputTarget) { defaultTarget = target }(GetDefaultOutputTarget())
+	dir := t.TempDir()
+	err := SetDefaultOutputTarget(OutputTarget{Dir: dir, Formats: []string{"svg"}})
+	assert.NilError(t, err)
+
+	tc, err := storedata.ImportData("../../data/", "benchmark_fmais_2023-03-26_01-59-08.json")
+	assert.NilError(t, err)
+
+	// slices are picked from the values present in both, the grid and the data
+	spec := sweep.Spec{Depths: sweep.Axis{1, 2, 3, 4, 5}, Apps: sweep.Axis{1, 26, 51, 1000}, Instances: sweep.Axis{1, 56}}
+	err = PlotTimeComplexities(tc, spec, "FMAIS", false, false)
+	assert.NilError(t, err)
+	for _, name := range []string{"fmais_time-complexity-depth-56-inst.svg", "fmais_time-complexity-apps-number-1-inst.svg",
+		"fmais_time-complexity-instances-per-app-1-apps.svg", "fmais_time-complexity-instances-per-app-51-apps.svg"} {
+		_, err = os.Stat(filepath.Join(dir, name))
+		assert.NilError(t, err)
+	}
+	_, err = os.Stat(filepath.Join(dir, "fmais_time-complexity-instances-per-app-1000-apps.svg"))
+	assert.Assert(t, os.IsNotExist(err))
+
+	err = PlotTimeComplexities(tc, sweep.Spec{Depths: sweep.Axis{7}, Apps: sweep.Axis{1}, Instances: sweep.Axis{1}}, "FMAIS", false, false)
+	assert.ErrorContains(t, err, "don't cover the parameter grid")
+}
+
+func TestPlotMemoryComplexities(t *testing.T) {
+	defer func(target OutputTarget) { defaultTarget = target }(GetDefaultOutputTarget())
+	dir := t.TempDir()
+	err := SetDefaultOutputTarget(OutputTarget{Dir: dir, Formats: []string{"svg"}})
+	assert.NilError(t, err)
+
+	result := storedata.NewResultDocument("FMAIS generation time", "us", 1, false)
+	spec := sweep.Spec{Depths: sweep.Axis{1, 2}, Apps: sweep.Axis{1, 6}, Instances: sweep.Axis{1, 6}}
+	err = PlotMemoryComplexities(result, spec, "FMAIS", false)
+	assert.ErrorContains(t, err, "memory metrics are not stored")
+
+	for _, d := range spec.Depths {
+		for _, a := range spec.Apps {
+			for _, i := range spec.Instances {
+				size := float64(d * a * i * 1024 * 1024)
+				result.AddCell(d, a, i, storedata.ComputeStats([]float64{size}))
+				err = result.SetMemory(d, a, i, storedata.Memory{AllocatedBytes: size, Allocations: size / 64, PeakHeap: 2 * size, RetainedHeap: size / 2})
+				assert.NilError(t, err)
+			}
+		}
+	}
+	err = PlotMemoryComplexities(result, spec, "FMAIS", false)
+	assert.NilError(t, err)
+	for _, name := range []string{"fmais_memory-complexity-allocated-memory-apps-number-6-inst.svg",
+		"fmais_memory-complexity-allocations-instances-per-app-6-apps.svg",
+		"fmais_memory-complexity-peak-heap-apps-number-6-inst.svg",
+		"fmais_memory-complexity-retained-heap-instances-per-app-6-apps.svg"} {
+		_, err = os.Stat(filepath.Join(dir, name))
+		assert.NilError(t, err)
+	}
+}
+
+func TestPlotComparison(t *testing.T) {
+	defer func(target OutputTarget) { defaultTarget = target }(GetDefaultOutputTarget())
+	dir := t.TempDir()
+	err := SetDefaultOutputTarget(OutputTarget{Dir: dir, Formats: []string{"svg"}})
+	assert.NilError(t, err)
+
+	ratios := map[int]map[int]map[int]float64{
+		1: {1: {1: 1, 6: 1.1}, 6: {1: 0.9, 6: 1.2}},
+		2: {1: {1: 1, 6: 1.05}, 6: {1: 0.95, 6: 1}},
+	}
+	err = PlotComparison(ratios, sweep.FromData(ratios), "Comparison", false)
+	assert.NilError(t, err)
+	for _, name := range []string{"comparison_time-ratio-apps-number-6-inst.svg", "comparison_time-ratio-instances-per-app-6-apps.svg"} {
+		_, err = os.Stat(filepath.Join(dir, name))
+		assert.NilError(t, err)
+	}
+	err = PlotComparison(ratios, sweep.Spec{Depths: sweep.Axis{7}, Apps: sweep.Axis{1}, Instances: sweep.Axis{1}}, "Comparison", false)
+	assert.ErrorContains(t, err, "don't cover the parameter grid")
+}
@@ -0,0 +1,144 @@
+// Package draw implements a set of helper functions to draw SystemModel. This file in particular implements fitting
+// of the models to the time complexity slices and an overlay of the fitted curves in the figures.
+package draw
+
+import (
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/fitting"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/sweep"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+	"math"
+	"sort"
+	"strings"
+)
+
+// fitSamples is a number of points, in which the fitted curve is sampled
+const fitSamples = 100
+
+// fitOverlay is a model fitted to each line of the complexity figures, no curves are overlaid, if it is empty
+var fitOverlay fitting.Kind
+
+// fitDegree is a degree of the polynomial overlay
+var fitDegree int
+
+// SetFitOverlay sets a model (exponential, polynomial[degree], power-law or best), which is fitted to each line of the
+// complexity figures (time, memory and comparison ones) drawn afterwards. Fitted curves are overlaid as dotted lines.
+// Empty model disables the overlay.
+func SetFitOverlay(model string) error {
+	if model == "" {
+		fitOverlay, fitDegree = "", 0
+		return nil
+	}
+	kind, degree, err := fitting.ParseModel(model)
+	if err != nil {
+		return err
+	}
+	fitOverlay, fitDegree = kind, degree
+	return nil
+}
+
+// addFittedCurves fits the overlay model to each line and adds the fitted curves (in the color of their line) to the
+// figure. Lines, which the model can't be fitted to (e.g., the exponential model to zero times), are left without a curve.
+func addFittedCurves(plt *plot.Plot, greyScale bool, lines map[string]plotter.XYs) error {
+	// keys are sorted in the same way as by AddScattersAndLines, so the curves share colors with their lines
+	keys := make([]string, 0, len(lines))
+	for key := range lines {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for i, key := range keys {
+		m, err := fitLine(lines[key], fitOverlay, fitDegree)
+		if err != nil {
+			continue
+		}
+		curve, err := plotter.NewLine(sampleModel(m, lines[key]))
+		if err != nil {
+			return err
+		}
+		curve.Width = linewidth / 2
+		if !greyScale {
+			curve.Color = plotutil.Color(i)
+		}
+		curve.Dashes = []vg.Length{vg.Points(2), vg.Points(4)}
+		plt.Add(curve)
+		plt.Legend.Add(fmt.Sprintf("fit: %s (R² = %.4f)", m, m.R2), curve)
+	}
+	return nil
+}
+
+// fitLine fits a model to the points of a line
+func fitLine(line plotter.XYs, kind fitting.Kind, degree int) (fitting.Model, error) {
+	xs := make([]float64, len(line))
+	ys := make([]float64, len(line))
+	for i, xy := range line {
+		xs[i], ys[i] = xy.X, xy.Y
+	}
+	return fitting.Fit(kind, degree, xs, ys)
+}
+
+// sampleModel samples the model evenly over the X range of the line
+func sampleModel(m fitting.Model, line plotter.XYs) plotter.XYs {
+	xmin, xmax, _, _ := plotter.XYRange(line)
+	samples := make(plotter.XYs, fitSamples)
+	for i := range samples {
+		x := xmin + (xmax-xmin)*float64(i)/float64(fitSamples-1)
+		samples[i] = plotter.XY{X: x, Y: m.Predict(x)}
+	}
+	return samples
+}
+
+// FitTimeComplexities fits a model (exponential, polynomial[degree], power-law or best) to the same slices of the
+// benchmarked data, which are plotted by PlotTimeComplexities, i.e., times (in milliseconds) on the depth, on the number
+// of applications and on the number of instances per application. Slices, which the model can't be fitted to, are
+// skipped. Models fitted on the depth are extrapolated to the provided depths.
+func FitTimeComplexities(tc map[int]map[int]map[int]float64, spec sweep.Spec, model string, extrapolateDepths []int) ([]fitting.SliceFit, error) {
+	kind, degree, err := fitting.ParseModel(model)
+	if err != nil {
+		return nil, err
+	}
+	spec = spec.Intersect(sweep.FromData(tc))
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("benchmarked data don't cover the parameter grid: %w", err)
+	}
+	depths := spec.Depths.Slice(spec.Slices.Depths, 3)
+	instances := spec.Instances.Slice(spec.Slices.Instances, 3)
+
+	fits := make([]fitting.SliceFit, 0)
+	for _, slices := range []struct {
+		variable string
+		lines    map[string]plotter.XYs
+	}{
+		{"depth", getLinesForDepth(tc, spec.Depths, spec.Apps.Slice(spec.Slices.CurveApps, 4), instances)},
+		{"apps", getLinesForAppNumber(tc, depths, spec.Apps, instances)},
+		{"instances", getLinesForInstances(tc, depths, sweep.List(append([]int{spec.Apps.Min()}, spec.Apps.Slice(spec.Slices.Apps, 3)...)...), spec.Instances)},
+	} {
+		keys := make([]string, 0, len(slices.lines))
+		for key := range slices.lines {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			m, err := fitLine(slices.lines[key], kind, degree)
+			if err != nil || math.IsNaN(m.R2) {
+				continue
+			}
+			f := fitting.SliceFit{
+				Variable: slices.variable,
+				Slice:    strings.TrimPrefix(key, "FMAIS; "),
+				Model:    m,
+			}
+			if slices.variable == "depth" && len(extrapolateDepths) > 0 {
+				f.Extrapolated = m.Extrapolate(extrapolateDepths...)
+			}
+			fits = append(fits, f)
+		}
+	}
+	if len(fits) == 0 {
+		return nil, fmt.Errorf("model %s can't be fitted to any slice of the benchmarked data", model)
+	}
+	return fits, nil
+}
@@ -0,0 +1,55 @@
+package draw
+
+import (
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/fitting"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/sweep"
+	"gotest.tools/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFitTimeComplexities(t *testing.T) {
+	tc, err := storedata.ImportData("../../data/", "benchmark_fmais_2023-03-26_01-59-08.json")
+	assert.NilError(t, err)
+
+	fits, err := FitTimeComplexities(tc, sweep.FromData(tc), "exponential", []int{5, 6})
+	assert.NilError(t, err)
+	depthFits := 0
+	for _, f := range fits {
+		assert.Equal(t, f.Model.Kind, fitting.Exponential)
+		if f.Variable == "depth" {
+			depthFits++
+			assert.Equal(t, len(f.Extrapolated), 2)
+			// time grows with the depth
+			assert.Assert(t, f.Extrapolated[6] > f.Extrapolated[5])
+		} else {
+			assert.Assert(t, f.Extrapolated == nil)
+		}
+	}
+	assert.Assert(t, depthFits > 0)
+	t.Logf("Fitted %d slices, the first one is %s: %s (R² %.4f)", len(fits), fits[0].Slice, fits[0].Model, fits[0].Model.R2)
+
+	_, err = FitTimeComplexities(tc, sweep.FromData(tc), "linear", nil)
+	assert.ErrorContains(t, err, "unknown model")
+}
+
+func TestPlotTimeComplexitiesWithFit(t *testing.T) {
+	defer func(target OutputTarget) { defaultTarget = target }(GetDefaultOutputTarget())
+	defer func() { assert.NilError(t, SetFitOverlay("")) }()
+	dir := t.TempDir()
+	assert.NilError(t, SetDefaultOutputTarget(OutputTarget{Dir: dir, Formats: []string{"svg"}}))
+	assert.ErrorContains(t, SetFitOverlay("sine"), "unknown model")
+	assert.NilError(t, SetFitOverlay("best"))
+
+	tc := map[int]map[int]map[int]float64{
+		1: {1: {1: 100, 6: 300}, 6: {1: 200, 6: 700}, 11: {1: 400, 6: 1500}},
+		2: {1: {1: 300, 6: 900}, 6: {1: 600, 6: 2100}, 11: {1: 1200, 6: 4500}},
+		3: {1: {1: 900, 6: 2700}, 6: {1: 1800, 6: 6300}, 11: {1: 3600, 6: 13500}},
+	}
+	err := PlotTimeComplexities(tc, sweep.FromData(tc), "FMAIS", false, false)
+	assert.NilError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "fmais_time-complexity-depth-6-inst.svg"))
+	assert.NilError(t, err)
+}
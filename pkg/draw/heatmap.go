@@ -0,0 +1,266 @@
+// Package draw implements a set of helper functions to draw SystemModel. This file in particular implements rendering
+// of the SystemModel as a reliability heat map, i.e., nodes are coloured by their reliability and width of the edges
+// corresponds to the priority of the child instance. Weak branches of the FMAIS are thus visible at a glance.
+package draw
+
+import (
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette"
+	"gonum.org/v1/plot/palette/moreland"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+	vgdraw "gonum.org/v1/plot/vg/draw"
+	"image/color"
+	"math"
+)
+
+// heatMapFormats are default formats, in which the heat map is stored
+var heatMapFormats = []string{"png", "svg", "eps", "pdf"}
+
+const colorBarWidth = 4 * vg.Centimeter // width of the colour bar placed next to the heat map
+const minEdgeWidth = vg.Length(1)       // width of the edge to the instance with the lowest priority (in points)
+const maxEdgeWidth = vg.Length(8)       // width of the edge to the instance with the highest priority (in points)
+
+var unknownColor = color.Gray{Y: 160} // colour of the instances, which reliability is not set
+var edgeColor = color.Gray{Y: 96}     // colour of the edges
+
+// drawReliabilityHeatMap draws a figure of the SystemModel, where each node is coloured by its reliability and
+// edge width is set by the priority of the child instance. Colour bar is placed on the right side of the figure.
+// Figure is stored in the formats of the output target (all heatMapFormats by default).
+func (d *Draw) drawReliabilityHeatMap(sm *systemmodel.SystemModel, ds *Coordinates) error {
+	d.adjustFigureSize(ds)
+	p, cb, err := d.plotReliabilityHeatMap(sm, ds)
+	if err != nil {
+		return err
+	}
+
+	// heat map and colour bar are placed next to each other
+	err = d.target.write(d.OutputFileName, d.XLength+colorBarWidth, d.YLength, func(c vgdraw.Canvas) {
+		composeHeatMap(c, p, cb, d.XLength, d.YLength)
+	}, heatMapFormats...)
+	if err != nil {
+		return err
+	}
+	d.Rendered = true
+	return nil
+}
+
+// plotReliabilityHeatMap creates a plot of the heat map and a plot of the colour bar
+func (d *Draw) plotReliabilityHeatMap(sm *systemmodel.SystemModel, ds *Coordinates) (*plot.Plot, *plot.Plot, error) {
+	lo, hi, err := reliabilityRange(sm)
+	if err != nil {
+		return nil, nil, err
+	}
+	cm := reliabilityColorMap()
+	cm.SetMax(hi)
+	cm.SetMin(lo)
+
+	p := plot.New()
+	p.Title.Text = d.FigureName
+	p.X.Label.Text = d.XaxisName
+	p.Y.Label.Text = d.YaxisName
+	if d.gridOn {
+		p.Add(plotter.NewGrid())
+	}
+
+	// adding edges, their width corresponds to the priority of the child instance
+	maxPriority := greatestPriority(sm)
+	paths := sm.InstancePaths()
+	for i := 1; i <= len(sm.Layers); i++ {
+		for _, v := range sm.Layers[i].Instances {
+			for _, rel := range v.Relations {
+				line := make(plotter.XYs, 2)
+				line[0].X, line[0].Y = ds.Points[paths[v]].Coordinates.XY(0)
+				line[1].X, line[1].Y = ds.Points[paths[rel]].Coordinates.XY(0)
+				l, err := plotter.NewLine(line)
+				if err != nil {
+					return nil, nil, err
+				}
+				l.Color = edgeColor
+				l.Width = minEdgeWidth
+				if prty, err := rel.GetPriority(); err == nil {
+					l.Width = edgeWidth(prty, maxPriority)
+				}
+				p.Add(l)
+			}
+		}
+	}
+
+	// adding nodes, the shape corresponds to the kind of the instance and the colour to its reliability
+	for i := 1; i <= len(sm.Layers); i++ {
+		for _, v := range sm.Layers[i].Instances {
+			s, err := plotter.NewScatter(ds.Points[paths[v]].Coordinates)
+			if err != nil {
+				return nil, nil, err
+			}
+			s.Shape = kindShape(instanceKind(v))
+			s.Radius = 0.25 * vg.Centimeter
+			s.Color = heatMapColor(cm, v)
+			p.Add(s)
+		}
+	}
+	for _, kind := range []string{"root", "vi", "app"} {
+		s, err := plotter.NewScatter(ds.Points[paths[sm.Layers[1].Instances[0]]].Coordinates)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.Shape = kindShape(kind)
+		s.Radius = 0.2 * vg.Centimeter
+		s.Color = unknownColor
+		p.Legend.Add(kindLegend(kind), s)
+	}
+	p.Legend.Top = true
+
+	labels, err := plotter.NewLabels(ds.Labels)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.Add(labels)
+
+	// colour bar is a separate plot, which is placed next to the heat map
+	cb := plot.New()
+	cb.HideX()
+	cb.Y.Label.Text = "Reliability [-]"
+	cb.Add(&colorBar{colorMap: cm, colors: 256})
+
+	return p, cb, nil
+}
+
+// composeHeatMap draws the heat map and the colour bar next to each other on a canvas
+func composeHeatMap(dc vgdraw.Canvas, p, cb *plot.Plot, width, height vg.Length) {
+	p.Draw(vgdraw.Crop(dc, 0, -colorBarWidth, 0, 0))
+	// colour bar is slightly shorter than the figure, so it is aligned with the nodes rather than with the title
+	cb.Draw(vgdraw.Crop(dc, width, 0, height/10, -height/10))
+}
+
+// reliabilityRange returns the lowest and the highest reliability of the instances in SystemModel. If all
+// reliabilities are equal, the range is extended down to 0, so the colour map is well-defined.
+func reliabilityRange(sm *systemmodel.SystemModel) (float64, float64, error) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, layer := range sm.Layers {
+		for _, inst := range layer.Instances {
+			r, err := inst.GetReliability()
+			if err != nil {
+				continue
+			}
+			lo = math.Min(lo, r)
+			hi = math.Max(hi, r)
+		}
+	}
+	if math.IsInf(lo, 1) {
+		return 0, 0, fmt.Errorf("reliability is not set for any instance, compute it with ME-ERT-CORE first")
+	}
+	if lo == hi {
+		lo = 0
+		if hi == 0 {
+			hi = 1
+		}
+	}
+	return lo, hi, nil
+}
+
+// reliabilityColorMap returns a colour map, where low reliability is red and high reliability is green.
+// The colour map is built directly rather than via palette.Reverse, which loses the upper boundary
+// of the range in floating point arithmetic.
+func reliabilityColorMap() palette.ColorMap {
+	greenRed := moreland.SmoothGreenRed()
+	greenRed.SetMax(1)
+	green, _ := greenRed.At(greenRed.Min())
+	red, _ := greenRed.At(greenRed.Max())
+	return moreland.NewSmoothDiverging(red, green, 88)
+}
+
+// greatestPriority returns the greatest priority among the instances of SystemModel
+func greatestPriority(sm *systemmodel.SystemModel) float64 {
+	var maxPriority float64
+	for _, layer := range sm.Layers {
+		for _, inst := range layer.Instances {
+			if p, err := inst.GetPriority(); err == nil {
+				maxPriority = math.Max(maxPriority, p)
+			}
+		}
+	}
+	return maxPriority
+}
+
+// edgeWidth scales the priority to the width of the edge between minEdgeWidth and maxEdgeWidth
+func edgeWidth(priority, maxPriority float64) vg.Length {
+	if maxPriority <= 0 || priority <= 0 {
+		return minEdgeWidth
+	}
+	return minEdgeWidth + (maxEdgeWidth-minEdgeWidth)*vg.Length(math.Min(priority/maxPriority, 1))
+}
+
+// heatMapColor returns a colour of the instance with regard to its reliability
+func heatMapColor(cm palette.ColorMap, inst *systemmodel.Instance) color.Color {
+	r, err := inst.GetReliability()
+	if err != nil {
+		return unknownColor
+	}
+	// reliability is clamped to the range of the colour map
+	c, err := cm.At(math.Max(cm.Min(), math.Min(cm.Max(), r)))
+	if err != nil {
+		return unknownColor
+	}
+	return c
+}
+
+// colorBar is a vertical colour bar, which is drawn with vector primitives (unlike plotter.ColorBar, which is
+// drawn as an image and thus is not supported by the EPS backend)
+type colorBar struct {
+	colorMap palette.ColorMap
+	colors   int // number of colour steps
+}
+
+// Plot implements the plot.Plotter interface
+func (cb *colorBar) Plot(c vgdraw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&c)
+	step := (cb.colorMap.Max() - cb.colorMap.Min()) / float64(cb.colors)
+	for i := 0; i < cb.colors; i++ {
+		lo := cb.colorMap.Min() + float64(i)*step
+		clr, err := cb.colorMap.At(math.Min(lo+step/2, cb.colorMap.Max()))
+		if err != nil {
+			continue
+		}
+		// neighbouring steps overlap slightly to avoid thin gaps in the rasterized output
+		rect := []vg.Point{
+			{X: trX(0), Y: trY(lo)},
+			{X: trX(1), Y: trY(lo)},
+			{X: trX(1), Y: trY(math.Min(lo+1.5*step, cb.colorMap.Max()))},
+			{X: trX(0), Y: trY(math.Min(lo+1.5*step, cb.colorMap.Max()))},
+		}
+		c.FillPolygon(clr, c.ClipPolygonXY(rect))
+	}
+}
+
+// DataRange implements the plot.DataRanger interface
+func (cb *colorBar) DataRange() (xmin, xmax, ymin, ymax float64) {
+	return 0, 1, cb.colorMap.Min(), cb.colorMap.Max()
+}
+
+// kindShape returns a glyph shape of the instance kind (root, vi or app)
+func kindShape(kind string) vgdraw.GlyphDrawer {
+	switch kind {
+	case "root":
+		return plotutil.Shape(7) // filled pyramid
+	case "vi":
+		return plotutil.Shape(5) // filled circle
+	default:
+		return plotutil.Shape(6) // filled box
+	}
+}
+
+// kindLegend returns a legend entry of the instance kind
+func kindLegend(kind string) string {
+	switch kind {
+	case "root":
+		return "MAIS"
+	case "vi":
+		return "VI"
+	default:
+		return "App"
+	}
+}
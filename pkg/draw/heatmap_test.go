@@ -0,0 +1,99 @@
+package draw
+
+import (
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/meertcore"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"gotest.tools/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReliabilityRange(t *testing.T) {
+	sm := systemmodel.CreateSystemModelDepth4()
+	lo, hi, err := reliabilityRange(sm)
+	assert.NilError(t, err)
+	t.Logf("Reliability range of leaf instances is [%v, %v]", lo, hi)
+	assert.Assert(t, lo < hi)
+
+	// ME-ERT-CORE sets reliability of the rest of instances, range can only get wider
+	me := meertcore.MeErtCore{
+		SystemModel: sm,
+	}
+	_, err = me.ComputeReliabilityPerDefinition()
+	assert.NilError(t, err)
+	lo2, hi2, err := reliabilityRange(sm)
+	assert.NilError(t, err)
+	t.Logf("Reliability range of all instances is [%v, %v]", lo2, hi2)
+	assert.Assert(t, lo2 <= lo && hi2 >= hi)
+
+	// single reliability is extended down to 0
+	single := systemmodel.SystemModel{}
+	single.InitializeSystemModel(1, 1).InitializeRootLayer()
+	single.Layers[1].Instances[0].SetReliability(0.5)
+	lo, hi, err = reliabilityRange(&single)
+	assert.NilError(t, err)
+	assert.Equal(t, lo, 0.0)
+	assert.Equal(t, hi, 0.5)
+
+	empty := systemmodel.SystemModel{}
+	empty.InitializeSystemModel(1, 1).InitializeRootLayer()
+	_, _, err = reliabilityRange(&empty)
+	assert.ErrorContains(t, err, "compute it with ME-ERT-CORE first")
+}
+
+func TestEdgeWidth(t *testing.T) {
+	assert.Equal(t, edgeWidth(0.5, 0.5), maxEdgeWidth)
+	assert.Equal(t, edgeWidth(0, 0.5), minEdgeWidth)
+	assert.Equal(t, edgeWidth(0.5, 0), minEdgeWidth)
+	assert.Equal(t, edgeWidth(0.25, 0.5), (minEdgeWidth+maxEdgeWidth)/2)
+}
+
+func TestHeatMapColor(t *testing.T) {
+	cm := reliabilityColorMap()
+	cm.SetMax(0.8)
+	cm.SetMin(0.2)
+
+	inst := &systemmodel.Instance{Name: "App#1-1-1"}
+	assert.Equal(t, heatMapColor(cm, inst), unknownColor)
+
+	// reliabilities out of range get the colour of the closest boundary
+	low, err := cm.At(0.2)
+	assert.NilError(t, err)
+	inst.SetReliability(0.1)
+	assert.Equal(t, heatMapColor(cm, inst), low)
+	high, err := cm.At(0.8)
+	assert.NilError(t, err)
+	inst.SetReliability(0.9)
+	assert.Equal(t, heatMapColor(cm, inst), high)
+
+	// low reliability is red, high reliability is green
+	lr, lg, _, _ := low.RGBA()
+	hr, hg, _, _ := high.RGBA()
+	assert.Assert(t, lr > lg && hg > hr)
+}
+
+func TestDrawReliabilityHeatMap(t *testing.T) {
+	sm := systemmodel.CreateSystemModelDepth4()
+	me := meertcore.MeErtCore{
+		SystemModel: sm,
+	}
+	_, err := me.ComputeReliabilityPerDefinition()
+	assert.NilError(t, err)
+
+	dir := t.TempDir()
+	d := Draw{}
+	d.InitializeDrawStruct().SetHeatMap(true).SetFigureName("Heat map").SetOutputFileName("heatmap").
+		SetOutputTarget(OutputTarget{Dir: dir})
+	err = d.DrawSystemModel(sm)
+	assert.NilError(t, err)
+	assert.Assert(t, d.Rendered)
+
+	// heat map is stored in all formats by default
+	for _, format := range heatMapFormats {
+		info, err := os.Stat(filepath.Join(dir, "heatmap."+format))
+		assert.NilError(t, err)
+		t.Logf("Heat map in %s format has %d bytes", format, info.Size())
+		assert.Assert(t, info.Size() > 0)
+	}
+}
@@ -0,0 +1,280 @@
+// Package draw implements a set of helper functions to draw SystemModel. This file in particular implements layouts
+// of the SystemModel tree, i.e., placement of the nodes in the figure.
+package draw
+
+import (
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"gonum.org/v1/plot/plotter"
+	"math"
+)
+
+// Layout defines how the nodes of the SystemModel are placed in the figure
+type Layout uint
+
+const (
+	LayoutTidy    Layout = 0 // tidy tree (Walker's algorithm), children are centred under their parent
+	LayoutRadial  Layout = 1 // tidy tree wrapped around the root node, layers form concentric circles (suits deep models)
+	LayoutLayered Layout = 2 // nodes are spread evenly over the layer by their index, parents are ignored
+)
+
+// ParseLayout returns a layout with a given name (tidy, radial or layered)
+func ParseLayout(name string) (Layout, error) {
+	switch name {
+	case "tidy":
+		return LayoutTidy, nil
+	case "radial":
+		return LayoutRadial, nil
+	case "layered":
+		return LayoutLayered, nil
+	default:
+		return LayoutTidy, fmt.Errorf("unknown layout '%s', expected tidy, radial or layered", name)
+	}
+}
+
+// layoutNode structure holds a node of the tree together with the auxiliary values of Walker's algorithm
+// (as improved by Buchheim, Jünger and Leipert to run in linear time)
+type layoutNode struct {
+	instance *systemmodel.Instance
+	name     string
+	children []*layoutNode
+	parent   *layoutNode
+	number   int // order of the node among its siblings (starting from 1)
+	level    int // level of the layer, where the node resides
+	prelim   float64
+	mod      float64
+	shift    float64
+	change   float64
+	thread   *layoutNode
+	ancestor *layoutNode
+	x        float64 // resulting horizontal position
+}
+
+// nodeDistance is a distance between neighbouring nodes in the tidy tree
+const nodeDistance = 1.0
+
+// ConvertSystemModelToDrawStructWithLayout converts SystemModel to a plotter-friendly structure, which holds information
+// about coordinates of each node placed with regard to the provided layout
+func (ds *Coordinates) ConvertSystemModelToDrawStructWithLayout(sm *systemmodel.SystemModel, layout Layout) {
+	if layout == LayoutLayered {
+		ds.ConvertSystemModelToDrawStruct(sm)
+		return
+	}
+
+	root := buildLayoutTree(sm)
+	firstWalk(root)
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	// VIs deployed at once share the same name, thus positions are indexed by the instances
+	positions := make(map[*systemmodel.Instance]*layoutNode, sm.GetTotalNumberOfInstances())
+	secondWalk(root, -root.prelim, func(n *layoutNode) {
+		minX = math.Min(minX, n.x)
+		maxX = math.Max(maxX, n.x)
+		positions[n.instance] = n
+	})
+	ds.Extent = maxX - minX + nodeDistance
+
+	levels := len(sm.Layers)
+	paths := sm.InstancePaths()
+	ds.Points = make(map[string]*Coordinate, sm.GetTotalNumberOfInstances())
+	ds.Labels = plotter.XYLabels{}
+	for i := 1; i <= levels; i++ {
+		for j, v := range sm.Layers[i].Instances {
+			var data plotter.XYs
+			n, ok := positions[v]
+			switch {
+			case !ok:
+				// instance, which is not reachable from the root, is placed per layered layout
+				data = createPoints(i, levels, j+1, len(sm.Layers[i].Instances))
+			case layout == LayoutRadial:
+				data = createRadialPoints(n.x-minX, ds.Extent, n.level, levels)
+			default:
+				data = createTidyPoints(n.x-minX, ds.Extent, n.level, levels)
+			}
+			ds.Points[paths[v]] = &Coordinate{
+				Coordinates: data,
+			}
+			// adding labels to figure
+			ds.Labels.XYs = append(ds.Labels.XYs, data[0])
+			ds.Labels.Labels = append(ds.Labels.Labels, v.Name)
+		}
+	}
+}
+
+// createTidyPoints maps a position in the tidy tree to the same coordinate space as createPoints does
+// (X within (0, 200), root at the top with Y = 100)
+func createTidyPoints(x, extent float64, currentLevel, levels int) plotter.XYs {
+	data := make(plotter.XYs, 1)
+	data[0].X = (x + nodeDistance/2) * 200 / extent
+	data[0].Y = float64(levels-currentLevel+1) * 100 / float64(levels)
+	return data
+}
+
+// createRadialPoints maps a position in the tidy tree to the circle, whose radius corresponds to the level
+// of the layer. Root node is placed in the centre (100, 100).
+func createRadialPoints(x, extent float64, currentLevel, levels int) plotter.XYs {
+	data := make(plotter.XYs, 1)
+	data[0].X, data[0].Y = 100, 100
+	if levels > 1 {
+		angle := 2 * math.Pi * (x + nodeDistance/2) / extent
+		radius := float64(currentLevel-1) * 100 / float64(levels-1)
+		data[0].X += radius * math.Cos(angle)
+		data[0].Y += radius * math.Sin(angle)
+	}
+	return data
+}
+
+// buildLayoutTree converts SystemModel to a tree of layout nodes starting from the root instance
+func buildLayoutTree(sm *systemmodel.SystemModel) *layoutNode {
+	var build func(inst *systemmodel.Instance, parent *layoutNode, number, level int) *layoutNode
+	build = func(inst *systemmodel.Instance, parent *layoutNode, number, level int) *layoutNode {
+		n := &layoutNode{
+			instance: inst,
+			name:     inst.Name,
+			children: make([]*layoutNode, 0, len(inst.Relations)),
+			parent:   parent,
+			number:   number,
+			level:    level,
+		}
+		n.ancestor = n
+		for idx, rel := range inst.Relations {
+			n.children = append(n.children, build(rel, n, idx+1, level+1))
+		}
+		return n
+	}
+	return build(sm.Layers[1].Instances[0], nil, 1, 1)
+}
+
+// leftSibling returns the left sibling of the node, or nil
+func (n *layoutNode) leftSibling() *layoutNode {
+	if n.parent == nil || n.number == 1 {
+		return nil
+	}
+	return n.parent.children[n.number-2]
+}
+
+// leftmostSibling returns the leftmost sibling of the node, or nil, if the node is the leftmost one
+func (n *layoutNode) leftmostSibling() *layoutNode {
+	if n.parent == nil || n.number == 1 {
+		return nil
+	}
+	return n.parent.children[0]
+}
+
+// nextLeft returns the successor of the node on the left contour of its subtree
+func (n *layoutNode) nextLeft() *layoutNode {
+	if len(n.children) > 0 {
+		return n.children[0]
+	}
+	return n.thread
+}
+
+// nextRight returns the successor of the node on the right contour of its subtree
+func (n *layoutNode) nextRight() *layoutNode {
+	if len(n.children) > 0 {
+		return n.children[len(n.children)-1]
+	}
+	return n.thread
+}
+
+// firstWalk computes preliminary positions of the nodes bottom-up
+func firstWalk(v *layoutNode) {
+	if len(v.children) == 0 {
+		if w := v.leftSibling(); w != nil {
+			v.prelim = w.prelim + nodeDistance
+		}
+		return
+	}
+
+	defaultAncestor := v.children[0]
+	for _, w := range v.children {
+		firstWalk(w)
+		defaultAncestor = apportion(w, defaultAncestor)
+	}
+	executeShifts(v)
+	midpoint := (v.children[0].prelim + v.children[len(v.children)-1].prelim) / 2
+	if w := v.leftSibling(); w != nil {
+		v.prelim = w.prelim + nodeDistance
+		v.mod = v.prelim - midpoint
+	} else {
+		v.prelim = midpoint
+	}
+}
+
+// apportion places the subtree of the node next to the subtrees of its left siblings, so that they do not overlap
+func apportion(v, defaultAncestor *layoutNode) *layoutNode {
+	w := v.leftSibling()
+	if w == nil {
+		return defaultAncestor
+	}
+
+	vip, vop := v, v                   // inner and outer right contours
+	vim, vom := w, v.leftmostSibling() // inner and outer left contours
+	sip, sop := vip.mod, vop.mod
+	sim, som := vim.mod, vom.mod
+	for vim.nextRight() != nil && vip.nextLeft() != nil {
+		vim = vim.nextRight()
+		vip = vip.nextLeft()
+		vom = vom.nextLeft()
+		vop = vop.nextRight()
+		vop.ancestor = v
+		shift := (vim.prelim + sim) - (vip.prelim + sip) + nodeDistance
+		if shift > 0 {
+			moveSubtree(ancestorOf(vim, v, defaultAncestor), v, shift)
+			sip += shift
+			sop += shift
+		}
+		sim += vim.mod
+		sip += vip.mod
+		som += vom.mod
+		sop += vop.mod
+	}
+	if vim.nextRight() != nil && vop.nextRight() == nil {
+		vop.thread = vim.nextRight()
+		vop.mod += sim - sop
+	}
+	if vip.nextLeft() != nil && vom.nextLeft() == nil {
+		vom.thread = vip.nextLeft()
+		vom.mod += sip - som
+		defaultAncestor = v
+	}
+	return defaultAncestor
+}
+
+// moveSubtree shifts the subtree rooted in wp to the right and spreads the shift among the subtrees in between
+func moveSubtree(wm, wp *layoutNode, shift float64) {
+	subtrees := float64(wp.number - wm.number)
+	wp.change -= shift / subtrees
+	wp.shift += shift
+	wm.change += shift / subtrees
+	wp.prelim += shift
+	wp.mod += shift
+}
+
+// executeShifts applies shifts accumulated by moveSubtree to the children of the node
+func executeShifts(v *layoutNode) {
+	var shift, change float64
+	for i := len(v.children) - 1; i >= 0; i-- {
+		w := v.children[i]
+		w.prelim += shift
+		w.mod += shift
+		change += w.change
+		shift += w.shift + change
+	}
+}
+
+// ancestorOf returns the ancestor of vim, which is a sibling of v, or the default ancestor
+func ancestorOf(vim, v, defaultAncestor *layoutNode) *layoutNode {
+	if vim.ancestor.parent == v.parent {
+		return vim.ancestor
+	}
+	return defaultAncestor
+}
+
+// secondWalk computes final positions of the nodes top-down by summing up the modifiers
+func secondWalk(v *layoutNode, m float64, visit func(n *layoutNode)) {
+	v.x = v.prelim + m
+	visit(v)
+	for _, w := range v.children {
+		secondWalk(w, m+v.mod, visit)
+	}
+}
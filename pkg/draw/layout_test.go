@@ -0,0 +1,122 @@
+package draw
+
+import (
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"gotest.tools/assert"
+	"math"
+	"sort"
+	"testing"
+)
+
+// checkTidyTree checks that parents are centred above their children and that no nodes at the same level overlap,
+// both in the layout tree and in the drawn figure
+func checkTidyTree(t *testing.T, sm *systemmodel.SystemModel) {
+	root := buildLayoutTree(sm)
+	firstWalk(root)
+	perLevel := make(map[int][]float64, 0)
+	nodes := make([]*layoutNode, 0)
+	secondWalk(root, -root.prelim, func(n *layoutNode) {
+		perLevel[n.level] = append(perLevel[n.level], n.x)
+		nodes = append(nodes, n)
+	})
+	assert.Equal(t, int64(len(nodes)), sm.GetTotalNumberOfInstances())
+
+	for _, n := range nodes {
+		if len(n.children) == 0 {
+			continue
+		}
+		midpoint := (n.children[0].x + n.children[len(n.children)-1].x) / 2
+		assert.Assert(t, math.Abs(n.x-midpoint) < 1e-9, "node %s is not centred above its children", n.name)
+		// children keep their order
+		for i := 1; i < len(n.children); i++ {
+			assert.Assert(t, n.children[i].x-n.children[i-1].x >= nodeDistance-1e-9)
+		}
+	}
+	for level, xs := range perLevel {
+		sort.Float64s(xs)
+		for i := 1; i < len(xs); i++ {
+			assert.Assert(t, xs[i]-xs[i-1] >= nodeDistance-1e-9, "nodes at level %d overlap", level)
+		}
+	}
+
+	// each instance is drawn at its own position, even if it shares the name with its siblings
+	ds := Coordinates{}
+	ds.ConvertSystemModelToDrawStructWithLayout(sm, LayoutTidy)
+	assert.Equal(t, int64(len(ds.Points)), sm.GetTotalNumberOfInstances())
+	drawn := make(map[float64][]float64, 0)
+	for _, p := range ds.Points {
+		x, y := p.Coordinates.XY(0)
+		drawn[y] = append(drawn[y], x)
+	}
+	assert.Equal(t, len(drawn), len(perLevel))
+	minDistance := nodeDistance * 200 / ds.Extent
+	for y, xs := range drawn {
+		sort.Float64s(xs)
+		for i := 1; i < len(xs); i++ {
+			assert.Assert(t, xs[i]-xs[i-1] >= minDistance-1e-9, "drawn nodes at height %v overlap", y)
+		}
+	}
+	paths := sm.InstancePaths()
+	for _, layer := range sm.Layers {
+		for _, v := range layer.Instances {
+			parent, ok := ds.Points[paths[v]]
+			assert.Assert(t, ok, "instance %s is not drawn", paths[v])
+			if len(v.Relations) == 0 {
+				continue
+			}
+			first, _ := ds.Points[paths[v.Relations[0]]].Coordinates.XY(0)
+			last, _ := ds.Points[paths[v.Relations[len(v.Relations)-1]]].Coordinates.XY(0)
+			x, _ := parent.Coordinates.XY(0)
+			assert.Assert(t, math.Abs(x-(first+last)/2) < 1e-9, "drawn node %s is not centred above its children", paths[v])
+		}
+	}
+}
+
+func TestTidyLayout(t *testing.T) {
+	checkTidyTree(t, systemmodel.CreateSystemModelDepth4())
+	checkTidyTree(t, systemmodel.CreateExampleBasicFMAIS())
+
+	for i := 0; i < 10; i++ {
+		sm := &systemmodel.SystemModel{}
+		sm.InitializeSystemModel(10, 4)
+		sm.CreateRandomApplications(systemmodel.GenerateAppNames(10), 1, 5)
+		_, err := sm.GenerateSystemModel()
+		assert.NilError(t, err)
+		checkTidyTree(t, sm)
+	}
+}
+
+func TestConvertSystemModelToDrawStructWithLayout(t *testing.T) {
+	sm := systemmodel.CreateSystemModelDepth4()
+
+	for _, layout := range []Layout{LayoutTidy, LayoutRadial, LayoutLayered} {
+		ds := Coordinates{}
+		ds.ConvertSystemModelToDrawStructWithLayout(sm, layout)
+		assert.Equal(t, int64(len(ds.Points)), sm.GetTotalNumberOfInstances())
+		assert.Equal(t, len(ds.Labels.Labels), len(ds.Points))
+		assert.Assert(t, ds.Extent > 0)
+		for name, p := range ds.Points {
+			x, y := p.Coordinates.XY(0)
+			assert.Assert(t, x >= 0 && x <= 200 && y >= 0 && y <= 200, "node %s is out of bounds (%v, %v)", name, x, y)
+		}
+	}
+
+	// in the radial layout, root is in the centre and the nodes of each layer lay on a circle
+	ds := Coordinates{}
+	ds.ConvertSystemModelToDrawStructWithLayout(sm, LayoutRadial)
+	x, y := ds.Points["MAIS"].Coordinates.XY(0)
+	assert.Equal(t, x, 100.0)
+	assert.Equal(t, y, 100.0)
+	paths := sm.InstancePaths()
+	for _, inst := range sm.Layers[3].Instances {
+		x, y = ds.Points[paths[inst]].Coordinates.XY(0)
+		assert.Assert(t, math.Abs(math.Hypot(x-100, y-100)-200.0/3) < 1e-9)
+	}
+
+	// in the tidy layout, VI#3-3 is placed right above its only child
+	ds = Coordinates{}
+	ds.ConvertSystemModelToDrawStructWithLayout(sm, LayoutTidy)
+	x3, _ := ds.Points["MAIS/VI#2-2/VI#3-3"].Coordinates.XY(0)
+	x4, _ := ds.Points["MAIS/VI#2-2/VI#3-3/App#4-4-1"].Coordinates.XY(0)
+	assert.Equal(t, x3, x4)
+}
@@ -0,0 +1,83 @@
+// Package draw implements a set of helper functions to draw SystemModel. This file in particular implements plotting
+// of the memory complexity, i.e., memory metrics of the benchmarked call with regard to the parameters of the FMAIS.
+package draw
+
+import (
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/sweep"
+	"strings"
+)
+
+// memoryFigure describes how a memory metric is shown in the figure
+type memoryFigure struct {
+	name  string  // name of the metric used in the figure name and in the file name
+	label string  // label of the Y-axis
+	scale float64 // conversion of the stored value to the unit of the Y-axis
+}
+
+// memoryFigures describe figures of each memory metric
+var memoryFigures = map[string]memoryFigure{
+	storedata.MemoryAllocatedBytes: {name: "Allocated memory", label: "Allocated memory [MB]", scale: 1.0 / 1024 / 1024},
+	storedata.MemoryAllocations:    {name: "Allocations", label: "Allocations [-]", scale: 1},
+	storedata.MemoryPeakHeap:       {name: "Peak heap", label: "Peak heap [MB]", scale: 1.0 / 1024 / 1024},
+	storedata.MemoryRetainedHeap:   {name: "Retained heap", label: "Retained heap [MB]", scale: 1.0 / 1024 / 1024},
+}
+
+// PlotMemoryComplexities plots memory metrics stored in the result document. For each metric, a dependency on the
+// number of applications (with the greatest number of instances per application) and on the number of instances per
+// application (with the greatest number of applications) is plotted for representative depths of the FMAIS.
+func PlotMemoryComplexities(result *storedata.ResultDocument, spec sweep.Spec, prefix string, greyScale bool) error {
+	if !result.HasMemory() {
+		return fmt.Errorf("memory metrics are not stored in the result document %s", result.Name)
+	}
+	for _, metric := range storedata.MemoryMetrics {
+		data, err := result.MemoryData(metric)
+		if err != nil {
+			return err
+		}
+		s := spec.Intersect(sweep.FromData(data))
+		if err := s.Validate(); err != nil {
+			return fmt.Errorf("benchmarked data don't cover the parameter grid: %w", err)
+		}
+		mf := memoryFigures[metric]
+		// lines are extracted in the same way as for the time complexity, i.e., values are divided by 1000
+		data = scaleData(data, mf.scale*1000)
+		fileName := strings.ToLower(prefix) + "_memory-complexity-" + strings.ReplaceAll(strings.ToLower(mf.name), " ", "-")
+		figureName := fmt.Sprintf("%s %s\nDependency ", prefix, mf.name)
+
+		d := Draw{}
+		d.InitializeDrawStruct()
+		d.SetOutputFileName(fmt.Sprintf("%s-apps-number-%d-inst", fileName, s.Instances.Max())).
+			SetFigureName(figureName + "on the App number").SetYaxisName(mf.label).SetXaxisName("Number of Applications [-]")
+		lines := getLinesForAppNumber(data, s.Depths.Pick(3), s.Apps, []int{s.Instances.Max()})
+		err = d.plotTimeComplexity(lines, greyScale, false, false, false, false)
+		if err != nil {
+			return err
+		}
+
+		d.SetOutputFileName(fmt.Sprintf("%s-instances-per-app-%d-apps", fileName, s.Apps.Max())).
+			SetFigureName(figureName + "on instances per App").SetXaxisName("Instances (per App) [-]")
+		lines = getLinesForInstances(data, s.Depths.Pick(3), []int{s.Apps.Max()}, s.Instances)
+		err = d.plotTimeComplexity(lines, greyScale, false, false, false, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scaleData returns a copy of the data cube with all values multiplied by a factor
+func scaleData(data map[int]map[int]map[int]float64, factor float64) map[int]map[int]map[int]float64 {
+	out := make(map[int]map[int]map[int]float64, len(data))
+	for d, m1 := range data {
+		out[d] = make(map[int]map[int]float64, len(m1))
+		for a, m2 := range m1 {
+			out[d][a] = make(map[int]float64, len(m2))
+			for i, v := range m2 {
+				out[d][a][i] = v * factor
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,145 @@
+// Package draw implements a set of helper functions to draw SystemModel. This file in particular implements an output
+// target of the figures, i.e., a directory, where figures are stored, their formats and resolution.
+package draw
+
+import (
+	"fmt"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	vgdraw "gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// supportedFormats are formats, in which the figures can be stored
+var supportedFormats = []string{"png", "svg", "eps", "pdf", "tex"}
+
+// OutputTarget structure describes where and how the figures are stored
+type OutputTarget struct {
+	Dir     string   // directory, where the figures are stored (it is created, if it doesn't exist)
+	Formats []string // formats of the figures, if empty, each figure is stored in its default formats
+	DPI     int      // resolution of the raster (PNG) figures, if zero, default resolution of the plotter is used
+}
+
+// defaultTarget is an output target used by all newly initialized Draw structures
+var defaultTarget = OutputTarget{
+	Dir: "figures/",
+}
+
+// SetDefaultOutputTarget sets an output target, which is used by all figures drawn afterwards
+func SetDefaultOutputTarget(target OutputTarget) error {
+	err := target.Validate()
+	if err != nil {
+		return err
+	}
+	defaultTarget = target
+	return nil
+}
+
+// GetDefaultOutputTarget returns an output target, which is used by all newly drawn figures
+func GetDefaultOutputTarget() OutputTarget {
+	return defaultTarget
+}
+
+// Validate checks that the output target defines a directory, supported formats and a non-negative DPI
+func (t OutputTarget) Validate() error {
+	if t.Dir == "" {
+		return fmt.Errorf("output directory is not specified")
+	}
+	for _, f := range t.Formats {
+		if !isSupportedFormat(f) {
+			return fmt.Errorf("unsupported figure format '%s', expected one of %s", f,
+				strings.Join(supportedFormats, ", "))
+		}
+	}
+	if t.DPI < 0 {
+		return fmt.Errorf("DPI should be non-negative, got %d", t.DPI)
+	}
+	return nil
+}
+
+// ParseFormats parses a list of formats, which may be separated by commas (e.g., "png,svg"), and checks they are supported
+func ParseFormats(formats ...string) ([]string, error) {
+	out := make([]string, 0, len(formats))
+	for _, f := range formats {
+		for _, format := range strings.Split(f, ",") {
+			format = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(format), "."))
+			if format == "" {
+				continue
+			}
+			if !isSupportedFormat(format) {
+				return nil, fmt.Errorf("unsupported figure format '%s', expected one of %s", format,
+					strings.Join(supportedFormats, ", "))
+			}
+			out = append(out, format)
+		}
+	}
+	return out, nil
+}
+
+// SetOutputTarget sets an output target of the figure
+func (d *Draw) SetOutputTarget(target OutputTarget) *Draw {
+	d.target = target
+	return d
+}
+
+// save stores the plot in all formats of the output target, or in the provided default formats,
+// if the output target doesn't specify any
+func (d *Draw) save(p *plot.Plot, defaultFormats ...string) error {
+	return d.target.write(d.OutputFileName, d.XLength, d.YLength, p.Draw, defaultFormats...)
+}
+
+// write draws the figure on a canvas of each format and stores it in the output directory
+func (t OutputTarget) write(name string, width, height vg.Length, draw func(c vgdraw.Canvas), defaultFormats ...string) error {
+	formats := t.Formats
+	if len(formats) == 0 {
+		formats = defaultFormats
+	}
+	err := os.MkdirAll(t.Dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	for _, format := range formats {
+		c, err := t.newCanvas(width, height, format)
+		if err != nil {
+			return err
+		}
+		draw(vgdraw.New(c))
+
+		f, err := os.Create(filepath.Join(t.Dir, name+"."+format))
+		if err != nil {
+			return err
+		}
+		_, err = c.WriteTo(f)
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
+		err = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newCanvas creates a canvas of a given format. DPI is applied to the PNG canvas only.
+func (t OutputTarget) newCanvas(width, height vg.Length, format string) (vg.CanvasWriterTo, error) {
+	if format == "png" && t.DPI > 0 {
+		return vgimg.PngCanvas{Canvas: vgimg.NewWith(vgimg.UseWH(width, height), vgimg.UseDPI(t.DPI))}, nil
+	}
+	return vgdraw.NewFormattedCanvas(width, height, format)
+}
+
+// isSupportedFormat checks whether the figure can be stored in a given format
+func isSupportedFormat(format string) bool {
+	for _, f := range supportedFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package draw
+
+import (
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gotest.tools/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFormats(t *testing.T) {
+	formats, err := ParseFormats("png,SVG", " .pdf ", "")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, formats, []string{"png", "svg", "pdf"})
+
+	_, err = ParseFormats("png,gif")
+	assert.ErrorContains(t, err, "unsupported figure format 'gif'")
+}
+
+func TestOutputTargetValidate(t *testing.T) {
+	assert.NilError(t, OutputTarget{Dir: "figures/", Formats: []string{"eps", "tex"}, DPI: 300}.Validate())
+	assert.ErrorContains(t, OutputTarget{}.Validate(), "output directory is not specified")
+	assert.ErrorContains(t, OutputTarget{Dir: "figures/", Formats: []string{"bmp"}}.Validate(), "unsupported figure format")
+	assert.ErrorContains(t, OutputTarget{Dir: "figures/", DPI: -1}.Validate(), "DPI should be non-negative")
+
+	// invalid output target is not set
+	err := SetDefaultOutputTarget(OutputTarget{Dir: "figures/", Formats: []string{"bmp"}})
+	assert.Assert(t, err != nil)
+	assert.Equal(t, GetDefaultOutputTarget().Dir, "figures/")
+}
+
+func TestOutputTargetWrite(t *testing.T) {
+	p := plot.New()
+	p.Title.Text = "Output target"
+	line, err := plotter.NewLine(plotter.XYs{{X: 0, Y: 0}, {X: 1, Y: 1}})
+	assert.NilError(t, err)
+	p.Add(line)
+
+	// directory is created, if it doesn't exist, default formats are used, when none are specified
+	dir := filepath.Join(t.TempDir(), "nested", "figures")
+	d := Draw{}
+	d.InitializeDrawStruct().SetOutputFileName("lowres").SetXLength(5 * vg.Centimeter).SetYLength(5 * vg.Centimeter).
+		SetOutputTarget(OutputTarget{Dir: dir, DPI: 50})
+	err = d.save(p, "png", "eps")
+	assert.NilError(t, err)
+	lowres, err := os.Stat(filepath.Join(dir, "lowres.png"))
+	assert.NilError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "lowres.eps"))
+	assert.NilError(t, err)
+
+	// formats of the output target override the default ones, higher DPI produces a larger raster figure
+	d.SetOutputFileName("highres").SetOutputTarget(OutputTarget{Dir: dir, Formats: []string{"png", "svg", "tex"}, DPI: 300})
+	err = d.save(p, "eps")
+	assert.NilError(t, err)
+	for _, format := range []string{"png", "svg", "tex"} {
+		_, err = os.Stat(filepath.Join(dir, "highres."+format))
+		assert.NilError(t, err)
+	}
+	_, err = os.Stat(filepath.Join(dir, "highres.eps"))
+	assert.Assert(t, os.IsNotExist(err))
+	highres, err := os.Stat(filepath.Join(dir, "highres.png"))
+	assert.NilError(t, err)
+	t.Logf("PNG figure has %d bytes at 50 DPI and %d bytes at 300 DPI", lowres.Size(), highres.Size())
+	assert.Assert(t, highres.Size() > lowres.Size())
+}
@@ -0,0 +1,350 @@
+// Package fitting implements fitting of the exponential, polynomial and power-law models to the benchmarked
+// dependencies (e.g., time on the depth of the FMAIS). Fitted models report their coefficients and the coefficient
+// of determination (R²) and they can be used to extrapolate the measured quantity beyond the benchmarked range.
+package fitting
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Kind is a kind of the fitted model
+type Kind string
+
+// kinds of the supported models
+const (
+	Exponential Kind = "exponential" // y = a * exp(b * x)
+	Polynomial  Kind = "polynomial"  // y = c0 + c1 * x + ... + cn * x^n
+	PowerLaw    Kind = "power-law"   // y = a * x^b
+)
+
+// Best is not a model, it selects the model with the greatest R² out of all candidates (see FitBest)
+const Best Kind = "best"
+
+// Model structure holds a fitted model
+type Model struct {
+	Kind   Kind `json:"kind"`
+	Degree int  `json:"degree,omitempty"` // degree of the polynomial
+	// Coefficients are a and b of the exponential and power-law model, and c0, c1, ..., cn of the polynomial
+	Coefficients []float64 `json:"coefficients"`
+	R2           float64   `json:"r2"` // coefficient of determination computed on the original (not logarithmic) scale
+}
+
+// Predict returns a value of the model in x
+func (m Model) Predict(x float64) float64 {
+	switch m.Kind {
+	case Exponential:
+		return m.Coefficients[0] * math.Exp(m.Coefficients[1]*x)
+	case PowerLaw:
+		return m.Coefficients[0] * math.Pow(x, m.Coefficients[1])
+	case Polynomial:
+		// Horner's method
+		y := 0.0
+		for i := len(m.Coefficients) - 1; i >= 0; i-- {
+			y = y*x + m.Coefficients[i]
+		}
+		return y
+	}
+	return math.NaN()
+}
+
+// String returns an equation of the model
+func (m Model) String() string {
+	switch m.Kind {
+	case Exponential:
+		return fmt.Sprintf("%.4g * exp(%.4g * x)", m.Coefficients[0], m.Coefficients[1])
+	case PowerLaw:
+		return fmt.Sprintf("%.4g * x^%.4g", m.Coefficients[0], m.Coefficients[1])
+	case Polynomial:
+		terms := make([]string, 0, len(m.Coefficients))
+		for i := len(m.Coefficients) - 1; i >= 0; i-- {
+			switch i {
+			case 0:
+				terms = append(terms, fmt.Sprintf("%.4g", m.Coefficients[i]))
+			case 1:
+				terms = append(terms, fmt.Sprintf("%.4g * x", m.Coefficients[i]))
+			default:
+				terms = append(terms, fmt.Sprintf("%.4g * x^%d", m.Coefficients[i], i))
+			}
+		}
+		return strings.Join(terms, " + ")
+	}
+	return string(m.Kind)
+}
+
+// Extrapolate returns values of the model in the provided points (e.g., depths beyond the benchmarked ones)
+func (m Model) Extrapolate(xs ...int) map[int]float64 {
+	values := make(map[int]float64, len(xs))
+	for _, x := range xs {
+		values[x] = m.Predict(float64(x))
+	}
+	return values
+}
+
+// SliceFit structure holds a model fitted to a single slice of the benchmarked data
+type SliceFit struct {
+	Variable     string          `json:"variable"` // benchmark parameter on the X-axis (depth, apps or instances)
+	Slice        string          `json:"slice"`    // description of the slice, i.e., of the fixed parameters
+	Model        Model           `json:"model"`
+	Extrapolated map[int]float64 `json:"extrapolated,omitempty"`
+}
+
+// WriteTable writes a text table of the fitted slices. Extrapolated values are listed after the table.
+func WriteTable(w io.Writer, fits []SliceFit) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Variable\tSlice\tModel\tEquation\tR²\t")
+	for _, f := range fits {
+		name := string(f.Model.Kind)
+		if f.Model.Kind == Polynomial {
+			name = fmt.Sprintf("%s%d", name, f.Model.Degree)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.4f\t\n", f.Variable, f.Slice, name, f.Model, f.Model.R2)
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fits {
+		if len(f.Extrapolated) == 0 {
+			continue
+		}
+		xs := make([]int, 0, len(f.Extrapolated))
+		for x := range f.Extrapolated {
+			xs = append(xs, x)
+		}
+		sort.Ints(xs)
+		values := make([]string, 0, len(xs))
+		for _, x := range xs {
+			values = append(values, fmt.Sprintf("%s %d: %.4g", f.Variable, x, f.Extrapolated[x]))
+		}
+		fmt.Fprintf(w, "Extrapolated %s: %s\n", f.Slice, strings.Join(values, ", "))
+	}
+	return nil
+}
+
+// ParseModel parses a model given by its kind (exponential, power-law or best) or by a polynomial degree
+// (e.g., polynomial2, or polynomial, which is of the second degree)
+func ParseModel(s string) (Kind, int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case s == string(Exponential) || s == string(PowerLaw) || s == string(Best):
+		return Kind(s), 0, nil
+	case s == string(Polynomial):
+		return Polynomial, 2, nil
+	case strings.HasPrefix(s, string(Polynomial)):
+		degree, err := strconv.Atoi(strings.TrimPrefix(s, string(Polynomial)))
+		if err != nil || degree < 1 {
+			return "", 0, fmt.Errorf("can't parse degree of the polynomial model %s", s)
+		}
+		return Polynomial, degree, nil
+	}
+	return "", 0, fmt.Errorf("unknown model %s, expected one of exponential, polynomial[degree], power-law or best", s)
+}
+
+// Fit fits a model of a given kind (degree is used by the polynomial model only) to the data points
+func Fit(kind Kind, degree int, xs, ys []float64) (Model, error) {
+	switch kind {
+	case Exponential:
+		return FitExponential(xs, ys)
+	case PowerLaw:
+		return FitPowerLaw(xs, ys)
+	case Polynomial:
+		return FitPolynomial(xs, ys, degree)
+	case Best:
+		return FitBest(xs, ys)
+	}
+	return Model{}, fmt.Errorf("unknown model %s", kind)
+}
+
+// FitExponential fits y = a * exp(b * x) with the least squares of ln(y), so all y should be positive
+func FitExponential(xs, ys []float64) (Model, error) {
+	if err := checkPoints(xs, ys, 2); err != nil {
+		return Model{}, err
+	}
+	logYs, err := logarithms(ys)
+	if err != nil {
+		return Model{}, fmt.Errorf("exponential model: %w", err)
+	}
+	c, err := leastSquares(xs, logYs, 1)
+	if err != nil {
+		return Model{}, fmt.Errorf("exponential model: %w", err)
+	}
+	m := Model{Kind: Exponential, Coefficients: []float64{math.Exp(c[0]), c[1]}}
+	m.R2 = rSquared(m, xs, ys)
+	return m, nil
+}
+
+// FitPowerLaw fits y = a * x^b with the least squares of ln(y) on ln(x), so all x and y should be positive
+func FitPowerLaw(xs, ys []float64) (Model, error) {
+	if err := checkPoints(xs, ys, 2); err != nil {
+		return Model{}, err
+	}
+	logXs, err := logarithms(xs)
+	if err != nil {
+		return Model{}, fmt.Errorf("power-law model: %w", err)
+	}
+	logYs, err := logarithms(ys)
+	if err != nil {
+		return Model{}, fmt.Errorf("power-law model: %w", err)
+	}
+	c, err := leastSquares(logXs, logYs, 1)
+	if err != nil {
+		return Model{}, fmt.Errorf("power-law model: %w", err)
+	}
+	m := Model{Kind: PowerLaw, Coefficients: []float64{math.Exp(c[0]), c[1]}}
+	m.R2 = rSquared(m, xs, ys)
+	return m, nil
+}
+
+// FitPolynomial fits a polynomial of a given degree with the least squares
+func FitPolynomial(xs, ys []float64, degree int) (Model, error) {
+	if degree < 1 {
+		return Model{}, fmt.Errorf("degree of the polynomial should be positive, got %d", degree)
+	}
+	if err := checkPoints(xs, ys, degree+1); err != nil {
+		return Model{}, err
+	}
+	c, err := leastSquares(xs, ys, degree)
+	if err != nil {
+		return Model{}, fmt.Errorf("polynomial model: %w", err)
+	}
+	m := Model{Kind: Polynomial, Degree: degree, Coefficients: c}
+	m.R2 = rSquared(m, xs, ys)
+	return m, nil
+}
+
+// FitAll fits all candidate models (exponential, power-law and polynomials of the first and the second degree),
+// which are applicable to the data points, and returns them ordered by R² (the best one first)
+func FitAll(xs, ys []float64) []Model {
+	models := make([]Model, 0, 4)
+	for _, fit := range []func() (Model, error){
+		func() (Model, error) { return FitExponential(xs, ys) },
+		func() (Model, error) { return FitPowerLaw(xs, ys) },
+		func() (Model, error) { return FitPolynomial(xs, ys, 1) },
+		func() (Model, error) { return FitPolynomial(xs, ys, 2) },
+	} {
+		m, err := fit()
+		if err == nil && !math.IsNaN(m.R2) {
+			models = append(models, m)
+		}
+	}
+	sort.SliceStable(models, func(i, j int) bool {
+		return models[i].R2 > models[j].R2
+	})
+	return models
+}
+
+// FitBest returns the candidate model with the greatest R² (see FitAll)
+func FitBest(xs, ys []float64) (Model, error) {
+	models := FitAll(xs, ys)
+	if len(models) == 0 {
+		if err := checkPoints(xs, ys, 2); err != nil {
+			return Model{}, err
+		}
+		return Model{}, fmt.Errorf("none of the models is applicable to the data points")
+	}
+	return models[0], nil
+}
+
+// checkPoints checks that there are enough data points to fit a model with a given number of coefficients
+func checkPoints(xs, ys []float64, coefficients int) error {
+	if len(xs) != len(ys) {
+		return fmt.Errorf("number of x (%d) and y (%d) values differ", len(xs), len(ys))
+	}
+	if len(xs) < coefficients {
+		return fmt.Errorf("at least %d data points are needed, got %d", coefficients, len(xs))
+	}
+	return nil
+}
+
+// logarithms returns natural logarithms of the values, which should be positive
+func logarithms(values []float64) ([]float64, error) {
+	logs := make([]float64, len(values))
+	for i, v := range values {
+		if v <= 0 {
+			return nil, fmt.Errorf("values should be positive, got %v", v)
+		}
+		logs[i] = math.Log(v)
+	}
+	return logs, nil
+}
+
+// rSquared returns the coefficient of determination of the model on the data points
+func rSquared(m Model, xs, ys []float64) float64 {
+	mean := 0.0
+	for _, y := range ys {
+		mean += y
+	}
+	mean /= float64(len(ys))
+	var ssRes, ssTot float64
+	for i, x := range xs {
+		r := ys[i] - m.Predict(x)
+		ssRes += r * r
+		ssTot += (ys[i] - mean) * (ys[i] - mean)
+	}
+	if ssTot == 0 {
+		if ssRes == 0 {
+			return 1
+		}
+		return math.NaN()
+	}
+	return 1 - ssRes/ssTot
+}
+
+// leastSquares returns coefficients c0, ..., cn of the polynomial of degree n minimizing the sum of squared residuals.
+// Normal equations are solved with Gaussian elimination with partial pivoting.
+func leastSquares(xs, ys []float64, degree int) ([]float64, error) {
+	n := degree + 1
+	// augmented matrix of the normal equations
+	a := make([][]float64, n)
+	for i := range a {
+		a[i] = make([]float64, n+1)
+	}
+	for k, x := range xs {
+		powers := make([]float64, 2*n-1)
+		powers[0] = 1
+		for p := 1; p < len(powers); p++ {
+			powers[p] = powers[p-1] * x
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				a[i][j] += powers[i+j]
+			}
+			a[i][n] += powers[i] * ys[k]
+		}
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12*math.Max(1, math.Abs(a[0][0])) {
+			return nil, fmt.Errorf("data points don't determine the model (e.g., all x values are the same)")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		for row := col + 1; row < n; row++ {
+			f := a[row][col] / a[col][col]
+			for j := col; j <= n; j++ {
+				a[row][j] -= f * a[col][j]
+			}
+		}
+	}
+	c := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		s := a[i][n]
+		for j := i + 1; j < n; j++ {
+			s -= a[i][j] * c[j]
+		}
+		c[i] = s / a[i][i]
+	}
+	return c, nil
+}
@@ -0,0 +1,113 @@
+package fitting
+
+import (
+	"bytes"
+	"gotest.tools/assert"
+	"math"
+	"testing"
+)
+
+func TestFitExponential(t *testing.T) {
+	xs := []float64{1, 2, 3, 4}
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = 0.5 * math.Exp(1.5*x)
+	}
+	m, err := FitExponential(xs, ys)
+	assert.NilError(t, err)
+	t.Logf("Fitted %s with R² %v", m, m.R2)
+	assert.Assert(t, math.Abs(m.Coefficients[0]-0.5) < 1e-9)
+	assert.Assert(t, math.Abs(m.Coefficients[1]-1.5) < 1e-9)
+	assert.Assert(t, math.Abs(m.R2-1) < 1e-9)
+	assert.Assert(t, math.Abs(m.Extrapolate(6)[6]-0.5*math.Exp(9)) < 1e-6)
+
+	// exponential model requires positive values
+	_, err = FitExponential([]float64{1, 2}, []float64{0, 1})
+	assert.ErrorContains(t, err, "should be positive")
+	_, err = FitExponential([]float64{1}, []float64{1})
+	assert.ErrorContains(t, err, "at least 2 data points")
+}
+
+func TestFitPolynomial(t *testing.T) {
+	xs := []float64{1, 6, 11, 16, 21, 26}
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = 2 + 0.3*x + 0.05*x*x
+	}
+	m, err := FitPolynomial(xs, ys, 2)
+	assert.NilError(t, err)
+	t.Logf("Fitted %s with R² %v", m, m.R2)
+	for i, c := range []float64{2, 0.3, 0.05} {
+		assert.Assert(t, math.Abs(m.Coefficients[i]-c) < 1e-6)
+	}
+	assert.Assert(t, math.Abs(m.R2-1) < 1e-9)
+	assert.Equal(t, m.Degree, 2)
+
+	_, err = FitPolynomial(xs[:2], ys[:2], 2)
+	assert.ErrorContains(t, err, "at least 3 data points")
+	_, err = FitPolynomial([]float64{1, 1, 1}, []float64{1, 2, 3}, 1)
+	assert.ErrorContains(t, err, "don't determine the model")
+}
+
+func TestFitPowerLaw(t *testing.T) {
+	xs := []float64{1, 2, 4, 8, 16}
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = 3 * math.Pow(x, 1.7)
+	}
+	m, err := FitPowerLaw(xs, ys)
+	assert.NilError(t, err)
+	t.Logf("Fitted %s with R² %v", m, m.R2)
+	assert.Assert(t, math.Abs(m.Coefficients[0]-3) < 1e-9)
+	assert.Assert(t, math.Abs(m.Coefficients[1]-1.7) < 1e-9)
+}
+
+func TestFitBest(t *testing.T) {
+	xs := []float64{1, 2, 3, 4, 5}
+	ys := []float64{0.11, 0.3, 0.79, 2.2, 5.9}
+	models := FitAll(xs, ys)
+	assert.Equal(t, len(models), 4)
+	for i := 1; i < len(models); i++ {
+		assert.Assert(t, models[i-1].R2 >= models[i].R2)
+	}
+	m, err := FitBest(xs, ys)
+	assert.NilError(t, err)
+	assert.Equal(t, m.Kind, Exponential)
+
+	// only polynomials are applicable to non-positive values
+	m, err = Fit(Best, 0, []float64{0, 1, 2}, []float64{0, 1, 2})
+	assert.NilError(t, err)
+	assert.Equal(t, m.Kind, Polynomial)
+}
+
+func TestParseModel(t *testing.T) {
+	for s, expected := range map[string]struct {
+		kind   Kind
+		degree int
+	}{
+		"exponential": {Exponential, 0},
+		"Power-Law":   {PowerLaw, 0},
+		"polynomial":  {Polynomial, 2},
+		"polynomial3": {Polynomial, 3},
+		"best":        {Best, 0},
+	} {
+		kind, degree, err := ParseModel(s)
+		assert.NilError(t, err)
+		assert.Equal(t, kind, expected.kind)
+		assert.Equal(t, degree, expected.degree)
+	}
+	for _, s := range []string{"linear", "polynomial0", "polynomialx"} {
+		_, _, err := ParseModel(s)
+		assert.Assert(t, err != nil, s)
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	m, err := FitExponential([]float64{1, 2, 3}, []float64{1, 2, 4})
+	assert.NilError(t, err)
+	var b bytes.Buffer
+	err = WriteTable(&b, []SliceFit{{Variable: "depth", Slice: "6 Apps", Model: m, Extrapolated: m.Extrapolate(5, 4)}})
+	assert.NilError(t, err)
+	t.Logf("\n%s", b.String())
+	assert.Assert(t, bytes.Contains(b.Bytes(), []byte("Extrapolated 6 Apps: depth 4: 8, depth 5: 16")))
+}
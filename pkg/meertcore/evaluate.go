@@ -0,0 +1,107 @@
+// Package meertcore implements ME-ERT-CORE reliability model. This file in particular implements evaluation of a given
+// System Model with a chosen ME-ERT-CORE method, which reports reliability of the system and each of its Applications.
+package meertcore
+
+import (
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Methods of the ME-ERT-CORE reliability computation
+const (
+	MethodPerDefinition = "per-definition" // canonical ME-ERT-CORE, see ComputeReliabilityPerDefinition()
+	MethodOptimized     = "optimized"      // optimized ME-ERT-CORE, see ComputeReliabilityOptimized()
+)
+
+// Evaluation holds results of the ME-ERT-CORE evaluation of a System Model
+type Evaluation struct {
+	Method       string                  `json:"method"`       // ME-ERT-CORE method used to compute reliability
+	Reliability  float64                 `json:"reliability"`  // reliability of the System Model
+	Applications []ApplicationEvaluation `json:"applications"` // reliabilities of the Applications, sorted by name
+}
+
+// ApplicationEvaluation holds reliability and chain coefficient of a single Application
+type ApplicationEvaluation struct {
+	Name             string  `json:"name"`             // name of the Application
+	Reliability      float64 `json:"reliability"`      // reliability of the Application gathered from its instances
+	ChainCoefficient float64 `json:"chainCoefficient"` // chain coefficient of the Application
+}
+
+// ParseMethod checks that the ME-ERT-CORE method is known
+func ParseMethod(method string) (string, error) {
+	switch method {
+	case MethodPerDefinition, MethodOptimized:
+		return method, nil
+	default:
+		return "", fmt.Errorf("unknown ME-ERT-CORE method %q (expected %s or %s)", method, MethodPerDefinition, MethodOptimized)
+	}
+}
+
+// Evaluate computes reliability of the System Model with a given ME-ERT-CORE method together with reliabilities and
+// chain coefficients of each deployed Application. Provided System Model is left intact.
+func Evaluate(sm *systemmodel.SystemModel, method string) (*Evaluation, error) {
+	method, err := ParseMethod(method)
+	if err != nil {
+		return nil, err
+	}
+
+	// chain coefficients and Application reliabilities are reported for both methods,
+	// the optimized method uses them to compute the reliability
+	model := sm.Clone()
+	err = model.SetChainCoefficients()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't set chain coefficients: %w", err)
+	}
+	appRel, err := model.GatherAllApplicationsReliabilities()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't gather reliabilities of the applications: %w", err)
+	}
+
+	evaluation := &Evaluation{
+		Method:       method,
+		Applications: make([]ApplicationEvaluation, 0, len(appRel)),
+	}
+	for name, rel := range appRel {
+		cc, err := model.Applications[name].GetChainCoefficient()
+		if err != nil {
+			return nil, fmt.Errorf("application %s: %w", name, err)
+		}
+		evaluation.Applications = append(evaluation.Applications, ApplicationEvaluation{
+			Name:             name,
+			Reliability:      rel,
+			ChainCoefficient: cc,
+		})
+	}
+	sort.Slice(evaluation.Applications, func(i, j int) bool {
+		return evaluation.Applications[i].Name < evaluation.Applications[j].Name
+	})
+
+	switch method {
+	case MethodPerDefinition:
+		// computation per definition overwrites reliabilities of VIs, thus it works on its own copy
+		me := MeErtCore{SystemModel: sm.Clone()}
+		evaluation.Reliability, err = me.ComputeReliabilityPerDefinition()
+	case MethodOptimized:
+		me := MeErtCore{SystemModel: model}
+		evaluation.Reliability, err = me.ComputeReliabilityOptimized()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't compute reliability (%s): %w", method, err)
+	}
+
+	return evaluation, nil
+}
+
+// WriteTable writes the evaluation as a human-readable table
+func (e *Evaluation) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "System reliability (%s): %.6f\n", e.Method, e.Reliability)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Application\tReliability\tChain coefficient\t")
+	for _, app := range e.Applications {
+		fmt.Fprintf(tw, "%s\t%.6f\t%.6f\t\n", app.Name, app.Reliability, app.ChainCoefficient)
+	}
+	return tw.Flush()
+}
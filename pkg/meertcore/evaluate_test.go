@@ -0,0 +1,40 @@
+package meertcore
+
+import (
+	"bytes"
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	sm := systemmodel.CreateSystemModelDepth4()
+
+	perDefinition, err := Evaluate(sm, MethodPerDefinition)
+	assert.NilError(t, err)
+	optimized, err := Evaluate(sm, MethodOptimized)
+	assert.NilError(t, err)
+	t.Logf("Reliability per definition is %v, optimized is %v", perDefinition.Reliability, optimized.Reliability)
+	assert.Equal(t, fmt.Sprintf("%.12f", perDefinition.Reliability), fmt.Sprintf("%.12f", optimized.Reliability))
+
+	// applications are reported sorted by name, VI is not an application
+	assert.Equal(t, len(perDefinition.Applications), 4)
+	assert.Equal(t, perDefinition.Applications[0].Name, "App#1")
+	assert.DeepEqual(t, perDefinition.Applications, optimized.Applications)
+	for _, app := range perDefinition.Applications {
+		assert.Assert(t, app.ChainCoefficient > 0 && app.ChainCoefficient <= 1, app.Name)
+	}
+
+	// provided System Model is left intact
+	_, err = sm.Applications["App#1"].GetChainCoefficient()
+	assert.ErrorContains(t, err, "ChainCoefficient aspect")
+
+	var out bytes.Buffer
+	assert.NilError(t, perDefinition.WriteTable(&out))
+	t.Logf("Evaluation is\n%s", out.String())
+	assert.Assert(t, bytes.Contains(out.Bytes(), []byte("App#4")))
+
+	_, err = Evaluate(sm, "fast")
+	assert.ErrorContains(t, err, "unknown ME-ERT-CORE method")
+}
@@ -2,24 +2,36 @@
 package meertcore
 
 import (
+	"context"
 	"fmt"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
 )
 
 // MeErtCore structure represents an ME-ERT-CORE instance reliability
 type MeErtCore struct {
-	SystemModel *systemmodel.SystemModel // holds System Model definition
-	Reliability float64                  // contains Reliability of the System Model
+	SystemModel   *systemmodel.SystemModel // holds System Model definition
+	Reliability   float64                  // contains Reliability of the System Model
+	Distributions map[string]*Distribution // optional: holds reliability distributions of leaf instances (key is an instance path, or name)
 }
 
 // ComputeReliabilityPerDefinition computes reliability of Fractal MAIS (i.e., System Model), per canonical definition
 func (me *MeErtCore) ComputeReliabilityPerDefinition() (float64, error) {
+	return me.ComputeReliabilityPerDefinitionContext(context.Background())
+}
+
+// ComputeReliabilityPerDefinitionContext computes reliability of Fractal MAIS (i.e., System Model), per canonical
+// definition. Cancellation of the context is checked before each layer. Once the context is cancelled, the error
+// of the context is returned and only instances of the layers processed so far carry their computed reliability.
+func (me *MeErtCore) ComputeReliabilityPerDefinitionContext(ctx context.Context) (float64, error) {
 	// no need to iterate over the last layer - reliabilities of all instances should be present for our disposal
 	for d := len(me.SystemModel.Layers) - 1; d > 0; d-- {
+		if err := ctx.Err(); err != nil {
+			return 0, fmt.Errorf("computation stopped at layer %d: %w", d, err)
+		}
 		layer, ok := me.SystemModel.Layers[d]
 		if !ok {
 			me.SystemModel.PrettyPrintApplications().PrettyPrintLayers()
-			return 0.0, fmt.Errorf("couldn't extract layer %d out of the System Model", d)
+			return 0.0, fmt.Errorf("level %d: %w", d, systemmodel.ErrLayerNotFound)
 		}
 		for _, inst := range layer.Instances {
 			var instRel float64 // there would be resulting reliability of an instance
@@ -99,3 +111,13 @@ func (me *MeErtCore) ComputeReliabilityPerDefinition() (float64, error) {
 
 	return totalReliability, nil
 }
+
+// Watch subscribes ME-ERT-CORE to the topology changes of the System Model. After each change reliability
+// is re-computed per definition and reported to the callback together with the change, which has caused it.
+func (me *MeErtCore) Watch(onChange func(event systemmodel.ChangeEvent, reliability float64, err error)) *MeErtCore {
+	me.SystemModel.Subscribe(func(event systemmodel.ChangeEvent) {
+		rel, err := me.ComputeReliabilityPerDefinition()
+		onChange(event, rel, err)
+	})
+	return me
+}
@@ -1,6 +1,8 @@
 package meertcore
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
 	"gotest.tools/assert"
@@ -48,6 +50,12 @@ func TestComputeReliabilityPerDefinition(t *testing.T) {
 	assert.Assert(t, totalRel != -1.23456789)
 	assert.Equal(t, fmt.Sprintf("%.12f", totalRel), "0.155589687500")
 
+	// computation is stopped, once the context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = me.ComputeReliabilityPerDefinitionContext(ctx)
+	assert.Assert(t, errors.Is(err, context.Canceled))
+
 	systemModel.PrettyPrintApplications()
 	systemModel.PrettyPrintLayers()
 	t.Logf("Total reliability of the system is: %v\n", fmt.Sprintf("%.12f", totalRel))
@@ -90,3 +98,42 @@ func TestComputeReliabilityOptimized(t *testing.T) {
 	// comparing reliability computed per definition and computed per optimized algo
 	assert.Equal(t, fmt.Sprintf("%.12f", totalRel), fmt.Sprintf("%.12f", totalRel1))
 }
+
+func TestWatch(t *testing.T) {
+	sm := systemmodel.CreateSystemModelDepth4()
+	me := &MeErtCore{
+		SystemModel: sm,
+	}
+	baseline, err := me.ComputeReliabilityPerDefinition()
+	assert.NilError(t, err)
+
+	updates := make([]float64, 0)
+	errs := make([]error, 0)
+	me.Watch(func(event systemmodel.ChangeEvent, reliability float64, err error) {
+		t.Logf("Reliability after change of type %v (%v) is %v", event.Type, event.Instances, reliability)
+		updates = append(updates, reliability)
+		errs = append(errs, err)
+	})
+
+	// removing a VI decreases reliability
+	err = sm.RemoveInstance("VI#3-4")
+	assert.NilError(t, err)
+	assert.Equal(t, len(updates), 1)
+	assert.NilError(t, errs[0])
+	assert.Assert(t, updates[0] < baseline)
+	assert.Equal(t, me.Reliability, updates[0])
+
+	// reliability of the newly deployed VI is not known yet
+	vis, err := sm.DeployApplicationAt("VI#2-2", "VI")
+	assert.NilError(t, err)
+	assert.Equal(t, len(updates), 2)
+	assert.ErrorContains(t, errs[1], "Reliability aspect")
+
+	// once reliability is set, it contributes to the System Model reliability
+	for _, vi := range vis {
+		vi.SetReliability(0.45)
+	}
+	rel, err := me.ComputeReliabilityPerDefinition()
+	assert.NilError(t, err)
+	assert.Assert(t, rel > updates[0])
+}
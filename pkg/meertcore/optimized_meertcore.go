@@ -3,7 +3,7 @@ package meertcore
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"math"
 	"strconv"
 	"strings"
@@ -122,7 +122,7 @@ func ComputeMeErtCoreCoefficient(rel float64, appNum int) (float64, error) {
 	case appNum <= 10000:
 		pow = 4
 	default:
-		log.Printf("Undefined App number scale: %d\n", appNum)
+		slog.Warn("undefined App number scale", "apps", appNum)
 		pow = 1
 	}
 
@@ -0,0 +1,405 @@
+// Package meertcore implements ME-ERT-CORE reliability model. This file in particular implements propagation of
+// the reliability uncertainty through the weighted FMAIS tree.
+package meertcore
+
+import (
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// DistributionType defines how the reliability distribution of an instance is described
+type DistributionType uint
+
+const (
+	DistributionMeanVariance DistributionType = 0 // DistributionMeanVariance describes a distribution with its mean value and variance
+	DistributionBeta         DistributionType = 1 // DistributionBeta describes a Beta distribution with parameters Alpha and Beta
+	DistributionSamples      DistributionType = 2 // DistributionSamples describes an empirical distribution given by a set of observed values
+)
+
+// Analytic and MonteCarlo name the methods used to propagate the uncertainty
+const (
+	Analytic   = "analytic"
+	MonteCarlo = "monte-carlo"
+)
+
+// defaultNumSamples is a number of Monte Carlo runs performed, when it was not specified by the caller
+const defaultNumSamples = 10000
+
+// leaf structure carries a leaf instance of the System Model together with its weight in the System Model reliability
+type leaf struct {
+	instance *systemmodel.Instance // leaf instance
+	path     string                // path of the leaf instance, e.g., MAIS/VI#2-1/App#3-2-1
+	weight   float64               // product of all priorities on the path from the root instance to the leaf
+}
+
+// Distribution structure carries a reliability distribution of a (leaf) instance
+type Distribution struct {
+	Type     DistributionType // specifies, how the distribution is described
+	Mean     float64          // mean value of the reliability
+	Variance float64          // variance of the reliability
+	Alpha    float64          // Alpha parameter of the Beta distribution
+	Beta     float64          // Beta parameter of the Beta distribution
+	Samples  []float64        // observed reliability values
+}
+
+// ReliabilityInterval structure carries a confidence interval of the System Model reliability
+type ReliabilityInterval struct {
+	Mean       float64 // expected reliability of the System Model
+	StdDev     float64 // standard deviation of the reliability of the System Model
+	Lower      float64 // lower bound of the confidence interval
+	Upper      float64 // upper bound of the confidence interval
+	Confidence float64 // confidence level of the interval (e.g., 0.95)
+	Method     string  // method, which was used to propagate the uncertainty (analytic or monte-carlo)
+}
+
+// NewMeanVarianceDistribution creates a Distribution described by its mean value and variance
+func NewMeanVarianceDistribution(mean, variance float64) *Distribution {
+	return &Distribution{
+		Type:     DistributionMeanVariance,
+		Mean:     mean,
+		Variance: variance,
+	}
+}
+
+// NewBetaDistribution creates a Beta Distribution with given parameters
+func NewBetaDistribution(alpha, beta float64) *Distribution {
+	return &Distribution{
+		Type:     DistributionBeta,
+		Mean:     alpha / (alpha + beta),
+		Variance: alpha * beta / ((alpha + beta) * (alpha + beta) * (alpha + beta + 1)),
+		Alpha:    alpha,
+		Beta:     beta,
+	}
+}
+
+// NewSampledDistribution creates an empirical Distribution out of the observed reliability values
+func NewSampledDistribution(samples []float64) *Distribution {
+	var mean, variance float64
+	for _, s := range samples {
+		mean += s
+	}
+	if len(samples) > 0 {
+		mean /= float64(len(samples))
+	}
+	for _, s := range samples {
+		variance += (s - mean) * (s - mean)
+	}
+	if len(samples) > 1 {
+		variance /= float64(len(samples) - 1)
+	}
+	return &Distribution{
+		Type:     DistributionSamples,
+		Mean:     mean,
+		Variance: variance,
+		Samples:  samples,
+	}
+}
+
+// validate checks that the Distribution is well-formed
+func (d *Distribution) validate() error {
+	switch d.Type {
+	case DistributionMeanVariance:
+		if d.Variance < 0 {
+			return fmt.Errorf("variance can't be negative, got %v", d.Variance)
+		}
+	case DistributionBeta:
+		if d.Alpha <= 0 || d.Beta <= 0 {
+			return fmt.Errorf("parameters of the Beta distribution should be positive, got alpha %v and beta %v", d.Alpha, d.Beta)
+		}
+	case DistributionSamples:
+		if len(d.Samples) == 0 {
+			return fmt.Errorf("sampled distribution carries no samples")
+		}
+	default:
+		return fmt.Errorf("unknown distribution type %d", d.Type)
+	}
+	return nil
+}
+
+// sample draws a random reliability value out of the Distribution
+func (d *Distribution) sample(rnd *rand.Rand) float64 {
+	switch d.Type {
+	case DistributionBeta:
+		x := sampleGamma(rnd, d.Alpha)
+		y := sampleGamma(rnd, d.Beta)
+		return x / (x + y)
+	case DistributionSamples:
+		return d.Samples[rnd.Intn(len(d.Samples))]
+	default:
+		// reliability is bounded to [0, 1], thus the Normal distribution is clipped
+		return math.Min(1, math.Max(0, d.Mean+rnd.NormFloat64()*math.Sqrt(d.Variance)))
+	}
+}
+
+// sampleGamma draws a random value from the Gamma distribution with a given shape (and unit scale).
+// It implements the method of Marsaglia and Tsang.
+func sampleGamma(rnd *rand.Rand, shape float64) float64 {
+	if shape < 1 {
+		// boosting the shape and correcting the result afterward
+		return sampleGamma(rnd, shape+1) * math.Pow(rnd.Float64(), 1/shape)
+	}
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rnd.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rnd.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
+
+// SetDistribution attaches a reliability Distribution to the (leaf) instance with a given path (e.g., MAIS/VI#2-2/VI#3-4),
+// or to all leaves with a given name. If several leaves share the name (e.g., VIs of a single deployment), each of them
+// follows the Distribution on its own, unless its path carries a Distribution of its own.
+func (me *MeErtCore) SetDistribution(instance string, d *Distribution) *MeErtCore {
+	if me.Distributions == nil {
+		me.Distributions = make(map[string]*Distribution, 0)
+	}
+	me.Distributions[instance] = d
+	return me
+}
+
+// distribution returns a Distribution of the leaf, the one set for its path takes precedence over the one set for
+// its name
+func (me *MeErtCore) distribution(l *leaf) (*Distribution, bool) {
+	if d, ok := me.Distributions[l.path]; ok {
+		return d, true
+	}
+	d, ok := me.Distributions[l.instance.Name]
+	return d, ok
+}
+
+// ComputeReliabilityInterval computes a confidence interval of the System Model reliability given the
+// reliability distributions of its leaf instances. Instances without a Distribution are treated as point estimates.
+// If all distributions are described by their moments, the propagation is analytic (the system reliability is
+// a weighted sum of the leaf reliabilities and the interval relies on a Normal approximation). Otherwise, numSamples
+// Monte Carlo runs are performed (numSamples <= 0 falls back to the default).
+func (me *MeErtCore) ComputeReliabilityInterval(confidence float64, numSamples int) (*ReliabilityInterval, error) {
+	if confidence <= 0 || confidence >= 1 {
+		return nil, fmt.Errorf("confidence level should be in (0, 1), got %v", confidence)
+	}
+
+	leaves, err := me.collectLeaves()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, 2*len(leaves))
+	for _, l := range leaves {
+		names[l.instance.Name] = true
+		names[l.path] = true
+	}
+	monteCarlo := false
+	// iterating in a stable order, so that the reported error doesn't change between runs
+	for _, name := range me.distributionNames() {
+		d := me.Distributions[name]
+		if !names[name] {
+			return nil, fmt.Errorf("instance %s carries a distribution, but it is not a leaf of the System Model", name)
+		}
+		if err := d.validate(); err != nil {
+			return nil, fmt.Errorf("instance %s: %w", name, err)
+		}
+		if d.Type == DistributionSamples {
+			monteCarlo = true
+		}
+	}
+
+	var interval *ReliabilityInterval
+	if monteCarlo {
+		interval, err = me.propagateMonteCarlo(leaves, confidence, numSamples)
+	} else {
+		interval, err = me.propagateAnalytic(leaves, confidence)
+	}
+	if err != nil {
+		return nil, err
+	}
+	me.Reliability = interval.Mean
+
+	return interval, nil
+}
+
+// propagateAnalytic propagates the moments of leaf distributions through the weighted tree
+func (me *MeErtCore) propagateAnalytic(leaves []*leaf, confidence float64) (*ReliabilityInterval, error) {
+	var mean, variance float64
+	for _, l := range leaves {
+		d, ok := me.distribution(l)
+		if !ok {
+			rel, err := l.instance.GetReliability()
+			if err != nil {
+				return nil, err
+			}
+			mean += l.weight * rel
+			continue
+		}
+		mean += l.weight * d.Mean
+		variance += l.weight * l.weight * d.Variance
+	}
+
+	stdDev := math.Sqrt(variance)
+	z := normalQuantile(0.5 + confidence/2)
+	return &ReliabilityInterval{
+		Mean:       mean,
+		StdDev:     stdDev,
+		Lower:      math.Max(0, mean-z*stdDev),
+		Upper:      math.Min(1, mean+z*stdDev),
+		Confidence: confidence,
+		Method:     Analytic,
+	}, nil
+}
+
+// propagateMonteCarlo estimates the distribution of the System Model reliability by sampling leaf distributions
+func (me *MeErtCore) propagateMonteCarlo(leaves []*leaf, confidence float64, numSamples int) (*ReliabilityInterval, error) {
+	if numSamples <= 0 {
+		numSamples = defaultNumSamples
+	}
+
+	// contribution of the point estimates is the same in each run
+	var fixed float64
+	sampled := make([]*leaf, 0, len(leaves))
+	distributions := make([]*Distribution, 0, len(leaves))
+	for _, l := range leaves {
+		if d, ok := me.distribution(l); ok {
+			sampled = append(sampled, l)
+			distributions = append(distributions, d)
+			continue
+		}
+		rel, err := l.instance.GetReliability()
+		if err != nil {
+			return nil, err
+		}
+		fixed += l.weight * rel
+	}
+
+	rnd := rand.New(rand.NewSource(rand.Int63()))
+	results := make([]float64, numSamples)
+	var mean float64
+	for s := 0; s < numSamples; s++ {
+		rel := fixed
+		// leaves are sampled in the order of the traversal, so that the runs are reproducible with a fixed seed
+		for i, l := range sampled {
+			rel += l.weight * distributions[i].sample(rnd)
+		}
+		results[s] = rel
+		mean += rel
+	}
+	mean /= float64(numSamples)
+
+	var variance float64
+	for _, r := range results {
+		variance += (r - mean) * (r - mean)
+	}
+	if numSamples > 1 {
+		variance /= float64(numSamples - 1)
+	}
+
+	sort.Float64s(results)
+	return &ReliabilityInterval{
+		Mean:       mean,
+		StdDev:     math.Sqrt(variance),
+		Lower:      quantile(results, (1-confidence)/2),
+		Upper:      quantile(results, 1-(1-confidence)/2),
+		Confidence: confidence,
+		Method:     MonteCarlo,
+	}, nil
+}
+
+// collectLeaves gathers all leaf instances of the System Model together with their weights in the order of the
+// traversal. The weight is a product of all priorities (instance priorities and Application/VI priorities) on the path
+// from the root instance to the leaf, i.e., the same coefficients which are applied in ComputeReliabilityPerDefinition().
+// Leaves are kept per instance together with their paths, since instances of a generated System Model don't have
+// to have unique names.
+func (me *MeErtCore) collectLeaves() ([]*leaf, error) {
+	root, ok := me.SystemModel.Layers[1]
+	if !ok || len(root.Instances) == 0 {
+		return nil, fmt.Errorf("couldn't extract root instance out of the System Model")
+	}
+	paths := me.SystemModel.InstancePaths()
+
+	// single instance System Model - the root instance is the only leaf
+	if len(root.Instances[0].Relations) == 0 {
+		return []*leaf{{instance: root.Instances[0], path: paths[root.Instances[0]], weight: 1}}, nil
+	}
+
+	return me.collectLeavesUnder(root.Instances[0], 1, paths, make([]*leaf, 0))
+}
+
+// collectLeavesUnder traverses the tree under the instance and accumulates leaf weights
+func (me *MeErtCore) collectLeavesUnder(inst *systemmodel.Instance, weight float64, paths map[*systemmodel.Instance]string,
+	leaves []*leaf) ([]*leaf, error) {
+	for _, rel := range inst.Relations {
+		priority, err := rel.GetPriority()
+		if err != nil {
+			return nil, err
+		}
+		appName := "VI"
+		if rel.IsApp() {
+			appName, err = rel.GetAppName()
+			if err != nil {
+				return nil, err
+			}
+		}
+		app, ok := me.SystemModel.Applications[appName]
+		if !ok {
+			return nil, fmt.Errorf("couldn't extract application with a key %s", appName)
+		}
+		appPriority, err := app.GetPriority()
+		if err != nil {
+			return nil, err
+		}
+
+		w := weight * priority * appPriority
+		if len(rel.Relations) == 0 {
+			leaves = append(leaves, &leaf{instance: rel, path: paths[rel], weight: w})
+			continue
+		}
+		leaves, err = me.collectLeavesUnder(rel, w, paths, leaves)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return leaves, nil
+}
+
+// distributionNames returns names of the instances carrying a distribution in ascending order
+func (me *MeErtCore) distributionNames() []string {
+	names := make([]string, 0, len(me.Distributions))
+	for name := range me.Distributions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// quantile returns the q-th quantile of sorted values (with linear interpolation)
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (pos-float64(lower))*(sorted[upper]-sorted[lower])
+}
+
+// normalQuantile returns the p-th quantile of a standard Normal distribution
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+// FormatInterval returns a human-readable representation of the interval
+func (ri *ReliabilityInterval) FormatInterval() string {
+	return fmt.Sprintf("%.6f [%.6f; %.6f] (%s, %.0f%% confidence)", ri.Mean, ri.Lower, ri.Upper,
+		ri.Method, ri.Confidence*100)
+}
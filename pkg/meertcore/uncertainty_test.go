@@ -0,0 +1,145 @@
+package meertcore
+
+import (
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"gotest.tools/assert"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestComputeReliabilityIntervalPointEstimates(t *testing.T) {
+	// without any distribution, the interval collapses to the reliability computed per definition
+	me := &MeErtCore{
+		SystemModel: systemmodel.CreateExampleBasicFMAIS(),
+	}
+	interval, err := me.ComputeReliabilityInterval(0.95, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, interval.Method, Analytic)
+	assert.Equal(t, fmt.Sprintf("%.12f", interval.Mean), "0.155589687500")
+	assert.Equal(t, interval.StdDev, 0.0)
+	assert.Equal(t, interval.Lower, interval.Upper)
+}
+
+func TestComputeReliabilityIntervalAnalytic(t *testing.T) {
+	me := &MeErtCore{
+		SystemModel: systemmodel.CreateExampleBasicFMAIS(),
+	}
+	// keeping the mean values of the instances the same
+	me.SetDistribution("App#2-1-1", NewMeanVarianceDistribution(0.77, 0.01)).
+		SetDistribution("App#3-2-5", NewBetaDistribution(74, 26))
+
+	interval, err := me.ComputeReliabilityInterval(0.95, 0)
+	assert.NilError(t, err)
+	t.Logf("Computed reliability is %s", interval.FormatInterval())
+	assert.Equal(t, interval.Method, Analytic)
+	assert.Equal(t, fmt.Sprintf("%.12f", interval.Mean), "0.155589687500")
+	assert.Assert(t, interval.StdDev > 0)
+	assert.Assert(t, interval.Lower < interval.Mean && interval.Mean < interval.Upper)
+}
+
+func TestComputeReliabilityIntervalMonteCarlo(t *testing.T) {
+	me := &MeErtCore{
+		SystemModel: systemmodel.CreateExampleBasicFMAIS(),
+	}
+	me.SetDistribution("App#2-1-2", NewSampledDistribution([]float64{0.3, 0.34, 0.38})).
+		SetDistribution("VI#3-4", NewBetaDistribution(7, 3))
+
+	interval, err := me.ComputeReliabilityInterval(0.9, 5000)
+	assert.NilError(t, err)
+	t.Logf("Computed reliability is %s", interval.FormatInterval())
+	assert.Equal(t, interval.Method, MonteCarlo)
+	assert.Assert(t, interval.Lower <= interval.Mean && interval.Mean <= interval.Upper)
+	// reliability shouldn't deviate much from the point estimate
+	assert.Assert(t, interval.Mean > 0.15 && interval.Mean < 0.16)
+}
+
+func TestComputeReliabilityIntervalErrors(t *testing.T) {
+	me := &MeErtCore{
+		SystemModel: systemmodel.CreateExampleBasicFMAIS(),
+	}
+	_, err := me.ComputeReliabilityInterval(1.5, 0)
+	assert.ErrorContains(t, err, "confidence level")
+
+	// VI#2-1 deploys other instances, thus it can't carry a distribution
+	me.SetDistribution("VI#2-1", NewMeanVarianceDistribution(0.5, 0.01))
+	_, err = me.ComputeReliabilityInterval(0.95, 0)
+	assert.ErrorContains(t, err, "not a leaf")
+
+	me.Distributions = nil
+	me.SetDistribution("App#2-1-1", NewSampledDistribution(nil))
+	_, err = me.ComputeReliabilityInterval(0.95, 0)
+	assert.ErrorContains(t, err, "no samples")
+}
+
+func TestComputeReliabilityIntervalGenerated(t *testing.T) {
+	// sibling VIs of a generated System Model share their names, each of them should still count on its own
+	for _, seed := range []int64{1, 42, 286} {
+		rand.Seed(seed)
+		sm := &systemmodel.SystemModel{}
+		sm.InitializeSystemModel(10, 4)
+		sm.CreateRandomApplications(systemmodel.GenerateAppNames(10), 1, 5)
+		_, err := sm.GenerateSystemModel()
+		assert.NilError(t, err)
+		sm.SetApplicationPrioritiesRandom()
+		assert.NilError(t, sm.SetInstancePrioritiesRandom())
+		assert.NilError(t, sm.SetInstanceReliabilitiesRandom())
+
+		me := &MeErtCore{SystemModel: sm}
+		interval, err := me.ComputeReliabilityInterval(0.95, 0)
+		assert.NilError(t, err)
+		rel, err := me.ComputeReliabilityPerDefinition()
+		assert.NilError(t, err)
+		t.Logf("Seed %d: interval mean is %v, reliability per definition is %v", seed, interval.Mean, rel)
+		assert.Assert(t, math.Abs(interval.Mean-rel) < 1e-9, "seed %d: %v != %v", seed, interval.Mean, rel)
+	}
+}
+
+func TestComputeReliabilityIntervalSameNamedLeaves(t *testing.T) {
+	// VIs of a single deployment share their name, they are told apart by their paths
+	sm := systemmodel.CreateSystemModelDepth4()
+	vis, err := sm.DeployApplicationAt("VI#3-4", "VI")
+	assert.NilError(t, err)
+	for _, vi := range vis {
+		vi.SetReliability(0.9)
+		vi.SetPriority(0.5)
+	}
+	me := &MeErtCore{SystemModel: sm}
+	me.SetDistribution("MAIS/VI#2-2/VI#3-4/VI#4-2[2]", NewMeanVarianceDistribution(0.5, 0.01))
+	interval, err := me.ComputeReliabilityInterval(0.95, 0)
+	assert.NilError(t, err)
+
+	// only the second VI follows the distribution
+	vis[1].SetReliability(0.5)
+	rel, err := me.ComputeReliabilityPerDefinition()
+	assert.NilError(t, err)
+	t.Logf("Interval mean is %v, reliability per definition is %v", interval.Mean, rel)
+	assert.Assert(t, math.Abs(interval.Mean-rel) < 1e-9)
+
+	// distribution set for the name is followed by both VIs, unless the path carries its own
+	me.SetDistribution("VI#4-2", NewMeanVarianceDistribution(0.7, 0.01))
+	interval, err = me.ComputeReliabilityInterval(0.95, 0)
+	assert.NilError(t, err)
+	vis[0].SetReliability(0.7)
+	rel, err = me.ComputeReliabilityPerDefinition()
+	assert.NilError(t, err)
+	assert.Assert(t, math.Abs(interval.Mean-rel) < 1e-9)
+}
+
+func TestComputeReliabilityIntervalReproducible(t *testing.T) {
+	me := &MeErtCore{
+		SystemModel: systemmodel.CreateExampleBasicFMAIS(),
+	}
+	me.SetDistribution("App#2-1-2", NewSampledDistribution([]float64{0.3, 0.34, 0.38})).
+		SetDistribution("App#2-1-1", NewBetaDistribution(7, 3)).
+		SetDistribution("VI#3-4", NewBetaDistribution(7, 3))
+
+	rand.Seed(7)
+	first, err := me.ComputeReliabilityInterval(0.9, 1000)
+	assert.NilError(t, err)
+	rand.Seed(7)
+	second, err := me.ComputeReliabilityInterval(0.9, 1000)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, first, second)
+}
@@ -0,0 +1,396 @@
+// Package report implements a generator of a single self-contained HTML report of a benchmark run. The report carries
+// run metadata, statistics gathered during the run, embedded SVG charts and sortable tables of the benchmarked data.
+// It can be generated at the end of the run, or offline from the data stored by the storedata package.
+package report
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/draw"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+const chartWidth = 16 * vg.Centimeter  // width of the embedded chart
+const chartHeight = 10 * vg.Centimeter // height of the embedded chart
+const maxCurves = 5                    // maximum number of curves in a chart, where curves are picked from a long axis
+
+// Entry structure holds a single named value shown in the report (e.g., a parameter or a statistic)
+type Entry struct {
+	Name  string
+	Value string
+}
+
+// Metadata structure describes the environment and the parameters of the run
+type Metadata struct {
+	Parameters []Entry   // parameters of the run in the order they should be shown
+	Seed       string    // seed of the random number generator (or a note, that it was not fixed)
+	Host       string    // name of the host, where the run was performed
+	GoVersion  string    // version of the Go runtime
+	Platform   string    // operating system and architecture
+	NumCPU     int       // number of logical CPUs
+	Generated  time.Time // time, when the report was generated
+}
+
+// Section structure holds a single benchmarked data cube, i.e., map[depth]map[apps]map[instances]value
+type Section struct {
+	Name string                          // name of the section
+	Unit string                          // unit of the values (e.g., us)
+	Data map[int]map[int]map[int]float64 // benchmarked data
+}
+
+// Report structure holds all information shown in the HTML report
+type Report struct {
+	Title      string
+	Metadata   Metadata
+	Statistics []Entry
+	Sections   []*Section
+}
+
+// NewReport creates a report with a given title and fills in the metadata of the current environment
+func NewReport(title string) *Report {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return &Report{
+		Title: title,
+		Metadata: Metadata{
+			Parameters: make([]Entry, 0),
+			Seed:       "not fixed",
+			Host:       host,
+			GoVersion:  runtime.Version(),
+			Platform:   runtime.GOOS + "/" + runtime.GOARCH,
+			NumCPU:     runtime.NumCPU(),
+			Generated:  time.Now(),
+		},
+		Statistics: make([]Entry, 0),
+		Sections:   make([]*Section, 0),
+	}
+}
+
+// AddParameter adds a parameter of the run to the report metadata
+func (r *Report) AddParameter(name string, value any) *Report {
+	r.Metadata.Parameters = append(r.Metadata.Parameters, Entry{Name: name, Value: fmt.Sprint(value)})
+	return r
+}
+
+// SetSeed sets a seed of the random number generator used in the run
+func (r *Report) SetSeed(seed int64) *Report {
+	r.Metadata.Seed = strconv.FormatInt(seed, 10)
+	return r
+}
+
+// AddStatistic adds a statistic gathered during the run (e.g., maximum number of instances or allocated memory)
+func (r *Report) AddStatistic(name string, value any) *Report {
+	r.Statistics = append(r.Statistics, Entry{Name: name, Value: fmt.Sprint(value)})
+	return r
+}
+
+// AddSection adds a benchmarked data cube to the report
+func (r *Report) AddSection(name, unit string, data map[int]map[int]map[int]float64) *Report {
+	r.Sections = append(r.Sections, &Section{Name: name, Unit: unit, Data: data})
+	return r
+}
+
+// FromData creates a report offline from the data files stored in the data directory (see storedata.DataDir).
+// Files holding a single value (e.g., maximum number of instances or reliability) and the values stored
+// in the result documents are shown as statistics, the rest as sections.
+func FromData(title string, fileNames ...string) (*Report, error) {
+	r := NewReport(title)
+	for _, fileName := range fileNames {
+		result, err := storedata.ImportResult(storedata.DataDir(), fileName)
+		if err != nil {
+			return nil, fmt.Errorf("can't import %s: %w", fileName, err)
+		}
+		data := result.Means()
+		name := strings.TrimSuffix(strings.TrimSuffix(fileName, ".json"), ".csv")
+		r.AddParameter("Data file", fileName)
+		for _, v := range result.Values {
+			r.AddStatistic(fmt.Sprintf("%s [%s]", v.Name, v.Unit), fmt.Sprintf("%v (depth %d, %d apps, %d instances per app)",
+				v.Value, v.Depth, v.Apps, v.Instances))
+		}
+
+		if depth, apps, instances, value, ok := singleValue(data); ok {
+			r.AddStatistic(fmt.Sprintf("%s (depth %d, %d apps, %d instances per app)", name, depth, apps, instances), value)
+			continue
+		}
+		unit := result.Unit
+		if unit == "" {
+			unit = "us"
+			if strings.Contains(name, "reliability") {
+				unit = "-"
+			}
+		}
+		r.AddSection(name, unit, data)
+	}
+	return r, nil
+}
+
+// Write renders the report as an HTML document to the writer
+func (r *Report) Write(w io.Writer) error {
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"formatTime": func(t time.Time) string { return t.Format(time.RFC1123) },
+	}).Parse(reportTemplate)
+	if err != nil {
+		return err
+	}
+
+	sections := make([]renderedSection, 0, len(r.Sections))
+	for _, s := range r.Sections {
+		rs, err := s.render()
+		if err != nil {
+			return fmt.Errorf("can't render section %s: %w", s.Name, err)
+		}
+		sections = append(sections, rs)
+	}
+
+	return tmpl.Execute(w, struct {
+		*Report
+		Rendered []renderedSection
+	}{
+		Report:   r,
+		Rendered: sections,
+	})
+}
+
+// Save renders the report to the file in a given directory (which is created, if it doesn't exist)
+// and returns the path to it
+func (r *Report) Save(dir, fileName string) (string, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+	if filepath.Ext(fileName) != ".html" {
+		fileName += ".html"
+	}
+	path := filepath.Join(dir, fileName)
+
+	var buf bytes.Buffer
+	err = r.Write(&buf)
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// Row structure holds a single cell of the benchmarked data cube
+type Row struct {
+	Depth     int
+	Apps      int
+	Instances int
+	Value     float64
+}
+
+// renderedSection structure holds a section prepared for the template
+type renderedSection struct {
+	Name   string
+	Unit   string
+	Charts []template.HTML
+	Rows   []Row
+}
+
+// render prepares the section for the template, i.e., renders its charts to SVG and flattens the data to rows
+func (s *Section) render() (renderedSection, error) {
+	rs := renderedSection{
+		Name: s.Name,
+		Unit: s.Unit,
+		Rows: s.Rows(),
+	}
+	depths, apps, instances := s.axes()
+	if len(depths) == 0 {
+		return rs, nil
+	}
+	maxApps := apps[len(apps)-1]
+	maxInstances := instances[len(instances)-1]
+
+	yLabel := fmt.Sprintf("%s [%s]", s.Name, s.Unit)
+	charts := []struct {
+		title  string
+		xLabel string
+		lines  map[string]plotter.XYs
+	}{
+		{
+			title:  fmt.Sprintf("Dependency on the number of instances per application (%d apps)", maxApps),
+			xLabel: "Instances per application [-]",
+			lines: s.lines(depths, "Depth ", func(depth int) plotter.XYs {
+				return s.series(instances, func(inst int) (float64, bool) { return s.value(depth, maxApps, inst) })
+			}),
+		},
+		{
+			title:  fmt.Sprintf("Dependency on the number of applications (%d instances per app)", maxInstances),
+			xLabel: "Applications [-]",
+			lines: s.lines(depths, "Depth ", func(depth int) plotter.XYs {
+				return s.series(apps, func(app int) (float64, bool) { return s.value(depth, app, maxInstances) })
+			}),
+		},
+		{
+			title:  fmt.Sprintf("Dependency on the depth (%d instances per app)", maxInstances),
+			xLabel: "Depth [-]",
+			lines: s.lines(pick(apps, maxCurves), "Apps ", func(app int) plotter.XYs {
+				return s.series(depths, func(depth int) (float64, bool) { return s.value(depth, app, maxInstances) })
+			}),
+		},
+	}
+	for _, c := range charts {
+		if len(c.lines) == 0 {
+			continue
+		}
+		svg, err := renderChart(c.title, c.xLabel, yLabel, c.lines)
+		if err != nil {
+			return rs, err
+		}
+		rs.Charts = append(rs.Charts, svg)
+	}
+	return rs, nil
+}
+
+// Rows flattens the benchmarked data cube to rows sorted by depth, number of applications and number of instances
+func (s *Section) Rows() []Row {
+	rows := make([]Row, 0)
+	for depth, apps := range s.Data {
+		for app, instances := range apps {
+			for inst, value := range instances {
+				rows = append(rows, Row{Depth: depth, Apps: app, Instances: inst, Value: value})
+			}
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Depth != rows[j].Depth {
+			return rows[i].Depth < rows[j].Depth
+		}
+		if rows[i].Apps != rows[j].Apps {
+			return rows[i].Apps < rows[j].Apps
+		}
+		return rows[i].Instances < rows[j].Instances
+	})
+	return rows
+}
+
+// axes returns sorted values of depth, number of applications and number of instances present in the data
+func (s *Section) axes() ([]int, []int, []int) {
+	depths, apps, instances := make(map[int]bool), make(map[int]bool), make(map[int]bool)
+	for _, row := range s.Rows() {
+		depths[row.Depth] = true
+		apps[row.Apps] = true
+		instances[row.Instances] = true
+	}
+	return sortedKeys(depths), sortedKeys(apps), sortedKeys(instances)
+}
+
+// value returns a value of the cell, if it is present in the data
+func (s *Section) value(depth, apps, instances int) (float64, bool) {
+	v, ok := s.Data[depth][apps][instances]
+	return v, ok
+}
+
+// series composes a curve over given X values, cells missing in the data are skipped
+func (s *Section) series(xs []int, value func(x int) (float64, bool)) plotter.XYs {
+	data := make(plotter.XYs, 0, len(xs))
+	for _, x := range xs {
+		if v, ok := value(x); ok {
+			data = append(data, plotter.XY{X: float64(x), Y: v})
+		}
+	}
+	return data
+}
+
+// lines composes a curve for each key, empty curves are skipped
+func (s *Section) lines(keys []int, prefix string, curve func(key int) plotter.XYs) map[string]plotter.XYs {
+	lines := make(map[string]plotter.XYs, len(keys))
+	width := 0
+	if len(keys) > 0 {
+		width = len(strconv.Itoa(keys[len(keys)-1]))
+	}
+	for _, k := range keys {
+		if c := curve(k); len(c) > 0 {
+			// padding keeps curves sorted by the numeric value in the legend
+			lines[fmt.Sprintf("%s%*d", prefix, width, k)] = c
+		}
+	}
+	return lines
+}
+
+// renderChart renders a chart with provided curves to an SVG, which is embedded to the report
+func renderChart(title, xLabel, yLabel string, lines map[string]plotter.XYs) (template.HTML, error) {
+	p := plot.New()
+	p.Title.Text = title
+	p.X.Label.Text = xLabel
+	p.Y.Label.Text = yLabel
+	p.Add(plotter.NewGrid())
+	err := draw.AddScattersAndLines(p, false, lines)
+	if err != nil {
+		return "", err
+	}
+	p.Legend.Top = true
+	p.Legend.Left = true
+
+	wt, err := p.WriterTo(chartWidth, chartHeight, "svg")
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	_, err = wt.WriteTo(&buf)
+	if err != nil {
+		return "", err
+	}
+	// SVG is generated by the plotter, thus it is safe to embed it as is (without the XML prolog)
+	svg := buf.String()
+	if idx := strings.Index(svg, "<svg"); idx > 0 {
+		svg = svg[idx:]
+	}
+	return template.HTML(svg), nil
+}
+
+// singleValue checks whether the data cube holds a single value and returns it together with its coordinates
+func singleValue(data map[int]map[int]map[int]float64) (int, int, int, float64, bool) {
+	count := 0
+	var depth, apps, instances int
+	var value float64
+	for d, a := range data {
+		for ap, i := range a {
+			for in, v := range i {
+				count++
+				depth, apps, instances, value = d, ap, in, v
+			}
+		}
+	}
+	return depth, apps, instances, value, count == 1
+}
+
+// pick returns at most n evenly spaced values (including the first and the last one)
+func pick(values []int, n int) []int {
+	if len(values) <= n {
+		return values
+	}
+	out := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, values[i*(len(values)-1)/(n-1)])
+	}
+	return out
+}
+
+// sortedKeys returns sorted keys of the map
+func sortedKeys(m map[int]bool) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,115 @@
+package report
+
+import (
+	"bytes"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/storedata"
+	"gotest.tools/assert"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testData returns a small benchmarked data cube
+func testData() map[int]map[int]map[int]float64 {
+	data := make(map[int]map[int]map[int]float64, 0)
+	for depth := 1; depth <= 3; depth++ {
+		data[depth] = make(map[int]map[int]float64, 0)
+		for apps := 1; apps <= 11; apps += 5 {
+			data[depth][apps] = make(map[int]float64, 0)
+			for inst := 1; inst <= 11; inst += 5 {
+				data[depth][apps][inst] = float64(depth * apps * inst)
+			}
+		}
+	}
+	return data
+}
+
+func TestReportWrite(t *testing.T) {
+	r := NewReport("Unit test <benchmark>").
+		AddParameter("Iterations", 10).
+		SetSeed(42).
+		AddStatistic("Maximum allocated memory [MB]", 12).
+		AddSection("Generation time", "us", testData())
+	assert.Assert(t, r.Metadata.GoVersion != "")
+
+	var buf bytes.Buffer
+	err := r.Write(&buf)
+	assert.NilError(t, err)
+	html := buf.String()
+	t.Logf("Report has %d bytes", len(html))
+
+	// title is escaped, charts are embedded as SVG without the XML prolog
+	assert.Assert(t, strings.Contains(html, "<title>Unit test &lt;benchmark&gt;</title>"))
+	assert.Equal(t, strings.Count(html, "<svg"), 3)
+	assert.Assert(t, !strings.Contains(html, "<?xml"))
+	assert.Assert(t, strings.Contains(html, "<td>42</td>"))
+	assert.Assert(t, strings.Contains(html, "Maximum allocated memory [MB]"))
+	// each cell of the data cube is a row of the sortable table
+	assert.Equal(t, strings.Count(html, "<tr><td>"), 27)
+	assert.Assert(t, strings.Contains(html, `<table class="sortable">`))
+	// rows are sorted by the column of the clicked header within its own table
+	assert.Assert(t, strings.Contains(html, "var column = th.cellIndex;"))
+}
+
+func TestSectionRows(t *testing.T) {
+	s := &Section{Name: "Generation time", Unit: "us", Data: testData()}
+	rows := s.Rows()
+	assert.Equal(t, len(rows), 27)
+	assert.DeepEqual(t, rows[0], Row{Depth: 1, Apps: 1, Instances: 1, Value: 1})
+	assert.DeepEqual(t, rows[1], Row{Depth: 1, Apps: 1, Instances: 6, Value: 6})
+	assert.DeepEqual(t, rows[26], Row{Depth: 3, Apps: 11, Instances: 11, Value: 363})
+
+	depths, apps, instances := s.axes()
+	assert.DeepEqual(t, depths, []int{1, 2, 3})
+	assert.DeepEqual(t, apps, []int{1, 6, 11})
+	assert.DeepEqual(t, instances, []int{1, 6, 11})
+}
+
+func TestPick(t *testing.T) {
+	assert.DeepEqual(t, pick([]int{1, 2, 3}, 5), []int{1, 2, 3})
+	assert.DeepEqual(t, pick([]int{1, 6, 11, 16, 21, 26, 31, 36, 41}, 5), []int{1, 11, 21, 31, 41})
+}
+
+func TestFromData(t *testing.T) {
+	defer storedata.SetDataDir(storedata.DataDir())
+	dir := t.TempDir()
+	storedata.SetDataDir(dir)
+
+	err := storedata.SaveData(testData(), "benchmark_unittest")
+	assert.NilError(t, err)
+	err = storedata.SaveData(map[int]map[int]map[int]float64{3: {11: {11: 363}}}, "maxNumInstances_unittest")
+	assert.NilError(t, err)
+
+	r, err := FromData("Offline report", "benchmark_unittest.json", "maxNumInstances_unittest.csv")
+	assert.NilError(t, err)
+	assert.Equal(t, len(r.Sections), 1)
+	assert.Equal(t, r.Sections[0].Name, "benchmark_unittest")
+	assert.Equal(t, len(r.Statistics), 1)
+	assert.Equal(t, r.Statistics[0].Value, "363")
+	t.Logf("Statistic: %s = %s", r.Statistics[0].Name, r.Statistics[0].Value)
+
+	path, err := r.Save(dir+"/reports", "offline")
+	assert.NilError(t, err)
+	info, err := os.Stat(path)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasSuffix(path, "offline.html"))
+	assert.Assert(t, info.Size() > 0)
+
+	// values of the result document are shown as statistics
+	result := storedata.NewResultDocument("ME-ERT-CORE computation time", "ms", 2, false).
+		AddCell(1, 1, 1, storedata.ComputeStats([]float64{1, 3})).
+		AddCell(2, 1, 1, storedata.ComputeStats([]float64{5, 7})).
+		AddValue("Maximum number of instances", "-", 21, 2, 1, 1)
+	err = storedata.SaveResult(result, "result_unittest")
+	assert.NilError(t, err)
+	r, err = FromData("Offline report", "result_unittest.json")
+	assert.NilError(t, err)
+	assert.Equal(t, len(r.Sections), 1)
+	assert.Equal(t, r.Sections[0].Unit, "ms")
+	assert.Equal(t, r.Sections[0].Data[2][1][1], 6.0)
+	assert.Equal(t, len(r.Statistics), 1)
+	assert.Equal(t, r.Statistics[0].Name, "Maximum number of instances [-]")
+
+	_, err = FromData("Offline report", "missing.json")
+	assert.ErrorContains(t, err, "can't import missing.json")
+}
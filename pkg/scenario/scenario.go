@@ -0,0 +1,218 @@
+// Package scenario implements what-if analysis of the Fractal MAIS. Each scenario applies a set of named
+// perturbations (e.g., failure of an instance) to a copy of the System Model and reports the impact
+// on the ME-ERT-CORE reliability.
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/meertcore"
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"math"
+	"os"
+)
+
+// Supported perturbation types
+const (
+	KillInstance     = "kill-instance"     // sets reliability of an instance (or of all instances deployed under it) to 0
+	RemoveSubtree    = "remove-subtree"    // removes a VI together with all instances it has deployed
+	ScaleApplication = "scale-application" // multiplies reliability of all instances of an Application by a factor
+	SetPriority      = "set-priority"      // changes priority of an Application (or a VI) or of a single instance
+)
+
+// Perturbation structure defines a single change applied to the System Model
+type Perturbation struct {
+	Type     string  `json:"type"`               // type of the perturbation
+	Target   string  `json:"target"`             // name of the instance or the Application, which is perturbed
+	Factor   float64 `json:"factor,omitempty"`   // scaling factor (used by scale-application)
+	Priority float64 `json:"priority,omitempty"` // new priority value (used by set-priority)
+}
+
+// Scenario structure defines a named set of perturbations, which are applied together
+type Scenario struct {
+	Name          string         `json:"name"`          // name of the scenario
+	Perturbations []Perturbation `json:"perturbations"` // perturbations applied in the scenario
+}
+
+// Result structure carries outcome of the scenario evaluation
+type Result struct {
+	Name                string  `json:"name"`                // name of the scenario
+	BaselineReliability float64 `json:"baselineReliability"` // reliability of the unperturbed System Model
+	Reliability         float64 `json:"reliability"`         // reliability of the System Model after the perturbations
+	Delta               float64 `json:"delta"`               // difference between perturbed and baseline reliability
+}
+
+// LoadScenarios reads a list of scenarios from a JSON file
+func LoadScenarios(fileName string) ([]Scenario, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var scenarios []Scenario
+	if err := json.Unmarshal(content, &scenarios); err != nil {
+		return nil, fmt.Errorf("couldn't parse scenarios from %s: %w", fileName, err)
+	}
+	if len(scenarios) == 0 {
+		return nil, fmt.Errorf("no scenarios were defined in %s", fileName)
+	}
+
+	return scenarios, nil
+}
+
+// Evaluate computes ME-ERT-CORE reliability (per definition) of the System Model and evaluates each scenario
+// on its own copy of the System Model. Provided System Model is left intact.
+func Evaluate(sm *systemmodel.SystemModel, scenarios ...Scenario) ([]Result, error) {
+	baseline, err := computeReliability(sm.Clone())
+	if err != nil {
+		return nil, fmt.Errorf("couldn't compute baseline reliability: %w", err)
+	}
+
+	results := make([]Result, 0, len(scenarios))
+	for _, s := range scenarios {
+		perturbed := sm.Clone()
+		for _, p := range s.Perturbations {
+			err = p.Apply(perturbed)
+			if err != nil {
+				return nil, fmt.Errorf("scenario %s: %w", s.Name, err)
+			}
+		}
+		rel, err := computeReliability(perturbed)
+		if err != nil {
+			return nil, fmt.Errorf("scenario %s: %w", s.Name, err)
+		}
+		results = append(results, Result{
+			Name:                s.Name,
+			BaselineReliability: baseline,
+			Reliability:         rel,
+			Delta:               rel - baseline,
+		})
+	}
+
+	return results, nil
+}
+
+// computeReliability computes reliability of the System Model per definition
+func computeReliability(sm *systemmodel.SystemModel) (float64, error) {
+	me := meertcore.MeErtCore{
+		SystemModel: sm,
+		Reliability: 0.0,
+	}
+	return me.ComputeReliabilityPerDefinition()
+}
+
+// Apply applies the perturbation to the System Model
+func (p Perturbation) Apply(sm *systemmodel.SystemModel) error {
+	switch p.Type {
+	case KillInstance:
+		return killInstance(sm, p.Target)
+	case RemoveSubtree:
+		return removeSubtree(sm, p.Target)
+	case ScaleApplication:
+		return scaleApplication(sm, p.Target, p.Factor)
+	case SetPriority:
+		return setPriority(sm, p.Target, p.Priority)
+	default:
+		return fmt.Errorf("unknown perturbation type '%s'", p.Type)
+	}
+}
+
+// killInstance sets reliability of an instance to 0. If the instance has deployed other instances,
+// all of them are killed as well.
+func killInstance(sm *systemmodel.SystemModel, name string) error {
+	inst, err := sm.GetInstance(name)
+	if err != nil {
+		return err
+	}
+	killSubtree(inst)
+	return nil
+}
+
+// killSubtree sets reliability of all instances under (and including) the instance to 0
+func killSubtree(inst *systemmodel.Instance) {
+	inst.SetReliability(0)
+	for _, rel := range inst.Relations {
+		killSubtree(rel)
+	}
+}
+
+// removeSubtree removes a VI together with all instances it has deployed from the System Model.
+// If the parent is left with no relations, its reliability is set to 0, since it does not host anything anymore.
+func removeSubtree(sm *systemmodel.SystemModel, name string) error {
+	inst, err := sm.GetInstance(name)
+	if err != nil {
+		return err
+	}
+	if !inst.IsVI() {
+		return fmt.Errorf("only VI subtrees can be removed, %s is not a VI", name)
+	}
+
+	parent, err := sm.GetParent(name)
+	if err != nil {
+		return err
+	}
+	err = sm.RemoveInstance(name)
+	if err != nil {
+		return err
+	}
+	if len(parent.Relations) == 0 {
+		parent.SetReliability(0)
+	}
+
+	return nil
+}
+
+// scaleApplication multiplies reliability of all instances of an Application by a factor.
+// Resulting reliability is kept within [0, 1].
+func scaleApplication(sm *systemmodel.SystemModel, appName string, factor float64) error {
+	if _, ok := sm.Applications[appName]; !ok {
+		return fmt.Errorf("%s: %w", appName, systemmodel.ErrAppNotFound)
+	}
+	if factor < 0 {
+		return fmt.Errorf("scaling factor can't be negative, got %v", factor)
+	}
+
+	found := 0
+	for _, layer := range sm.Layers {
+		for _, inst := range layer.Instances {
+			if !inst.IsApp() {
+				continue
+			}
+			instAppName, err := inst.GetAppName()
+			if err != nil {
+				return err
+			}
+			if instAppName != appName {
+				continue
+			}
+			rel, err := inst.GetReliability()
+			if err != nil {
+				return err
+			}
+			inst.SetReliability(math.Min(1, rel*factor))
+			found++
+		}
+	}
+	if found == 0 {
+		return fmt.Errorf("%s has no instances: %w", appName, systemmodel.ErrAppNotDeployed)
+	}
+
+	return nil
+}
+
+// setPriority changes priority of an Application (including VI), or of an instance with a given name
+func setPriority(sm *systemmodel.SystemModel, target string, priority float64) error {
+	if priority < 0 || priority > 1 {
+		return fmt.Errorf("priority should be within [0, 1], got %v", priority)
+	}
+	if app, ok := sm.Applications[target]; ok {
+		app.SetPriority(priority)
+		return nil
+	}
+	inst, err := sm.GetInstance(target)
+	if err != nil {
+		return err
+	}
+	inst.SetPriority(priority)
+	return nil
+}
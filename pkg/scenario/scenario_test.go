@@ -0,0 +1,99 @@
+package scenario
+
+import (
+	"gitlab.fel.cvut.cz/eroshiva/fractal-multi-agent-system/pkg/systemmodel"
+	"gotest.tools/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	sm := systemmodel.CreateSystemModelDepth4()
+	numInstances := sm.GetTotalNumberOfInstances()
+
+	results, err := Evaluate(sm,
+		Scenario{
+			Name:          "no change",
+			Perturbations: []Perturbation{},
+		},
+		Scenario{
+			Name: "App#4 fails",
+			Perturbations: []Perturbation{
+				{Type: KillInstance, Target: "App#4-4-1"},
+			},
+		},
+		Scenario{
+			Name: "VI#3-3 is removed",
+			Perturbations: []Perturbation{
+				{Type: RemoveSubtree, Target: "VI#3-3"},
+			},
+		},
+		Scenario{
+			Name: "App#1 degrades",
+			Perturbations: []Perturbation{
+				{Type: ScaleApplication, Target: "App#1", Factor: 0.5},
+			},
+		},
+		Scenario{
+			Name: "App#1 is more important",
+			Perturbations: []Perturbation{
+				{Type: SetPriority, Target: "App#1", Priority: 0.5},
+			},
+		},
+	)
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 5)
+	for _, r := range results {
+		t.Logf("Scenario '%s': reliability %v, delta %v", r.Name, r.Reliability, r.Delta)
+	}
+
+	assert.Equal(t, results[0].Delta, 0.0)
+	assert.Assert(t, results[1].Delta < 0)
+	assert.Equal(t, results[1].Delta, results[2].Delta) // both scenarios cut off the only instance of App#4
+	assert.Assert(t, results[3].Delta < 0)
+	assert.Assert(t, results[4].Delta > 0)
+
+	// original System Model should stay intact
+	assert.Equal(t, sm.GetTotalNumberOfInstances(), numInstances)
+	inst, err := sm.GetInstance("App#4-4-1")
+	assert.NilError(t, err)
+	rel, err := inst.GetReliability()
+	assert.NilError(t, err)
+	assert.Equal(t, rel, 0.77)
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	sm := systemmodel.CreateSystemModelDepth4()
+
+	_, err := Evaluate(sm, Scenario{Name: "unknown", Perturbations: []Perturbation{{Type: "explode", Target: "MAIS"}}})
+	assert.ErrorContains(t, err, "unknown perturbation type")
+
+	_, err = Evaluate(sm, Scenario{Name: "root", Perturbations: []Perturbation{{Type: RemoveSubtree, Target: "MAIS"}}})
+	assert.ErrorContains(t, err, "root instance can't be removed")
+
+	_, err = Evaluate(sm, Scenario{Name: "app", Perturbations: []Perturbation{{Type: RemoveSubtree, Target: "App#2-1-1"}}})
+	assert.ErrorContains(t, err, "is not a VI")
+
+	_, err = Evaluate(sm, Scenario{Name: "missing", Perturbations: []Perturbation{{Type: ScaleApplication, Target: "App#9", Factor: 2}}})
+	assert.ErrorContains(t, err, "was not initialized")
+}
+
+func TestLoadScenarios(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "scenarios.json")
+	content := `[
+ {"name": "App#4 fails", "perturbations": [{"type": "kill-instance", "target": "App#4-4-1"}]},
+ {"name": "App#1 degrades", "perturbations": [{"type": "scale-application", "target": "App#1", "factor": 0.8}]}
+]`
+	err := os.WriteFile(fileName, []byte(content), 0644)
+	assert.NilError(t, err)
+
+	scenarios, err := LoadScenarios(fileName)
+	assert.NilError(t, err)
+	assert.Equal(t, len(scenarios), 2)
+	assert.Equal(t, scenarios[1].Perturbations[0].Type, ScaleApplication)
+	assert.Equal(t, scenarios[1].Perturbations[0].Factor, 0.8)
+
+	_, err = LoadScenarios(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Assert(t, err != nil)
+}
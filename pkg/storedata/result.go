@@ -0,0 +1,471 @@
+// Package storedata implements a set of utility functions, which are capable of importing/exporting data
+// from/to JSON or CSV file. This file in particular implements a versioned result document of a benchmark run, which
+// keeps the parameter grid, per-cell statistics of the measured samples, environment metadata and units.
+package storedata
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResultSchemaVersion is a version of the result document written by SaveResult.
+// Files without a version are bare data cubes written by SaveData.
+const ResultSchemaVersion = 1
+
+// Stats structure holds statistics of the samples measured in a single cell of the parameter grid
+type Stats struct {
+	N      int     `json:"n"` // number of samples, it is 0 for the data imported from the bare data cubes
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	P95    float64 `json:"p95"` // 95th percentile
+	StdDev float64 `json:"stddev"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	// confidence interval of the mean, it is not set, unless computed with WithConfidenceInterval
+	CILower    float64 `json:"ciLower,omitempty"`
+	CIUpper    float64 `json:"ciUpper,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"` // confidence level of the interval (e.g., 0.95)
+	Trimmed    int     `json:"trimmed,omitempty"`    // number of samples discarded as outliers (not included in N)
+}
+
+// Cell structure holds statistics of a single combination of the benchmark parameters
+type Cell struct {
+	Depth     int `json:"depth"`     // depth of the FMAIS
+	Apps      int `json:"apps"`      // number of applications in the FMAIS
+	Instances int `json:"instances"` // maximum number of instances deployed by application
+	Stats
+	Memory *Memory `json:"memory,omitempty"` // memory metrics of the measured call, if they were gathered
+}
+
+// Memory structure holds memory metrics of the measured call in a single cell of the parameter grid
+type Memory struct {
+	AllocatedBytes float64 `json:"allocatedBytes"` // mean number of bytes allocated by a single call
+	Allocations    float64 `json:"allocations"`    // mean number of heap objects allocated by a single call
+	PeakHeap       float64 `json:"peakHeap"`       // the greatest heap size observed right after the call (in bytes)
+	RetainedHeap   float64 `json:"retainedHeap"`   // heap retained by the result of a single call after garbage collection (in bytes)
+}
+
+// names of the memory metrics, see MemoryData
+const (
+	MemoryAllocatedBytes = "allocatedBytes"
+	MemoryAllocations    = "allocations"
+	MemoryPeakHeap       = "peakHeap"
+	MemoryRetainedHeap   = "retainedHeap"
+)
+
+// MemoryMetrics lists names of all memory metrics
+var MemoryMetrics = []string{MemoryAllocatedBytes, MemoryAllocations, MemoryPeakHeap, MemoryRetainedHeap}
+
+// metric returns a value of the memory metric with a given name
+func (m *Memory) metric(name string) (float64, error) {
+	switch name {
+	case MemoryAllocatedBytes:
+		return m.AllocatedBytes, nil
+	case MemoryAllocations:
+		return m.Allocations, nil
+	case MemoryPeakHeap:
+		return m.PeakHeap, nil
+	case MemoryRetainedHeap:
+		return m.RetainedHeap, nil
+	}
+	return 0, fmt.Errorf("unknown memory metric %s, expected one of %s", name, strings.Join(MemoryMetrics, ", "))
+}
+
+// Grid structure holds values of each benchmark parameter, over which the benchmark was run
+type Grid struct {
+	Depths    []int `json:"depths"`
+	Apps      []int `json:"apps"`
+	Instances []int `json:"instances"`
+}
+
+// Value structure holds a single named value gathered during the run (e.g., maximum number of instances)
+// together with the cell of the grid, where it was observed
+type Value struct {
+	Name      string  `json:"name"`
+	Unit      string  `json:"unit"`
+	Value     float64 `json:"value"`
+	Depth     int     `json:"depth"`
+	Apps      int     `json:"apps"`
+	Instances int     `json:"instances"`
+}
+
+// Environment structure describes the environment, where the benchmark was run
+type Environment struct {
+	Host      string `json:"host"`
+	GoVersion string `json:"goVersion"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	NumCPU    int    `json:"numCPU"`
+	Docker    bool   `json:"docker"`
+}
+
+// Execution structure describes how the cells of the benchmark were executed
+type Execution struct {
+	Mode         string `json:"mode"`         // sequential (the reference mode) or parallel
+	Workers      int    `json:"workers"`      // number of cells measured at the same time
+	GOMAXPROCS   int    `json:"gomaxprocs"`   // value of GOMAXPROCS during the run
+	LockOSThread bool   `json:"lockOSThread"` // indicates that each worker was locked to its own OS thread
+}
+
+// ResultDocument structure is a versioned document holding results of a benchmark run
+type ResultDocument struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Name          string      `json:"name"`       // name of the measured quantity
+	Unit          string      `json:"unit"`       // unit of the measured quantity (e.g., us)
+	Iterations    int         `json:"iterations"` // number of iterations performed in each cell
+	Created       time.Time   `json:"created"`
+	Environment   Environment `json:"environment"`
+	Execution     *Execution  `json:"execution,omitempty"` // it is not set for the documents written before it was introduced
+	Grid          Grid        `json:"grid"`
+	Cells         []Cell      `json:"cells"`
+	Values        []Value     `json:"values,omitempty"`
+}
+
+// NewResultDocument creates an empty result document of a measured quantity and fills in the current environment
+func NewResultDocument(name, unit string, iterations int, docker bool) *ResultDocument {
+	return &ResultDocument{
+		SchemaVersion: ResultSchemaVersion,
+		Name:          name,
+		Unit:          unit,
+		Iterations:    iterations,
+		Created:       time.Now(),
+		Environment:   CurrentEnvironment(docker),
+		Grid: Grid{
+			Depths:    make([]int, 0),
+			Apps:      make([]int, 0),
+			Instances: make([]int, 0),
+		},
+		Cells:  make([]Cell, 0),
+		Values: make([]Value, 0),
+	}
+}
+
+// CurrentEnvironment returns a description of the environment, where the program runs
+func CurrentEnvironment(docker bool) Environment {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return Environment{
+		Host:      host,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		NumCPU:    runtime.NumCPU(),
+		Docker:    docker,
+	}
+}
+
+// ComputeStats computes statistics of the samples. Percentiles are computed with the nearest-rank method.
+func ComputeStats(samples []float64) Stats {
+	if len(samples) == 0 {
+		return Stats{}
+	}
+	sorted := make([]float64, len(samples))
+	copy(sorted, samples)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, s := range sorted {
+		sum += s
+	}
+	mean := sum / float64(len(sorted))
+	var sq float64
+	for _, s := range sorted {
+		sq += (s - mean) * (s - mean)
+	}
+	var stdDev float64
+	if len(sorted) > 1 {
+		// sample standard deviation
+		stdDev = math.Sqrt(sq / float64(len(sorted)-1))
+	}
+
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+
+	return Stats{
+		N:      len(sorted),
+		Mean:   mean,
+		Median: median,
+		P95:    sorted[int(math.Ceil(0.95*float64(len(sorted))))-1],
+		StdDev: stdDev,
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// AddCell adds statistics of a single cell to the document and extends the parameter grid. Cells are kept in the order
+// of the grid, i.e., sorted by the depth, number of applications and number of instances, no matter in which order
+// they were measured.
+func (r *ResultDocument) AddCell(depth, apps, instances int, stats Stats) *ResultDocument {
+	i := sort.Search(len(r.Cells), func(i int) bool {
+		c := r.Cells[i]
+		if c.Depth != depth {
+			return c.Depth > depth
+		}
+		if c.Apps != apps {
+			return c.Apps > apps
+		}
+		return c.Instances > instances
+	})
+	r.Cells = slices.Insert(r.Cells, i, Cell{
+		Depth:     depth,
+		Apps:      apps,
+		Instances: instances,
+		Stats:     stats,
+	})
+	r.Grid.Depths = insertSorted(r.Grid.Depths, depth)
+	r.Grid.Apps = insertSorted(r.Grid.Apps, apps)
+	r.Grid.Instances = insertSorted(r.Grid.Instances, instances)
+	return r
+}
+
+// AddValue adds a named value observed in a given cell of the grid (e.g., maximum number of instances)
+func (r *ResultDocument) AddValue(name, unit string, value float64, depth, apps, instances int) *ResultDocument {
+	r.Values = append(r.Values, Value{
+		Name:      name,
+		Unit:      unit,
+		Value:     value,
+		Depth:     depth,
+		Apps:      apps,
+		Instances: instances,
+	})
+	return r
+}
+
+// SetMemory sets memory metrics of a cell, which was added before
+func (r *ResultDocument) SetMemory(depth, apps, instances int, memory Memory) error {
+	for i := range r.Cells {
+		c := &r.Cells[i]
+		if c.Depth == depth && c.Apps == apps && c.Instances == instances {
+			c.Memory = &memory
+			return nil
+		}
+	}
+	return fmt.Errorf("cell of depth %d, %d apps and %d instances is not in the result document", depth, apps, instances)
+}
+
+// HasMemory checks whether memory metrics were gathered for all cells of the document
+func (r *ResultDocument) HasMemory() bool {
+	for _, c := range r.Cells {
+		if c.Memory == nil {
+			return false
+		}
+	}
+	return len(r.Cells) > 0
+}
+
+// MemoryData returns values of a given memory metric (see MemoryMetrics) of all cells as a data cube,
+// i.e., map[depth]map[apps]map[instances]value. Cells without memory metrics are skipped.
+func (r *ResultDocument) MemoryData(metric string) (map[int]map[int]map[int]float64, error) {
+	data := make(map[int]map[int]map[int]float64, 0)
+	for _, c := range r.Cells {
+		if c.Memory == nil {
+			continue
+		}
+		v, err := c.Memory.metric(metric)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := data[c.Depth]; !ok {
+			data[c.Depth] = make(map[int]map[int]float64, 0)
+		}
+		if _, ok := data[c.Depth][c.Apps]; !ok {
+			data[c.Depth][c.Apps] = make(map[int]float64, 0)
+		}
+		data[c.Depth][c.Apps][c.Instances] = v
+	}
+	return data, nil
+}
+
+// Means returns mean values of all cells as a data cube, i.e., map[depth]map[apps]map[instances]mean
+func (r *ResultDocument) Means() map[int]map[int]map[int]float64 {
+	data := make(map[int]map[int]map[int]float64, 0)
+	for _, c := range r.Cells {
+		if _, ok := data[c.Depth]; !ok {
+			data[c.Depth] = make(map[int]map[int]float64, 0)
+		}
+		if _, ok := data[c.Depth][c.Apps]; !ok {
+			data[c.Depth][c.Apps] = make(map[int]float64, 0)
+		}
+		data[c.Depth][c.Apps][c.Instances] = c.Mean
+	}
+	return data
+}
+
+// resultFromData converts a bare data cube to the result document. Only means are known, number of samples is 0.
+func resultFromData(name string, data map[int]map[int]map[int]float64) *ResultDocument {
+	r := &ResultDocument{
+		Name: name,
+		Grid: Grid{
+			Depths:    make([]int, 0),
+			Apps:      make([]int, 0),
+			Instances: make([]int, 0),
+		},
+		Cells:  make([]Cell, 0),
+		Values: make([]Value, 0),
+	}
+	for _, d := range sortedKeys(data) {
+		for _, a := range sortedKeys(data[d]) {
+			for _, i := range sortedKeys(data[d][a]) {
+				r.AddCell(d, a, i, Stats{Mean: data[d][a][i]})
+			}
+		}
+	}
+	return r
+}
+
+// SaveResult saves the result document to the data directory. Document is stored in JSON, means together with the rest
+// of the statistics are stored in CSV file, which first four columns have the same layout as the files written by SaveData.
+func SaveResult(result *ResultDocument, name string) error {
+	err := ExportDataToJSON(dataDir, name, result, "", " ")
+	if err != nil {
+		return err
+	}
+	return exportResultToCSV(dataDir, name, result)
+}
+
+// ImportResult imports the result document from a file. Bare data cubes (i.e., files written by SaveData) are
+// converted to the result document holding means only.
+func ImportResult(path, fileName string) (*ResultDocument, error) {
+	name := strings.TrimSuffix(strings.TrimSuffix(fileName, ".json"), ".csv")
+	if isJSON(fileName) {
+		content, err := os.ReadFile(path + name + ".json")
+		if err != nil {
+			return nil, err
+		}
+		result, err := decodeResult(content)
+		if err != nil {
+			return nil, fmt.Errorf("can't decode %s: %w", fileName, err)
+		}
+		if result.Name == "" {
+			result.Name = name
+		}
+		return result, nil
+	}
+
+	data, err := ImportData(path, fileName)
+	if err != nil {
+		return nil, err
+	}
+	return resultFromData(name, data), nil
+}
+
+// decodeResult decodes the JSON content either as a versioned result document, or as a bare data cube
+func decodeResult(content []byte) (*ResultDocument, error) {
+	var version struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	// bare data cube has only integer keys, so the version is not set
+	if err := json.Unmarshal(content, &version); err != nil {
+		return nil, err
+	}
+	if version.SchemaVersion > ResultSchemaVersion {
+		return nil, fmt.Errorf("unsupported schema version %d, the latest supported version is %d",
+			version.SchemaVersion, ResultSchemaVersion)
+	}
+
+	if version.SchemaVersion == 0 {
+		var data map[int]map[int]map[int]float64
+		if err := json.NewDecoder(bytes.NewReader(content)).Decode(&data); err != nil {
+			return nil, err
+		}
+		return resultFromData("", data), nil
+	}
+
+	var result ResultDocument
+	if err := json.Unmarshal(content, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// exportResultToCSV stores statistics of all cells of the result document to CSV file
+func exportResultToCSV(path, filename string, result *ResultDocument) error {
+	err := createDir(path)
+	if err != nil {
+		return err
+	}
+	outputFile, err := os.Create(path + filename + ".csv")
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(outputFile)
+	// setting a delimiter
+	writer.Comma = ';'
+
+	unit := "[" + result.Unit + "]"
+	header := []string{"Fractal MAIS Depth [-]", "Application Number in Fractal MAIS [-]",
+		"Maximum Number of Instances Deployed by Application [-]", "Mean " + unit, "Median " + unit, "P95 " + unit,
+		"Standard Deviation " + unit, "Minimum " + unit, "Maximum " + unit, "Samples [-]",
+		"Confidence Interval Lower Bound " + unit, "Confidence Interval Upper Bound " + unit, "Confidence Level [-]",
+		"Trimmed Samples [-]", "Allocated Memory [B]", "Allocations [-]", "Peak Heap [B]", "Retained Heap [B]"}
+	if err = writer.Write(header); err != nil {
+		_ = outputFile.Close()
+		return err
+	}
+
+	for _, c := range result.Cells {
+		row := []string{strconv.Itoa(c.Depth), strconv.Itoa(c.Apps), strconv.Itoa(c.Instances),
+			formatFloat(c.Mean), formatFloat(c.Median), formatFloat(c.P95), formatFloat(c.StdDev),
+			formatFloat(c.Min), formatFloat(c.Max), strconv.Itoa(c.N), formatFloat(c.CILower), formatFloat(c.CIUpper),
+			formatFloat(c.Confidence), strconv.Itoa(c.Trimmed)}
+		if c.Memory != nil {
+			row = append(row, formatFloat(c.Memory.AllocatedBytes), formatFloat(c.Memory.Allocations),
+				formatFloat(c.Memory.PeakHeap), formatFloat(c.Memory.RetainedHeap))
+		} else {
+			row = append(row, "", "", "", "")
+		}
+		if err = writer.Write(row); err != nil {
+			_ = outputFile.Close()
+			return err
+		}
+	}
+
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		_ = outputFile.Close()
+		return err
+	}
+	return outputFile.Close()
+}
+
+// formatFloat formats the value in the shortest representation, which is parsed back to the same value
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// insertSorted inserts the value into the sorted slice, unless it is already present
+func insertSorted(values []int, value int) []int {
+	i := sort.SearchInts(values, value)
+	if i < len(values) && values[i] == value {
+		return values
+	}
+	values = append(values, 0)
+	copy(values[i+1:], values[i:])
+	values[i] = value
+	return values
+}
+
+// sortedKeys returns keys of the map in increasing order
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
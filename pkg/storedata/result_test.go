@@ -0,0 +1,112 @@
+package storedata
+
+import (
+	"gotest.tools/assert"
+	"math"
+	"testing"
+)
+
+func TestComputeStats(t *testing.T) {
+	samples := []float64{5, 1, 4, 2, 3, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 100}
+	stats := ComputeStats(samples)
+	t.Logf("Statistics of the samples are %+v", stats)
+	assert.Equal(t, stats.N, 20)
+	assert.Equal(t, stats.Mean, 14.5)
+	assert.Equal(t, stats.Median, 10.5)
+	assert.Equal(t, stats.P95, 19.0)
+	assert.Equal(t, stats.Min, 1.0)
+	assert.Equal(t, stats.Max, 100.0)
+	assert.Assert(t, math.Abs(stats.StdDev-20.8567) < 1e-4)
+	// samples are left untouched
+	assert.Equal(t, samples[0], 5.0)
+
+	single := ComputeStats([]float64{3})
+	assert.DeepEqual(t, single, Stats{N: 1, Mean: 3, Median: 3, P95: 3, Min: 3, Max: 3})
+	assert.DeepEqual(t, ComputeStats(nil), Stats{})
+}
+
+func TestSaveResult(t *testing.T) {
+	defer SetDataDir(DataDir())
+	SetDataDir(t.TempDir())
+
+	result := NewResultDocument("FMAIS generation time", "us", 3, false).
+		AddCell(2, 6, 1, ComputeStats([]float64{1, 2, 3})).
+		AddCell(1, 6, 1, ComputeStats([]float64{4, 5, 6})).
+		AddCell(1, 1, 6, ComputeStats([]float64{7, 8, 9})).
+		AddValue("Maximum number of instances", "-", 42, 2, 6, 1)
+	assert.DeepEqual(t, result.Grid, Grid{Depths: []int{1, 2}, Apps: []int{1, 6}, Instances: []int{1, 6}})
+	// cells are kept in the order of the grid
+	assert.Equal(t, result.Cells[0].Depth, 1)
+	assert.Equal(t, result.Cells[0].Apps, 1)
+	assert.Equal(t, result.Cells[2].Depth, 2)
+
+	err := SaveResult(result, "unittest-result")
+	assert.NilError(t, err)
+
+	imported, err := ImportResult(DataDir(), "unittest-result.json")
+	assert.NilError(t, err)
+	assert.Equal(t, imported.SchemaVersion, ResultSchemaVersion)
+	assert.Equal(t, imported.Environment.NumCPU, result.Environment.NumCPU)
+	assert.DeepEqual(t, imported.Cells, result.Cells)
+	assert.DeepEqual(t, imported.Values, result.Values)
+
+	// result document is readable as a bare data cube from both, JSON and CSV file
+	for _, fileName := range []string{"unittest-result.json", "unittest-result.csv"} {
+		data, err := ImportData(DataDir(), fileName)
+		assert.NilError(t, err)
+		t.Logf("Means imported from %s are %v", fileName, data)
+		assert.DeepEqual(t, data, result.Means())
+	}
+}
+
+func TestImportResultLegacy(t *testing.T) {
+	result, err := ImportResult("../../data/", "benchmark_fmais_2023-03-26_01-59-08.json")
+	assert.NilError(t, err)
+	assert.Equal(t, result.SchemaVersion, 0)
+	assert.Equal(t, result.Name, "benchmark_fmais_2023-03-26_01-59-08")
+	assert.Assert(t, len(result.Cells) > 0)
+	assert.Equal(t, result.Cells[0].N, 0)
+	t.Logf("Legacy file has a grid %+v", result.Grid)
+
+	data, err := ImportData("../../data/", "benchmark_fmais_2023-03-26_01-59-08.json")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, result.Means(), data)
+
+	_, err = decodeResult([]byte(`{"schemaVersion": 99}`))
+	assert.ErrorContains(t, err, "unsupported schema version 99")
+}
+
+func TestResultMemory(t *testing.T) {
+	defer SetDataDir(DataDir())
+	SetDataDir(t.TempDir())
+
+	result := NewResultDocument("FMAIS generation time", "us", 3, false).
+		AddCell(1, 1, 1, ComputeStats([]float64{1, 2, 3})).
+		AddCell(1, 6, 1, ComputeStats([]float64{4, 5, 6}))
+	assert.Assert(t, !result.HasMemory())
+	data, err := result.MemoryData(MemoryPeakHeap)
+	assert.NilError(t, err)
+	assert.Equal(t, len(data), 0)
+
+	err = result.SetMemory(1, 1, 1, Memory{AllocatedBytes: 1024, Allocations: 10, PeakHeap: 4096, RetainedHeap: 512})
+	assert.NilError(t, err)
+	err = result.SetMemory(1, 6, 1, Memory{AllocatedBytes: 2048, Allocations: 20, PeakHeap: 8192, RetainedHeap: 1024})
+	assert.NilError(t, err)
+	err = result.SetMemory(2, 6, 1, Memory{})
+	assert.Assert(t, err != nil)
+	assert.Assert(t, result.HasMemory())
+
+	data, err = result.MemoryData(MemoryAllocations)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, data, map[int]map[int]map[int]float64{1: {1: {1: 10}, 6: {1: 20}}})
+	_, err = result.MemoryData("unknown")
+	assert.Assert(t, err != nil)
+
+	// memory metrics survive the round trip through the JSON file
+	err = SaveResult(result, "unittest-memory")
+	assert.NilError(t, err)
+	imported, err := ImportResult(DataDir(), "unittest-memory.json")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, imported.Cells, result.Cells)
+	t.Logf("Memory of the first cell is %+v", *imported.Cells[0].Memory)
+}
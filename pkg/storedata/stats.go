@@ -0,0 +1,166 @@
+// Package storedata implements a set of utility functions, which are capable of importing/exporting data
+// from/to JSON or CSV file. This file in particular implements outlier trimming of the measured samples,
+// a confidence interval of their mean and a significance test of the difference of two means.
+package storedata
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// TrimSamples returns sorted samples without a given fraction of the lowest and the highest ones (e.g., 0.05 discards
+// 5 % of the samples on each side). At least one sample is always kept.
+func TrimSamples(samples []float64, fraction float64) ([]float64, error) {
+	if fraction < 0 || fraction >= 0.5 {
+		return nil, fmt.Errorf("trimmed fraction should be in range [0, 0.5), got %v", fraction)
+	}
+	sorted := make([]float64, len(samples))
+	copy(sorted, samples)
+	sort.Float64s(sorted)
+
+	cut := int(fraction * float64(len(sorted)))
+	if len(sorted)-2*cut < 1 {
+		cut = (len(sorted) - 1) / 2
+	}
+	return sorted[cut : len(sorted)-cut], nil
+}
+
+// WithConfidenceInterval returns statistics extended with a confidence interval of the mean at a given level
+// (e.g., 0.95). The interval is based on the Student's t-distribution, so it holds for a small number of samples too.
+func (s Stats) WithConfidenceInterval(level float64) (Stats, error) {
+	if level <= 0 || level >= 1 {
+		return s, fmt.Errorf("confidence level should be in range (0, 1), got %v", level)
+	}
+	s.Confidence = level
+	s.CILower, s.CIUpper = s.Mean, s.Mean
+	if s.N < 2 {
+		return s, nil
+	}
+	halfWidth := StudentQuantile((1+level)/2, s.N-1) * s.StdDev / math.Sqrt(float64(s.N))
+	s.CILower = s.Mean - halfWidth
+	s.CIUpper = s.Mean + halfWidth
+	return s, nil
+}
+
+// StudentQuantile returns a quantile of the Student's t-distribution with df degrees of freedom. Quantiles for 1 and
+// 2 degrees of freedom are exact, the rest is approximated with the Cornish-Fisher expansion around the normal
+// quantile (Abramowitz and Stegun, 26.7.5), which is accurate to 3 decimal places from 3 degrees of freedom on.
+func StudentQuantile(p float64, df int) float64 {
+	switch {
+	case df <= 0 || p <= 0 || p >= 1:
+		return math.NaN()
+	case df == 1:
+		return math.Tan(math.Pi * (p - 0.5))
+	case df == 2:
+		return (2*p - 1) / math.Sqrt(2*p*(1-p))
+	}
+
+	z := math.Sqrt2 * math.Erfinv(2*p-1)
+	z2 := z * z
+	nu := float64(df)
+	g1 := z * (z2 + 1) / 4
+	g2 := z * ((5*z2+16)*z2 + 3) / 96
+	g3 := z * (((3*z2+19)*z2+17)*z2 - 15) / 384
+	g4 := z * ((((79*z2+776)*z2+1482)*z2-1920)*z2 - 945) / 92160
+	return z + g1/nu + g2/(nu*nu) + g3/(nu*nu*nu) + g4/(nu*nu*nu*nu)
+}
+
+// WelchTTest performs Welch's t-test of the difference of the means of two sets of samples, which don't need to have
+// the same variance. It returns the t statistic, (fractional) degrees of freedom and a two-sided p-value.
+func WelchTTest(a, b Stats) (float64, float64, float64, error) {
+	if a.N < 2 || b.N < 2 {
+		return 0, 0, 0, fmt.Errorf("at least 2 samples on each side are needed, got %d and %d", a.N, b.N)
+	}
+	va := a.StdDev * a.StdDev / float64(a.N)
+	vb := b.StdDev * b.StdDev / float64(b.N)
+	if va+vb == 0 {
+		// both sides are constant
+		if a.Mean == b.Mean {
+			return 0, float64(a.N + b.N - 2), 1, nil
+		}
+		return math.Copysign(math.Inf(1), b.Mean-a.Mean), float64(a.N + b.N - 2), 0, nil
+	}
+	t := (b.Mean - a.Mean) / math.Sqrt(va+vb)
+	df := (va + vb) * (va + vb) / (va*va/float64(a.N-1) + vb*vb/float64(b.N-1))
+	return t, df, StudentPValue(t, df), nil
+}
+
+// StudentPValue returns a two-sided p-value of the t statistic with df (possibly fractional) degrees of freedom,
+// i.e., a probability that |T| >= |t|
+func StudentPValue(t, df float64) float64 {
+	if df <= 0 || math.IsNaN(t) {
+		return math.NaN()
+	}
+	if math.IsInf(t, 0) {
+		return 0
+	}
+	return regularizedIncompleteBeta(df/2, 0.5, df/(df+t*t))
+}
+
+// regularizedIncompleteBeta returns the regularized incomplete beta function I_x(a, b). It is evaluated with
+// the continued fraction (Numerical Recipes, 6.4), which converges quickly for x < (a+1)/(a+b+2), the symmetry
+// I_x(a, b) = 1 - I_{1-x}(b, a) is used otherwise.
+func regularizedIncompleteBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	lgab, _ := math.Lgamma(a + b)
+	front := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(a, b, x) / a
+	}
+	return 1 - front*betaContinuedFraction(b, a, 1-x)/b
+}
+
+// betaContinuedFraction evaluates the continued fraction of the incomplete beta function with the modified Lentz's method
+func betaContinuedFraction(a, b, x float64) float64 {
+	const maxIterations = 300
+	const epsilon = 1e-15
+	const tiny = 1e-300
+
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		// even step
+		num := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		// odd step
+		num = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,85 @@
+package storedata
+
+import (
+	"gotest.tools/assert"
+	"math"
+	"testing"
+)
+
+func TestTrimSamples(t *testing.T) {
+	samples := []float64{100, 1, 2, 3, 4, 5, 6, 7, 8, -50}
+	trimmed, err := TrimSamples(samples, 0.1)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, trimmed, []float64{1, 2, 3, 4, 5, 6, 7, 8})
+	// samples are left untouched
+	assert.Equal(t, samples[0], 100.0)
+
+	trimmed, err = TrimSamples(samples, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, len(trimmed), len(samples))
+
+	// at least one sample is kept
+	trimmed, err = TrimSamples([]float64{3, 1, 2}, 0.49)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, trimmed, []float64{2})
+
+	_, err = TrimSamples(samples, 0.5)
+	assert.ErrorContains(t, err, "trimmed fraction")
+}
+
+func TestStudentQuantile(t *testing.T) {
+	// reference values of the two-sided 95 % interval
+	for df, expected := range map[int]float64{1: 12.7062, 2: 4.3027, 3: 3.1824, 4: 2.7764, 10: 2.2281, 30: 2.0423, 1000: 1.9623} {
+		q := StudentQuantile(0.975, df)
+		t.Logf("Quantile for %d degrees of freedom is %v", df, q)
+		assert.Assert(t, math.Abs(q-expected) < 5e-3)
+	}
+	assert.Assert(t, math.Abs(StudentQuantile(0.995, 5)-4.0321) < 5e-3)
+	assert.Assert(t, math.IsNaN(StudentQuantile(0.975, 0)))
+}
+
+func TestWithConfidenceInterval(t *testing.T) {
+	stats, err := ComputeStats([]float64{9, 10, 11, 10, 10}).WithConfidenceInterval(0.95)
+	assert.NilError(t, err)
+	t.Logf("Statistics are %+v", stats)
+	assert.Equal(t, stats.Confidence, 0.95)
+	assert.Assert(t, stats.CILower < stats.Mean && stats.Mean < stats.CIUpper)
+	// half width is t(0.975, 4) * stddev / sqrt(5)
+	assert.Assert(t, math.Abs(stats.CIUpper-stats.Mean-2.7764*math.Sqrt(0.5)/math.Sqrt(5)) < 1e-3)
+
+	single, err := ComputeStats([]float64{3}).WithConfidenceInterval(0.95)
+	assert.NilError(t, err)
+	assert.Equal(t, single.CILower, 3.0)
+	assert.Equal(t, single.CIUpper, 3.0)
+
+	_, err = stats.WithConfidenceInterval(1)
+	assert.ErrorContains(t, err, "confidence level")
+}
+
+func TestStudentPValue(t *testing.T) {
+	assert.Assert(t, math.Abs(StudentPValue(2.2281, 10)-0.05) < 1e-4)
+	assert.Assert(t, math.Abs(StudentPValue(-2.2281, 10)-0.05) < 1e-4)
+	// Cauchy distribution
+	assert.Assert(t, math.Abs(StudentPValue(1, 1)-0.5) < 1e-9)
+	assert.Equal(t, StudentPValue(0, 5), 1.0)
+	assert.Equal(t, StudentPValue(math.Inf(1), 5), 0.0)
+	assert.Assert(t, math.IsNaN(StudentPValue(1, 0)))
+}
+
+func TestWelchTTest(t *testing.T) {
+	// example of Welch's t-test with unequal variances (Welch, 1947)
+	a := ComputeStats([]float64{27.5, 21.0, 19.0, 23.6, 17.0, 17.9, 16.9, 20.1, 21.9, 22.6, 23.1, 19.6, 19.0, 21.7, 21.4})
+	b := ComputeStats([]float64{27.1, 22.0, 20.8, 23.4, 23.4, 23.5, 25.8, 22.0, 24.8, 20.2, 21.9, 22.1, 22.9, 20.5, 24.4})
+	tStat, df, p, err := WelchTTest(a, b)
+	assert.NilError(t, err)
+	t.Logf("t = %v, df = %v, p = %v", tStat, df, p)
+	assert.Assert(t, math.Abs(tStat-2.46) < 0.01)
+	assert.Assert(t, math.Abs(df-24.99) < 0.01)
+	assert.Assert(t, math.Abs(p-0.021) < 0.001)
+
+	_, _, p, err = WelchTTest(ComputeStats([]float64{1, 1}), ComputeStats([]float64{1, 1, 1}))
+	assert.NilError(t, err)
+	assert.Equal(t, p, 1.0)
+	_, _, _, err = WelchTTest(Stats{N: 1}, b)
+	assert.ErrorContains(t, err, "at least 2 samples")
+}
@@ -8,48 +8,74 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// dataDir is a directory, where the benchmarked and measured data are stored (and from where they are imported)
+var dataDir = "data/"
+
+// SetDataDir sets a directory, where the data are stored
+func SetDataDir(dir string) {
+	if dir == "" {
+		dir = "."
+	}
+	if !strings.HasSuffix(dir, string(os.PathSeparator)) && !strings.HasSuffix(dir, "/") {
+		dir += string(os.PathSeparator)
+	}
+	dataDir = dir
+}
+
+// DataDir returns a directory, where the data are stored. It always ends with a path separator.
+func DataDir() string {
+	return dataDir
+}
+
+// Timestamp returns the current time formatted to be a part of a file name, e.g., 2023-03-26_01-59-08
+func Timestamp() string {
+	ct := time.Now()
+	ts := ct.Format(time.DateOnly) + "_" + ct.Format(time.TimeOnly)
+	return strings.ReplaceAll(ts, ":", "-")
+}
+
 // ExportDataToJSON stores generated during benchmarking data to JSON file
 func ExportDataToJSON(path, filename string, data any, prefix, indent string) error {
 
 	// marshal data to JSON
 	out, err := json.MarshalIndent(data, prefix, indent)
 	if err != nil {
-		log.Panicf("Something went wrong during marshalling of data into JSON.. %v\n", err)
+		return fmt.Errorf("couldn't marshal data into JSON: %w", err)
 	}
 
 	// export JSON data to file
+	err = createDir(path)
+	if err != nil {
+		return err
+	}
 	err = os.WriteFile(path+filename+".json", out, 0644)
 	if err != nil {
-		log.Panicf("Something went wrong when the data were written to the file... %v\n", err)
+		return fmt.Errorf("couldn't write data to %s: %w", path+filename+".json", err)
 	}
 
 	return nil
 }
 
-// importDataFromJSON imports data from JSON file
+// importDataFromJSON imports data from JSON file. Both, bare data cubes and versioned result documents
+// (see SaveResult) are accepted, means are taken from the latter.
 func importDataFromJSON(path, filename string) (map[int]map[int]map[int]float64, error) {
-	sourceFile, err := os.Open(path + filename + ".json")
+	content, err := os.ReadFile(path + filename + ".json")
 	if err != nil {
 		return nil, err
 	}
 
-	var out map[int]map[int]map[int]float64
-	if err := json.NewDecoder(sourceFile).Decode(&out); err != nil {
-		return nil, err
-	}
-
-	err = sourceFile.Close()
+	result, err := decodeResult(content)
 	if err != nil {
 		return nil, err
 	}
 
-	return out, nil
+	return result.Means(), nil
 }
 
 // importDataFromJSONMeErtCore imports data from JSON file
@@ -76,6 +102,10 @@ func importDataFromJSONMeErtCore(path, filename string) (map[int]float64, error)
 func exportDataToCSV(path, filename string, data map[int]map[int]map[int]float64, names ...string) error {
 
 	// creating a new file to store CSV data
+	err := createDir(path)
+	if err != nil {
+		return err
+	}
 	outputFile, err := os.Create(path + filename + ".csv")
 	if err != nil {
 		return err
@@ -123,8 +153,9 @@ func importDataFromCSV(path, filename string) (map[int]map[int]map[int]float64,
 	// open a file
 	file, err := os.Open(path + filename + ".csv")
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	defer file.Close()
 
 	// read csv values
 	csvReader := csv.NewReader(file)
@@ -139,7 +170,7 @@ func importDataFromCSV(path, filename string) (map[int]map[int]map[int]float64,
 			break
 		}
 		if err != nil {
-			log.Fatal(err)
+			return nil, fmt.Errorf("couldn't read %s: %w", path+filename+".csv", err)
 		}
 
 		// check if it is a first line (shouldn't be parsed)
@@ -192,18 +223,16 @@ func importDataFromCSV(path, filename string) (map[int]map[int]map[int]float64,
 // SaveData saves data to a file (both, .csv and .json)
 func SaveData(benchmarkedData map[int]map[int]map[int]float64, name string) error {
 
-	err := ExportDataToJSON("data/", name, benchmarkedData, "", " ")
+	err := ExportDataToJSON(dataDir, name, benchmarkedData, "", " ")
 	if err != nil {
-		log.Panicf("Something went wrong during storing of the data in JSON file... %v\n", err)
-		return err
+		return fmt.Errorf("couldn't store data in JSON file: %w", err)
 	}
 
-	err = exportDataToCSV("data/", name, benchmarkedData, "Fractal MAIS Depth [-]",
+	err = exportDataToCSV(dataDir, name, benchmarkedData, "Fractal MAIS Depth [-]",
 		"Application Number in Fractal MAIS [-]", "Maximum Number of Instances Deployed by Application [-]",
 		"Time [us]")
 	if err != nil {
-		log.Panicf("Something went wrong during storing of the data in CSV file... %v\n", err)
-		return err
+		return fmt.Errorf("couldn't store data in CSV file: %w", err)
 	}
 
 	return nil
@@ -262,6 +291,14 @@ func ImportDataMeErtCore(path, fileName string) (map[int]float64, error) {
 	return data, nil
 }
 
+// createDir creates a directory (if it doesn't exist yet), where the data are stored
+func createDir(path string) error {
+	if path == "" {
+		return nil
+	}
+	return os.MkdirAll(path, 0755)
+}
+
 // isJSON checks if file has .json extension
 func isJSON(name string) bool {
 	return strings.Contains(name, ".json")
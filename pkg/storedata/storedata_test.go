@@ -69,3 +69,29 @@ func Test_exportDataFromCSV(t *testing.T) {
 	assert.Equal(t, ok, true)
 	assert.Equal(t, value, 3.1457)
 }
+
+func TestSetDataDir(t *testing.T) {
+	defer SetDataDir(DataDir())
+
+	dir := t.TempDir() + "/nested"
+	SetDataDir(dir)
+	assert.Equal(t, DataDir(), dir+"/")
+
+	// directory is created on export
+	data := map[int]map[int]map[int]float64{2: {10: {5: 1.5}}}
+	err := SaveData(data, "unittest-datadir")
+	assert.NilError(t, err)
+	imported, err := ImportData(DataDir(), "unittest-datadir.csv")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, imported, data)
+}
+
+func Test_ExportDataToJSONErrors(t *testing.T) {
+	// data, which can't be marshalled, are reported instead of panicking
+	err := ExportDataToJSON(t.TempDir()+"/", "unmarshallable", map[string]any{"ch": make(chan int)}, "", " ")
+	assert.ErrorContains(t, err, "couldn't marshal data into JSON")
+
+	// a missing CSV file is reported instead of terminating the program
+	_, err = importDataFromCSV(t.TempDir()+"/", "missing")
+	assert.Assert(t, err != nil)
+}
@@ -0,0 +1,341 @@
+// Package sweep implements a specification of a parameter sweep, i.e., a grid of the FMAIS depths, numbers of
+// applications and numbers of instances per application, over which the benchmarks are run and from which
+// the figures pick their slices.
+//
+// Each axis of the grid is specified by a comma-separated list of terms, where each term is either:
+//   - a single value, e.g., "26",
+//   - an inclusive range with an optional step (1 by default), e.g., "1:101:5" or "1:4",
+//   - a log-spaced range of a given number of points, e.g., "log:1:1000:7".
+//
+// Terms may be combined, e.g., "1,6:96:10,log:100:1000:3". Values of an axis are sorted and unique.
+//
+// Figures show slices of the grid, i.e., curves for selected values of the other parameters. Slices are selected
+// explicitly (see Slices), otherwise representative values are picked from the grid.
+package sweep
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Axis holds values of a single swept parameter in increasing order
+type Axis []int
+
+// Spec structure describes a grid of the swept parameters
+type Spec struct {
+	Depths    Axis   `json:"depths"`    // depths of the FMAIS
+	Apps      Axis   `json:"apps"`      // numbers of applications in the FMAIS
+	Instances Axis   `json:"instances"` // maximum numbers of instances deployed by application
+	Slices    Slices `json:"-"`         // values shown in the figures, they don't change the grid
+}
+
+// Slices structure selects values of the grid, which are shown in the figures. Only values present in the grid
+// are taken into account. If none of the selected values of an axis is present, representative values
+// are picked from the grid instead (see Axis.Slice).
+type Slices struct {
+	Depths    Axis // depths of the curves (the deepest one is shown in the joint figures)
+	Apps      Axis // numbers of applications, a figure of the dependency on the instances is plotted for each of them
+	CurveApps Axis // numbers of applications of the curves in the figures of the dependency on the depth
+	Instances Axis // numbers of instances per application, a figure is plotted for each of them
+}
+
+// PaperSlices returns slices, which were shown in the figures of the paper
+func PaperSlices() Slices {
+	return Slices{
+		Depths:    Axis{2, 3, 4},
+		Apps:      Axis{26, 56, 96},
+		CurveApps: Axis{26, 51, 76, 96},
+		Instances: Axis{26, 56, 96},
+	}
+}
+
+// ParseAxis parses an axis specification (see package documentation)
+func ParseAxis(spec string) (Axis, error) {
+	values := make([]int, 0)
+	for _, term := range strings.Split(spec, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		var a Axis
+		var err error
+		switch {
+		case strings.HasPrefix(term, "log:"):
+			var bounds []int
+			bounds, err = parseInts(strings.TrimPrefix(term, "log:"), 3)
+			if err == nil {
+				a, err = LogSpaced(bounds[0], bounds[1], bounds[2])
+			}
+		case strings.Contains(term, ":"):
+			a, err = parseRange(term)
+		default:
+			var v int
+			v, err = strconv.Atoi(term)
+			a = Axis{v}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't parse term '%s' of the sweep axis: %w", term, err)
+		}
+		values = append(values, a...)
+	}
+
+	axis := List(values...)
+	if err := axis.Validate(); err != nil {
+		return nil, err
+	}
+	return axis, nil
+}
+
+// Range returns an axis with values from start to stop (inclusive) with a given step
+func Range(start, stop, step int) (Axis, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step should be positive, got %d", step)
+	}
+	if start > stop {
+		return nil, fmt.Errorf("start %d is greater than stop %d", start, stop)
+	}
+	a := make(Axis, 0, (stop-start)/step+1)
+	for v := start; v <= stop; v += step {
+		a = append(a, v)
+	}
+	return a, nil
+}
+
+// LogSpaced returns an axis with n values spaced evenly on a log scale from start to stop (both inclusive).
+// Values are rounded to integers, so the axis may hold fewer than n values, if the range is narrow.
+func LogSpaced(start, stop, n int) (Axis, error) {
+	if start <= 0 {
+		return nil, fmt.Errorf("log-spaced range should start at a positive value, got %d", start)
+	}
+	if start > stop {
+		return nil, fmt.Errorf("start %d is greater than stop %d", start, stop)
+	}
+	if n < 2 {
+		return nil, fmt.Errorf("log-spaced range should have at least 2 points, got %d", n)
+	}
+	values := make([]int, 0, n)
+	ratio := math.Log(float64(stop) / float64(start))
+	for i := 0; i < n; i++ {
+		values = append(values, int(math.Round(float64(start)*math.Exp(ratio*float64(i)/float64(n-1)))))
+	}
+	return List(values...), nil
+}
+
+// List returns an axis of explicitly listed values (sorted and without duplicates)
+func List(values ...int) Axis {
+	sorted := make([]int, len(values))
+	copy(sorted, values)
+	sort.Ints(sorted)
+	a := make(Axis, 0, len(sorted))
+	for i, v := range sorted {
+		if i > 0 && sorted[i-1] == v {
+			continue
+		}
+		a = append(a, v)
+	}
+	return a
+}
+
+// Validate checks that the axis is not empty and holds positive values only
+func (a Axis) Validate() error {
+	if len(a) == 0 {
+		return fmt.Errorf("sweep axis is empty")
+	}
+	if a[0] <= 0 {
+		return fmt.Errorf("sweep axis should hold positive values only, got %d", a[0])
+	}
+	return nil
+}
+
+// Min returns the lowest value of the axis (or 0, if the axis is empty)
+func (a Axis) Min() int {
+	if len(a) == 0 {
+		return 0
+	}
+	return a[0]
+}
+
+// Max returns the greatest value of the axis (or 0, if the axis is empty)
+func (a Axis) Max() int {
+	if len(a) == 0 {
+		return 0
+	}
+	return a[len(a)-1]
+}
+
+// Contains checks whether the axis holds a given value
+func (a Axis) Contains(value int) bool {
+	i := sort.SearchInts(a, value)
+	return i < len(a) && a[i] == value
+}
+
+// Pick returns at most n representative values of the axis, which are spread evenly over it. The lowest value
+// (typically a trivial configuration) is skipped, unless the axis holds n values or fewer.
+func (a Axis) Pick(n int) Axis {
+	if len(a) <= n || n <= 0 {
+		return a
+	}
+	picked := make(Axis, 0, n)
+	for k := 1; k <= n; k++ {
+		picked = append(picked, a[int(math.Round(float64(k*(len(a)-1))/float64(n)))])
+	}
+	return picked
+}
+
+// Slice returns selected values, which are present in the axis. If none of them is present, at most n
+// representative values are picked from the axis (see Pick).
+func (a Axis) Slice(selected Axis, n int) Axis {
+	if values := a.Intersect(selected); len(values) > 0 {
+		return values
+	}
+	return a.Pick(n)
+}
+
+// Ends returns the lowest and the greatest value of the axis
+func (a Axis) Ends() Axis {
+	if len(a) <= 2 {
+		return a
+	}
+	return Axis{a.Min(), a.Max()}
+}
+
+// Intersect returns values, which are present in both axes
+func (a Axis) Intersect(other Axis) Axis {
+	out := make(Axis, 0)
+	for _, v := range a {
+		if other.Contains(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// String returns the axis as a comma-separated list of values, which can be parsed back with ParseAxis
+func (a Axis) String() string {
+	values := make([]string, 0, len(a))
+	for _, v := range a {
+		values = append(values, strconv.Itoa(v))
+	}
+	return strings.Join(values, ",")
+}
+
+// Default returns a grid, which was used by the benchmarks originally: all depths up to maxDepth and numbers of
+// applications and instances starting at 1 with a step of 5 up to maxApps+1 and maxInstances+1 respectively.
+// Figures show the slices of the paper.
+func Default(maxDepth, maxApps, maxInstances int) Spec {
+	depths, _ := Range(1, maxDepth, 1)
+	apps, _ := Range(1, maxApps+1, 5)
+	instances, _ := Range(1, maxInstances+1, 5)
+	return Spec{
+		Depths:    depths,
+		Apps:      apps,
+		Instances: instances,
+		Slices:    PaperSlices(),
+	}
+}
+
+// Parse parses a specification of each axis of the grid
+func Parse(depths, apps, instances string) (Spec, error) {
+	var s Spec
+	var err error
+	s.Depths, err = ParseAxis(depths)
+	if err != nil {
+		return Spec{}, fmt.Errorf("depths: %w", err)
+	}
+	s.Apps, err = ParseAxis(apps)
+	if err != nil {
+		return Spec{}, fmt.Errorf("apps: %w", err)
+	}
+	s.Instances, err = ParseAxis(instances)
+	if err != nil {
+		return Spec{}, fmt.Errorf("instances: %w", err)
+	}
+	return s, nil
+}
+
+// FromData derives a grid from the benchmarked data, i.e., map[depth]map[apps]map[instances]value
+func FromData(data map[int]map[int]map[int]float64) Spec {
+	depths := make([]int, 0)
+	apps := make([]int, 0)
+	instances := make([]int, 0)
+	for d, m1 := range data {
+		depths = append(depths, d)
+		for a, m2 := range m1 {
+			apps = append(apps, a)
+			for i := range m2 {
+				instances = append(instances, i)
+			}
+		}
+	}
+	return Spec{
+		Depths:    List(depths...),
+		Apps:      List(apps...),
+		Instances: List(instances...),
+	}
+}
+
+// Validate checks that none of the axes is empty and all of them hold positive values only
+func (s Spec) Validate() error {
+	if err := s.Depths.Validate(); err != nil {
+		return fmt.Errorf("depths: %w", err)
+	}
+	if err := s.Apps.Validate(); err != nil {
+		return fmt.Errorf("apps: %w", err)
+	}
+	if err := s.Instances.Validate(); err != nil {
+		return fmt.Errorf("instances: %w", err)
+	}
+	return nil
+}
+
+// Intersect returns a grid of values, which are present in both grids. Slices of the grid are kept.
+func (s Spec) Intersect(other Spec) Spec {
+	return Spec{
+		Depths:    s.Depths.Intersect(other.Depths),
+		Apps:      s.Apps.Intersect(other.Apps),
+		Instances: s.Instances.Intersect(other.Instances),
+		Slices:    s.Slices,
+	}
+}
+
+// Cells returns the number of cells of the grid, i.e., the number of parameter combinations
+func (s Spec) Cells() int {
+	return len(s.Depths) * len(s.Apps) * len(s.Instances)
+}
+
+// String returns a human-readable description of the grid
+func (s Spec) String() string {
+	return fmt.Sprintf("depths [%s], apps [%s], instances [%s]", s.Depths, s.Apps, s.Instances)
+}
+
+// parseRange parses an inclusive range in a form start:stop or start:stop:step
+func parseRange(term string) (Axis, error) {
+	if strings.Count(term, ":") == 1 {
+		term += ":1"
+	}
+	bounds, err := parseInts(term, 3)
+	if err != nil {
+		return nil, err
+	}
+	return Range(bounds[0], bounds[1], bounds[2])
+}
+
+// parseInts parses exactly n colon-separated integers
+func parseInts(s string, n int) ([]int, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d colon-separated values, got %d", n, len(parts))
+	}
+	out := make([]int, 0, n)
+	for _, p := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
@@ -0,0 +1,85 @@
+package sweep
+
+import (
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestParseAxis(t *testing.T) {
+	a, err := ParseAxis("1:21:5")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, a, Axis{1, 6, 11, 16, 21})
+
+	a, err = ParseAxis("1:4")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, a, Axis{1, 2, 3, 4})
+
+	// terms are combined, sorted and deduplicated
+	a, err = ParseAxis("50, 10,20:30:10, 10")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, a, Axis{10, 20, 30, 50})
+
+	a, err = ParseAxis("log:1:1000:4")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, a, Axis{1, 10, 100, 1000})
+	t.Logf("Axis is %s", a)
+
+	for _, spec := range []string{"", "0:5", "a", "5:1", "1:10:0", "1:2:3:4", "log:0:10:3", "log:1:10:1"} {
+		_, err = ParseAxis(spec)
+		t.Logf("Parsing '%s' fails with: %v", spec, err)
+		assert.Assert(t, err != nil)
+	}
+}
+
+func TestLogSpaced(t *testing.T) {
+	// rounding of a narrow range produces duplicates, which are removed
+	a, err := LogSpaced(1, 3, 5)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, a, Axis{1, 2, 3})
+}
+
+func TestPick(t *testing.T) {
+	apps := Default(4, 100, 100).Apps
+	assert.DeepEqual(t, apps.Pick(4), Axis{26, 51, 76, 101})
+	assert.DeepEqual(t, apps.Pick(3), Axis{36, 66, 101})
+	assert.DeepEqual(t, Axis{1, 2, 3, 4}.Pick(3), Axis{2, 3, 4})
+	assert.DeepEqual(t, Axis{1, 2}.Pick(3), Axis{1, 2})
+}
+
+func TestSlice(t *testing.T) {
+	// default grid shows the slices of the paper
+	s := Default(4, 100, 100)
+	assert.DeepEqual(t, s.Depths.Slice(s.Slices.Depths, 3), Axis{2, 3, 4})
+	assert.DeepEqual(t, s.Apps.Slice(s.Slices.CurveApps, 4), Axis{26, 51, 76, 96})
+	assert.DeepEqual(t, s.Instances.Slice(s.Slices.Instances, 3), Axis{26, 56, 96})
+	assert.DeepEqual(t, s.Instances.Slice(s.Slices.Instances, 3).Ends(), Axis{26, 96})
+
+	// only selected values present in the grid are shown, representative values are picked otherwise
+	apps := Axis{6, 11, 16, 21, 26, 31}
+	assert.DeepEqual(t, apps.Slice(Axis{26, 56, 96}, 3), Axis{26})
+	assert.DeepEqual(t, apps.Slice(Axis{56, 96}, 3), Axis{16, 21, 31})
+	assert.DeepEqual(t, apps.Slice(nil, 2), apps.Pick(2))
+	assert.DeepEqual(t, Axis{4}.Ends(), Axis{4})
+}
+
+func TestSpec(t *testing.T) {
+	s := Default(4, 100, 100)
+	assert.NilError(t, s.Validate())
+	assert.Equal(t, s.Cells(), 4*21*21)
+	assert.Equal(t, s.Apps.Min(), 1)
+	assert.Equal(t, s.Apps.Max(), 101)
+	t.Logf("Default grid is %s", s)
+
+	data := map[int]map[int]map[int]float64{
+		2: {6: {1: 1, 11: 2}},
+		3: {6: {1: 3}, 16: {1: 4}},
+	}
+	fromData := FromData(data)
+	assert.DeepEqual(t, fromData, Spec{Depths: Axis{2, 3}, Apps: Axis{6, 16}, Instances: Axis{1, 11}})
+	assert.DeepEqual(t, s.Intersect(fromData), Spec{Depths: Axis{2, 3}, Apps: Axis{6, 16}, Instances: Axis{1, 11}, Slices: PaperSlices()})
+
+	_, err := Parse("1:4", "1:101:5", "")
+	assert.ErrorContains(t, err, "instances: sweep axis is empty")
+	empty := Spec{Depths: Axis{1}, Apps: Axis{1}}
+	assert.ErrorContains(t, empty.Validate(), "instances")
+}
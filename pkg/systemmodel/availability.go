@@ -0,0 +1,103 @@
+// Package systemmodel implements means of Fractal MAIS system model. This file in particular holds aspects, which
+// describe failure and recovery of the instances over time, i.e., Mean Time Between Failures (MTBF)
+// and Mean Time To Repair (MTTR).
+package systemmodel
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const mtbfKey = "MTBF" // represents Mean Time Between Failures
+const mttrKey = "MTTR" // represents Mean Time To Repair
+
+// SetMTBF sets Mean Time Between Failures Aspect for an Instance
+func (i *Instance) SetMTBF(mtbf float64) *Instance {
+	if i.Aspect == nil {
+		i.Aspect = make(map[string]string, 0)
+	}
+	i.Aspect[mtbfKey] = strconv.FormatFloat(mtbf, 'f', -1, 64)
+	return i
+}
+
+// GetMTBF returns Mean Time Between Failures Aspect of an Instance
+func (i *Instance) GetMTBF() (float64, error) {
+	return parseTimeAspect(i.Aspect, mtbfKey, "instance "+i.Name)
+}
+
+// SetMTTR sets Mean Time To Repair Aspect for an Instance
+func (i *Instance) SetMTTR(mttr float64) *Instance {
+	if i.Aspect == nil {
+		i.Aspect = make(map[string]string, 0)
+	}
+	i.Aspect[mttrKey] = strconv.FormatFloat(mttr, 'f', -1, 64)
+	return i
+}
+
+// GetMTTR returns Mean Time To Repair Aspect of an Instance
+func (i *Instance) GetMTTR() (float64, error) {
+	return parseTimeAspect(i.Aspect, mttrKey, "instance "+i.Name)
+}
+
+// SetMTBF sets Mean Time Between Failures Aspect for an Application. It applies to all its instances,
+// which do not define their own MTBF.
+func (a *Application) SetMTBF(mtbf float64) *Application {
+	if a.Aspect == nil {
+		a.Aspect = make(map[string]string, 0)
+	}
+	a.Aspect[mtbfKey] = strconv.FormatFloat(mtbf, 'f', -1, 64)
+	return a
+}
+
+// GetMTBF returns Mean Time Between Failures Aspect of an Application
+func (a *Application) GetMTBF() (float64, error) {
+	return parseTimeAspect(a.Aspect, mtbfKey, "an Application")
+}
+
+// SetMTTR sets Mean Time To Repair Aspect for an Application. It applies to all its instances,
+// which do not define their own MTTR.
+func (a *Application) SetMTTR(mttr float64) *Application {
+	if a.Aspect == nil {
+		a.Aspect = make(map[string]string, 0)
+	}
+	a.Aspect[mttrKey] = strconv.FormatFloat(mttr, 'f', -1, 64)
+	return a
+}
+
+// GetMTTR returns Mean Time To Repair Aspect of an Application
+func (a *Application) GetMTTR() (float64, error) {
+	return parseTimeAspect(a.Aspect, mttrKey, "an Application")
+}
+
+// GetApplication returns an Application (or VI), which the instance belongs to
+func (sm *SystemModel) GetApplication(inst *Instance) (*Application, error) {
+	appName := "VI"
+	if inst.IsApp() {
+		var err error
+		appName, err = inst.GetAppName()
+		if err != nil {
+			return nil, err
+		}
+	}
+	app, ok := sm.Applications[appName]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", appName, ErrAppNotFound)
+	}
+	return app, nil
+}
+
+// parseTimeAspect parses a time-related aspect (MTBF or MTTR), which should be a positive number
+func parseTimeAspect(aspects map[string]string, key, owner string) (float64, error) {
+	str, ok := aspects[key]
+	if !ok {
+		return 0, fmt.Errorf("%s: %s %w", owner, key, ErrAspectMissing)
+	}
+	value, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, fmt.Errorf("can't parse string %s to float64", str)
+	}
+	if value <= 0 {
+		return 0, fmt.Errorf("%s of %s should be positive, got %v", key, owner, value)
+	}
+	return value, nil
+}
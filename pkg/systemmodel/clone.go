@@ -0,0 +1,73 @@
+// Package systemmodel implements means of Fractal MAIS system model. This file in particular implements copying
+// of the System Model.
+package systemmodel
+
+// Clone returns a deep copy of the SystemModel. The copy shares no instances, relations, aspects or applications
+// with the original SystemModel, thus it can be freely modified (e.g., by reliability computation).
+func (sm *SystemModel) Clone() *SystemModel {
+	clone := &SystemModel{
+		Depth:        sm.Depth,
+		Layers:       make(map[int]*Layer, len(sm.Layers)),
+		Applications: make(map[string]*Application, len(sm.Applications)),
+	}
+	if sm.VIcount != nil {
+		viCount := *sm.VIcount
+		clone.VIcount = &viCount
+	}
+
+	// this map keeps track of already copied instances, so that the relations point to the copies
+	copies := make(map[*Instance]*Instance, sm.GetTotalNumberOfInstances())
+	for level, layer := range sm.Layers {
+		clonedLayer := &Layer{
+			Instances:     make([]*Instance, 0, len(layer.Instances)),
+			VIwasDeployed: layer.VIwasDeployed,
+		}
+		for _, inst := range layer.Instances {
+			clonedLayer.Instances = append(clonedLayer.Instances, inst.clone(copies))
+		}
+		clone.Layers[level] = clonedLayer
+	}
+
+	for name, app := range sm.Applications {
+		clone.Applications[name] = app.Clone()
+	}
+
+	return clone
+}
+
+// clone copies an instance together with all instances it relates to. Already copied instances are reused.
+func (i *Instance) clone(copies map[*Instance]*Instance) *Instance {
+	if c, ok := copies[i]; ok {
+		return c
+	}
+	c := &Instance{
+		Name:      i.Name,
+		Type:      i.Type,
+		Relations: make([]*Instance, 0, len(i.Relations)),
+		Aspect:    copyAspects(i.Aspect),
+	}
+	copies[i] = c
+	for _, rel := range i.Relations {
+		c.Relations = append(c.Relations, rel.clone(copies))
+	}
+	return c
+}
+
+// Clone returns a deep copy of the Application
+func (a *Application) Clone() *Application {
+	return &Application{
+		Rules:       a.Rules,
+		Probability: a.Probability,
+		State:       a.State,
+		Aspect:      copyAspects(a.Aspect),
+	}
+}
+
+// copyAspects returns a copy of the aspects map
+func copyAspects(aspects map[string]string) map[string]string {
+	res := make(map[string]string, len(aspects))
+	for k, v := range aspects {
+		res[k] = v
+	}
+	return res
+}
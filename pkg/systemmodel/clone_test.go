@@ -0,0 +1,35 @@
+package systemmodel
+
+import (
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	sm := CreateSystemModelDepth4()
+	clone := sm.Clone()
+
+	assert.Equal(t, clone.Depth, sm.Depth)
+	assert.Equal(t, len(clone.Layers), len(sm.Layers))
+	assert.Equal(t, len(clone.Applications), len(sm.Applications))
+	assert.Equal(t, clone.GetTotalNumberOfInstances(), sm.GetTotalNumberOfInstances())
+
+	// relations of the copy should point to the instances of the copy
+	root := clone.Layers[1].Instances[0]
+	vi1, err := clone.GetInstance("VI#2-1")
+	assert.NilError(t, err)
+	assert.Assert(t, root.Relations[0] == vi1)
+
+	// modifying the copy does not affect the original
+	vi1.SetPriority(0.99)
+	origVI1, err := sm.GetInstance("VI#2-1")
+	assert.NilError(t, err)
+	prty, err := origVI1.GetPriority()
+	assert.NilError(t, err)
+	assert.Equal(t, prty, 0.25)
+
+	clone.Applications["App#1"].SetPriority(0.5)
+	appPrty, err := sm.Applications["App#1"].GetPriority()
+	assert.NilError(t, err)
+	assert.Equal(t, appPrty, 0.19)
+}
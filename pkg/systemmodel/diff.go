@@ -0,0 +1,326 @@
+// Package systemmodel implements means of Fractal MAIS system model. This file in particular implements structural
+// comparison of two System Models.
+package systemmodel
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// applicationPresence is a name of the field reported when an Application was added to or removed from the System Model
+const applicationPresence = "Application"
+
+// pathSeparator separates names of the instances in a path of an instance
+const pathSeparator = "/"
+
+// ModelDiff structure carries differences between two System Models in a machine-readable form. Instances are
+// identified by their paths, i.e., names of all instances from the root down to the instance separated with '/'
+// (e.g., VI#1/VI#2-1/App#3-2-1). Siblings with the same name are distinguished by their order, e.g., VI#2-1[2]
+// is the second instance named VI#2-1 deployed by the same parent.
+type ModelDiff struct {
+	AddedInstances      []string            `json:"addedInstances"`      // paths of instances, which are present only in the second System Model
+	RemovedInstances    []string            `json:"removedInstances"`    // paths of instances, which are present only in the first System Model
+	MovedInstances      []InstanceMove      `json:"movedInstances"`      // instances, which were deployed by another parent in the second System Model
+	ChangedAspects      []AspectChange      `json:"changedAspects"`      // aspects, which differ for instances present in both System Models
+	ChangedApplications []ApplicationChange `json:"changedApplications"` // differences in the Applications (state, rules, aspects, presence)
+}
+
+// InstanceMove structure describes an instance (together with all instances it has deployed), which has changed
+// its parent, i.e., it was removed from the relations of one VI and added to the relations of another one.
+type InstanceMove struct {
+	Instance  string `json:"instance"`  // path of the instance in the first System Model
+	OldParent string `json:"oldParent"` // path of the parent in the first System Model
+	NewParent string `json:"newParent"` // path of the parent in the second System Model
+}
+
+// AspectChange structure describes a change of an instance aspect. Empty value means that the aspect was not set.
+type AspectChange struct {
+	Instance string `json:"instance"` // path of the instance in the second System Model
+	Aspect   string `json:"aspect"`   // name of the aspect (e.g., Reliability)
+	Old      string `json:"old"`      // value in the first System Model
+	New      string `json:"new"`      // value in the second System Model
+}
+
+// ApplicationChange structure describes a change of an Application. Field is either "State", "Rules", "Probability",
+// a name of the changed aspect, or "Application" when the whole Application was added ("absent" -> "present")
+// or removed ("present" -> "absent").
+type ApplicationChange struct {
+	Application string `json:"application"` // name of the Application
+	Field       string `json:"field"`       // changed field
+	Old         string `json:"old"`         // value in the first System Model
+	New         string `json:"new"`         // value in the second System Model
+}
+
+// Diff compares two System Models and reports added, removed and moved instances (matched by their paths),
+// changed aspects of the instances and changed Applications. An instance is reported as moved, if its name is
+// unique among the unmatched instances of both System Models and its parent differs; instances it has deployed
+// are then matched under the new parent. The output is sorted, thus it is stable for the same input.
+func Diff(a, b *SystemModel) *ModelDiff {
+	diff := &ModelDiff{
+		AddedInstances:      make([]string, 0),
+		RemovedInstances:    make([]string, 0),
+		MovedInstances:      make([]InstanceMove, 0),
+		ChangedAspects:      make([]AspectChange, 0),
+		ChangedApplications: make([]ApplicationChange, 0),
+	}
+
+	instA := a.instancesByPath()
+	instB := b.instancesByPath()
+	matched := diff.matchInstances(instA, instB)
+	for pathA, pathB := range matched {
+		inst, other := instA[pathA], instB[pathB]
+		for _, key := range unionOfKeys(inst.Aspect, other.Aspect) {
+			if inst.Aspect[key] != other.Aspect[key] {
+				diff.ChangedAspects = append(diff.ChangedAspects, AspectChange{
+					Instance: pathB,
+					Aspect:   key,
+					Old:      inst.Aspect[key],
+					New:      other.Aspect[key],
+				})
+			}
+		}
+	}
+	for path := range instA {
+		if _, ok := matched[path]; !ok {
+			diff.RemovedInstances = append(diff.RemovedInstances, path)
+		}
+	}
+	matchedB := make(map[string]bool, len(matched))
+	for _, pathB := range matched {
+		matchedB[pathB] = true
+	}
+	for path := range instB {
+		if !matchedB[path] {
+			diff.AddedInstances = append(diff.AddedInstances, path)
+		}
+	}
+
+	for name, app := range a.Applications {
+		other, ok := b.Applications[name]
+		if !ok {
+			diff.addApplicationChange(name, applicationPresence, "present", "absent")
+			continue
+		}
+		diff.addApplicationChange(name, "State", strconv.FormatBool(app.State), strconv.FormatBool(other.State))
+		diff.addApplicationChange(name, "Rules", strconv.Itoa(app.Rules), strconv.Itoa(other.Rules))
+		diff.addApplicationChange(name, "Probability", strconv.FormatFloat(float64(app.Probability), 'f', -1, 32),
+			strconv.FormatFloat(float64(other.Probability), 'f', -1, 32))
+		for _, key := range unionOfKeys(app.Aspect, other.Aspect) {
+			diff.addApplicationChange(name, key, app.Aspect[key], other.Aspect[key])
+		}
+	}
+	for name := range b.Applications {
+		if _, ok := a.Applications[name]; !ok {
+			diff.addApplicationChange(name, applicationPresence, "absent", "present")
+		}
+	}
+
+	diff.sort()
+	return diff
+}
+
+// IsEmpty returns true, if no difference was found
+func (d *ModelDiff) IsEmpty() bool {
+	return len(d.AddedInstances) == 0 && len(d.RemovedInstances) == 0 &&
+		len(d.ChangedAspects) == 0 && len(d.ChangedApplications) == 0
+}
+
+// addApplicationChange records a change of an Application field, if the values differ
+func (d *ModelDiff) addApplicationChange(app, field, oldValue, newValue string) {
+	if oldValue == newValue {
+		return
+	}
+	d.ChangedApplications = append(d.ChangedApplications, ApplicationChange{
+		Application: app,
+		Field:       field,
+		Old:         oldValue,
+		New:         newValue,
+	})
+}
+
+// matchInstances pairs instances of the first System Model with the instances of the second one. Instances with
+// the same path are paired first. The remaining ones are visited from the top and paired either under the pair
+// of their parent (when an ancestor was moved), or with the only unmatched instance of the same name, which is
+// recorded as a move. Returned map carries paths in the second System Model indexed by paths in the first one.
+func (d *ModelDiff) matchInstances(instA, instB map[string]*Instance) map[string]string {
+	matched := make(map[string]string, len(instA))
+	unmatchedA := make([]string, 0)
+	unmatchedB := make(map[string]bool)
+	for path := range instA {
+		if _, ok := instB[path]; ok {
+			matched[path] = path
+		} else {
+			unmatchedA = append(unmatchedA, path)
+		}
+	}
+	for path := range instB {
+		if _, ok := instA[path]; !ok {
+			unmatchedB[path] = true
+		}
+	}
+
+	// names of the unmatched instances, which occur exactly once in both System Models, could have been moved
+	countA := make(map[string]int)
+	for _, path := range unmatchedA {
+		countA[instA[path].Name]++
+	}
+	candidates := make(map[string]string)
+	countB := make(map[string]int)
+	for path := range unmatchedB {
+		countB[instB[path].Name]++
+		candidates[instB[path].Name] = path
+	}
+
+	// parents have to be paired before the instances they have deployed
+	sort.Slice(unmatchedA, func(i, j int) bool {
+		di, dj := strings.Count(unmatchedA[i], pathSeparator), strings.Count(unmatchedA[j], pathSeparator)
+		if di != dj {
+			return di < dj
+		}
+		return unmatchedA[i] < unmatchedA[j]
+	})
+	for _, pathA := range unmatchedA {
+		parentA, segment := splitPath(pathA)
+		if parentB, ok := matched[parentA]; ok && parentA != parentB {
+			if pathB := joinPath(parentB, segment); unmatchedB[pathB] {
+				matched[pathA] = pathB
+				delete(unmatchedB, pathB)
+				continue
+			}
+		}
+		name := instA[pathA].Name
+		pathB, ok := candidates[name]
+		if !ok || countA[name] != 1 || countB[name] != 1 || !unmatchedB[pathB] || instA[pathA].Type != instB[pathB].Type {
+			continue
+		}
+		matched[pathA] = pathB
+		delete(unmatchedB, pathB)
+		newParent, _ := splitPath(pathB)
+		d.MovedInstances = append(d.MovedInstances, InstanceMove{
+			Instance:  pathA,
+			OldParent: parentA,
+			NewParent: newParent,
+		})
+	}
+
+	return matched
+}
+
+// sort orders all reported differences by names
+func (d *ModelDiff) sort() {
+	sort.Strings(d.AddedInstances)
+	sort.Strings(d.RemovedInstances)
+	sort.Slice(d.MovedInstances, func(i, j int) bool {
+		return d.MovedInstances[i].Instance < d.MovedInstances[j].Instance
+	})
+	sort.Slice(d.ChangedAspects, func(i, j int) bool {
+		if d.ChangedAspects[i].Instance != d.ChangedAspects[j].Instance {
+			return d.ChangedAspects[i].Instance < d.ChangedAspects[j].Instance
+		}
+		return d.ChangedAspects[i].Aspect < d.ChangedAspects[j].Aspect
+	})
+	sort.Slice(d.ChangedApplications, func(i, j int) bool {
+		if d.ChangedApplications[i].Application != d.ChangedApplications[j].Application {
+			return d.ChangedApplications[i].Application < d.ChangedApplications[j].Application
+		}
+		return d.ChangedApplications[i].Field < d.ChangedApplications[j].Field
+	})
+}
+
+// instancesByName returns all instances of the System Model indexed by their names
+func (sm *SystemModel) instancesByName() map[string]*Instance {
+	res := make(map[string]*Instance, sm.GetTotalNumberOfInstances())
+	for _, layer := range sm.Layers {
+		for _, inst := range layer.Instances {
+			res[inst.Name] = inst
+		}
+	}
+	return res
+}
+
+// instancesByPath returns all instances of the System Model indexed by their paths. Instances, which are not deployed
+// by any other instance (i.e., the root), start the paths. Relations to the instances, which are not in any layer,
+// are skipped.
+func (sm *SystemModel) instancesByPath() map[string]*Instance {
+	res := make(map[string]*Instance, sm.GetTotalNumberOfInstances())
+	inLayers := make(map[*Instance]bool)
+	for _, layer := range sm.Layers {
+		for _, inst := range layer.Instances {
+			inLayers[inst] = true
+		}
+	}
+	hasParent := make(map[*Instance]bool)
+	for inst := range inLayers {
+		for _, rel := range inst.Relations {
+			hasParent[rel] = true
+		}
+	}
+
+	var walk func(inst *Instance, path string)
+	walk = func(inst *Instance, path string) {
+		res[path] = inst
+		seen := make(map[string]int, len(inst.Relations))
+		for _, rel := range inst.Relations {
+			if !inLayers[rel] {
+				continue
+			}
+			seen[rel.Name]++
+			walk(rel, joinPath(path, pathSegment(rel.Name, seen[rel.Name])))
+		}
+	}
+	roots := make(map[string]int)
+	for l := 1; l <= len(sm.Layers); l++ {
+		layer, ok := sm.Layers[l]
+		if !ok {
+			continue
+		}
+		for _, inst := range layer.Instances {
+			if !hasParent[inst] {
+				roots[inst.Name]++
+				walk(inst, pathSegment(inst.Name, roots[inst.Name]))
+			}
+		}
+	}
+	return res
+}
+
+// pathSegment returns a segment of the path for an instance, which is n-th sibling with a given name
+func pathSegment(name string, n int) string {
+	if n == 1 {
+		return name
+	}
+	return name + "[" + strconv.Itoa(n) + "]"
+}
+
+// joinPath appends a segment to the path
+func joinPath(path, segment string) string {
+	if path == "" {
+		return segment
+	}
+	return path + pathSeparator + segment
+}
+
+// splitPath splits the path to the path of the parent and the last segment
+func splitPath(path string) (string, string) {
+	idx := strings.LastIndex(path, pathSeparator)
+	if idx == -1 {
+		return "", path
+	}
+	return path[:idx], path[idx+1:]
+}
+
+// unionOfKeys returns sorted union of keys of both maps
+func unionOfKeys(a, b map[string]string) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
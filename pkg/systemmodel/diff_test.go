@@ -0,0 +1,133 @@
+package systemmodel
+
+import (
+	"encoding/json"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestDiffOfClone(t *testing.T) {
+	sm := CreateSystemModelDepth3()
+	diff := Diff(sm, sm.Clone())
+	assert.Assert(t, diff.IsEmpty())
+}
+
+func TestDiff(t *testing.T) {
+	sm := CreateSystemModelDepth3()
+	clone := sm.Clone()
+
+	// changing an aspect of an instance
+	err := clone.UpdateApplicationReliability("App#2", map[int64]float64{
+		1: 0.5,
+		2: 0.34,
+	})
+	assert.NilError(t, err)
+
+	// adding an instance to the last layer
+	inst := &Instance{}
+	inst.CreateInstance("App#3-4-1", CreateInstanceTypeApp()).SetPriority(1).SetReliability(0.9)
+	vi, err := clone.GetInstance("VI#2-1")
+	assert.NilError(t, err)
+	vi.AddRelation(inst)
+	clone.Layers[3].AddInstanceToLayer(inst)
+	clone.CreateApplication(1, 1.0, "App#4")
+	clone.Applications["App#4"].SetPriority(0.1).Deploy()
+
+	// removing an instance from the last layer
+	layer3 := clone.Layers[3]
+	for idx, v := range layer3.Instances {
+		if v.Name == "VI#3-3" {
+			layer3.Instances = append(layer3.Instances[:idx], layer3.Instances[idx+1:]...)
+			break
+		}
+	}
+
+	// changing an Application
+	clone.Applications["App#1"].SetPriority(0.3)
+	clone.Applications["App#3"].State = false
+
+	diff := Diff(sm, clone)
+	assert.DeepEqual(t, diff.AddedInstances, []string{"MAIS/VI#2-1/App#3-4-1"})
+	assert.DeepEqual(t, diff.RemovedInstances, []string{"MAIS/VI#2-2/VI#3-3"})
+	assert.DeepEqual(t, diff.MovedInstances, []InstanceMove{})
+	assert.DeepEqual(t, diff.ChangedAspects, []AspectChange{
+		{Instance: "MAIS/VI#2-1/App#3-2-1", Aspect: "Reliability", Old: "0.77", New: "0.5"},
+	})
+	assert.DeepEqual(t, diff.ChangedApplications, []ApplicationChange{
+		{Application: "App#1", Field: "Priority", Old: "0.27", New: "0.3"},
+		{Application: "App#3", Field: "State", Old: "true", New: "false"},
+		{Application: "App#4", Field: "Application", Old: "absent", New: "present"},
+	})
+
+	// the diff is also machine-readable
+	out, err := json.Marshal(diff)
+	assert.NilError(t, err)
+	t.Logf("Diff is %s", out)
+	restored := &ModelDiff{}
+	err = json.Unmarshal(out, restored)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, restored, diff)
+}
+
+func TestDiffMovedInstance(t *testing.T) {
+	sm := CreateSystemModelDepth3()
+	clone := sm.Clone()
+
+	// App#3-3-1 is deployed by VI#2-1 instead of VI#2-2, it stays in the same layer
+	inst, err := clone.GetInstance("App#3-3-1")
+	assert.NilError(t, err)
+	parent, err := clone.GetInstance("VI#2-2")
+	assert.NilError(t, err)
+	parent.removeRelation(inst)
+	target, err := clone.GetInstance("VI#2-1")
+	assert.NilError(t, err)
+	target.AddRelation(inst)
+	inst.SetReliability(0.5)
+
+	diff := Diff(sm, clone)
+	t.Logf("Diff is %+v", diff)
+	assert.DeepEqual(t, diff.AddedInstances, []string{})
+	assert.DeepEqual(t, diff.RemovedInstances, []string{})
+	assert.DeepEqual(t, diff.MovedInstances, []InstanceMove{
+		{Instance: "MAIS/VI#2-2/App#3-3-1", OldParent: "MAIS/VI#2-2", NewParent: "MAIS/VI#2-1"},
+	})
+	assert.Equal(t, len(diff.ChangedAspects), 1)
+	assert.Equal(t, diff.ChangedAspects[0].Instance, "MAIS/VI#2-1/App#3-3-1")
+}
+
+func TestDiffSameNamedInstances(t *testing.T) {
+	sm := CreateSystemModelDepth3()
+	vi, err := sm.GetInstance("VI#2-1")
+	assert.NilError(t, err)
+	// generated System Models give the same name to all VIs deployed by a VI at once
+	for i := 0; i < 2; i++ {
+		inst := &Instance{}
+		inst.CreateInstance("VI#3-9", CreateInstanceTypeVI()).SetPriority(0.5).SetReliability(0.9)
+		vi.AddRelation(inst)
+		sm.Layers[3].AddInstanceToLayer(inst)
+	}
+	assert.Equal(t, int64(len(sm.instancesByPath())), sm.GetTotalNumberOfInstances())
+	assert.Assert(t, Diff(sm, sm.Clone()).IsEmpty())
+
+	clone := sm.Clone()
+	cloneVI, err := clone.GetInstance("VI#2-1")
+	assert.NilError(t, err)
+	cloneVI.Relations[len(cloneVI.Relations)-1].SetReliability(0.4)
+
+	diff := Diff(sm, clone)
+	assert.DeepEqual(t, diff.ChangedAspects, []AspectChange{
+		{Instance: "MAIS/VI#2-1/VI#3-9[2]", Aspect: "Reliability", Old: "0.9", New: "0.4"},
+	})
+}
+
+func TestDiffGeneratedSystemModel(t *testing.T) {
+	sm := &SystemModel{}
+	sm.InitializeSystemModel(10, 4)
+	sm.CreateRandomApplications(GenerateAppNames(10), 1, 10)
+	_, err := sm.GenerateSystemModel()
+	assert.NilError(t, err)
+
+	// each instance has its own path, even if its name is shared with its siblings
+	assert.Equal(t, int64(len(sm.instancesByPath())), sm.GetTotalNumberOfInstances())
+	assert.Assert(t, Diff(sm, sm.Clone()).IsEmpty())
+}
@@ -0,0 +1,21 @@
+// Package systemmodel implements means of Fractal MAIS system model. This file in particular defines errors, which
+// are returned (wrapped) by the System Model functions, so that they can be distinguished with errors.Is.
+package systemmodel
+
+import "errors"
+
+var (
+	// ErrLayerNotFound is returned, when the System Model has no layer at the requested level
+	ErrLayerNotFound = errors.New("layer was not found in the System Model")
+	// ErrInstanceNotFound is returned, when the System Model has no instance of the requested name
+	ErrInstanceNotFound = errors.New("couldn't find instance in the System Model")
+	// ErrAmbiguousInstance is returned, when more instances share the requested name and the instance has to be
+	// referred to by its path
+	ErrAmbiguousInstance = errors.New("more instances share the name, refer to the instance by its path")
+	// ErrAppNotFound is returned, when the Application was not initialized in the System Model
+	ErrAppNotFound = errors.New("application was not initialized in a SystemModel")
+	// ErrAppNotDeployed is returned, when the Application was initialized, but it is not deployed
+	ErrAppNotDeployed = errors.New("application is not deployed")
+	// ErrAspectMissing is returned, when the Instance or the Application doesn't carry the requested aspect
+	ErrAspectMissing = errors.New("aspect is not defined")
+)
@@ -0,0 +1,40 @@
+package systemmodel
+
+import (
+	"errors"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	systemModel := CreateExampleBasicFMAIS()
+
+	// VI, which deploys other instances, has no Reliability aspect
+	inst, err := systemModel.GetInstance("VI#2-1")
+	assert.NilError(t, err)
+	_, err = inst.GetReliability()
+	t.Logf("Missing aspect: %v", err)
+	assert.Assert(t, errors.Is(err, ErrAspectMissing))
+
+	// instance, which doesn't exist
+	_, err = systemModel.GetInstance("App#42-1-1")
+	t.Logf("Missing instance: %v", err)
+	assert.Assert(t, errors.Is(err, ErrInstanceNotFound))
+
+	// application, which doesn't exist
+	_, err = systemModel.GatherApplicationInstanceReliabilities("App#42")
+	t.Logf("Missing application: %v", err)
+	assert.Assert(t, errors.Is(err, ErrAppNotFound))
+
+	// application, which is initialized, but not deployed
+	systemModel.CreateApplication(2, 0.5, "App#42")
+	_, err = systemModel.GatherApplicationInstanceReliabilities("App#42")
+	t.Logf("Application not deployed: %v", err)
+	assert.Assert(t, errors.Is(err, ErrAppNotDeployed))
+
+	// layer, which doesn't exist
+	delete(systemModel.Layers, 2)
+	_, err = systemModel.GetInstance("App#42-1-1")
+	t.Logf("Missing layer: %v", err)
+	assert.Assert(t, errors.Is(err, ErrLayerNotFound))
+}
@@ -233,7 +233,7 @@ func (sm *SystemModel) SetApplicationReliability(appName string, rls map[int64]f
 		layer, ok := sm.Layers[d]
 		if !ok {
 			sm.PrettyPrintApplications().PrettyPrintApplications()
-			return fmt.Errorf("layer %d was not found in FMAIS", d)
+			return fmt.Errorf("level %d: %w", d, ErrLayerNotFound)
 		}
 		for _, inst := range layer.Instances {
 			if counter == count {
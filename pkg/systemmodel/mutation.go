@@ -0,0 +1,519 @@
+// Package systemmodel implements means of Fractal MAIS system model. This file in particular implements mutation
+// of the System Model topology at runtime (i.e., deployment and removal of instances) and notification about
+// these changes.
+package systemmodel
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ChangeType defines a type of the System Model topology change
+type ChangeType uint
+
+const (
+	ApplicationDeployed ChangeType = 0 // instances of an Application (or VI) were deployed by a VI
+	InstanceRemoved     ChangeType = 1 // an instance was removed together with all instances it has deployed
+	SubtreeMigrated     ChangeType = 2 // an instance was moved together with all instances it has deployed under another VI
+)
+
+// ChangeEvent structure describes a change of the System Model topology
+type ChangeEvent struct {
+	Type        ChangeType        // type of the change
+	Application string            // name of the deployed Application (set only for ApplicationDeployed)
+	Parent      string            // name of the VI, which has deployed, lost or received the instances
+	Instances   []string          // names of the affected instances (new names in case of SubtreeMigrated)
+	Renamed     map[string]string // maps old instance names to the new ones (set only for SubtreeMigrated)
+}
+
+// Subscribe registers a listener, which is called after each topology change of the System Model.
+// Listeners are not carried over to the copies of the System Model.
+func (sm *SystemModel) Subscribe(listener func(event ChangeEvent)) *SystemModel {
+	sm.listeners = append(sm.listeners, listener)
+	return sm
+}
+
+// notify passes the event to all subscribed listeners
+func (sm *SystemModel) notify(event ChangeEvent) {
+	for _, listener := range sm.listeners {
+		listener(event)
+	}
+}
+
+// DeployApplicationAt deploys an Application (or a VI) under the VI with a given name (or path, see InstancePaths).
+// Number of created instances is defined by the Application rules, each instance gets an equal share of the priority.
+// VIs can be deployed multiple times, other Applications only once. As in the generated System Models, all VIs
+// created by one deployment share the same name and VIcount is incremented once per deployment. Created instances
+// are returned, so that their reliability can be set. If chain coefficients were already computed for the System
+// Model, they are set for new instances as well.
+func (sm *SystemModel) DeployApplicationAt(parentName, appName string) ([]*Instance, error) {
+	parent, level, err := sm.locateInstance(parentName)
+	if err != nil {
+		return nil, err
+	}
+	if !parent.IsVI() {
+		return nil, fmt.Errorf("only VI can deploy applications, %s is not a VI", parentName)
+	}
+	app, ok := sm.Applications[appName]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", appName, ErrAppNotFound)
+	}
+	isVI := strings.HasPrefix(appName, "VI")
+	if app.State && !isVI {
+		return nil, fmt.Errorf("application %s is already deployed", appName)
+	}
+	if app.Rules < 1 {
+		return nil, fmt.Errorf("application %s does not deploy any instances (rules are %d)", appName, app.Rules)
+	}
+	if sm.VIcount == nil {
+		viCount := uint64(0)
+		sm.VIcount = &viCount
+	}
+
+	// composing names first, so that the System Model is left intact in case of an error
+	currentLevel := level + 1
+	existing := sm.instancesByName()
+	names := make([]string, 0, app.Rules)
+	viCount := *sm.VIcount
+	viName := ""
+	if isVI {
+		// VI deployment number is unique across the System Model, skipping numbers which are already taken
+		for viName == "" || existing[viName] != nil {
+			viCount++
+			viName = ComposeVIName(currentLevel, int64(viCount))
+		}
+	}
+	for j := 1; j <= app.Rules; j++ {
+		name := viName
+		if !isVI {
+			name, err = ComposeAppName(appName, currentLevel, j)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := existing[name]; ok {
+				return nil, fmt.Errorf("instance %s already exists in a SystemModel", name)
+			}
+		}
+		names = append(names, name)
+	}
+	*sm.VIcount = viCount
+
+	layer := sm.getOrCreateLayer(currentLevel)
+	tp := CreateInstanceTypeApp()
+	if isVI {
+		tp = CreateInstanceTypeVI()
+	}
+	deployed := make([]*Instance, 0, len(names))
+	for _, name := range names {
+		inst := &Instance{}
+		inst.CreateInstance(name, tp).SetPriority(1 / float64(app.Rules))
+		parent.AddRelation(inst)
+		layer.AddInstanceToLayer(inst)
+		deployed = append(deployed, inst)
+	}
+	app.Deploy()
+
+	err = sm.refreshChainCoefficients(deployed)
+	if err != nil {
+		return nil, err
+	}
+
+	sm.notify(ChangeEvent{
+		Type:        ApplicationDeployed,
+		Application: appName,
+		Parent:      parentName,
+		Instances:   names,
+	})
+
+	return deployed, nil
+}
+
+// RemoveInstance removes an instance (referred to by its name or path) together with all instances it has deployed
+// from the System Model.
+// An Application, which has no instances left, is marked as not deployed and can be deployed again.
+// Empty layers at the bottom of the System Model are removed, depth of the System Model stays the same.
+func (sm *SystemModel) RemoveInstance(name string) error {
+	inst, level, err := sm.locateInstance(name)
+	if err != nil {
+		return err
+	}
+	parent, err := sm.parentOf(inst, level)
+	if err != nil {
+		return err
+	}
+
+	parent.removeRelation(inst)
+	subtree := sm.detachSubtree(inst, level)
+	names := make([]string, 0, len(subtree))
+	for _, v := range subtree {
+		names = append(names, v.instance.Name)
+	}
+	sm.trimLayers()
+	sm.refreshLayers()
+
+	// Applications with no instances left are not deployed anymore
+	remaining := sm.instancesByName()
+	for _, v := range subtree {
+		appName := "VI"
+		if v.instance.IsApp() {
+			appName, err = v.instance.GetAppName()
+			if err != nil {
+				return err
+			}
+		}
+		if app, ok := sm.Applications[appName]; ok && !sm.hasInstancesOf(appName, remaining) {
+			app.State = false
+		}
+	}
+
+	// parent, which is left with no relations, becomes a leaf of the System Model
+	if len(parent.Relations) == 0 {
+		err = sm.refreshChainCoefficients([]*Instance{parent})
+		if err != nil {
+			return err
+		}
+	}
+
+	sm.notify(ChangeEvent{
+		Type:      InstanceRemoved,
+		Parent:    parent.Name,
+		Instances: names,
+	})
+
+	return nil
+}
+
+// MigrateSubtree moves an instance together with all instances it has deployed under another VI. Both instances are
+// referred to by their names or paths. Migrated instances
+// are moved to the corresponding layers and renamed to reflect their new level (e.g., VI#3-3 becomes VI#4-3).
+// If chain coefficients were already computed for the System Model, they are updated as well.
+func (sm *SystemModel) MigrateSubtree(from, to string) error {
+	inst, level, err := sm.locateInstance(from)
+	if err != nil {
+		return err
+	}
+	parent, err := sm.parentOf(inst, level)
+	if err != nil {
+		return err
+	}
+	target, targetLevel, err := sm.locateInstance(to)
+	if err != nil {
+		return err
+	}
+	if !target.IsVI() {
+		return fmt.Errorf("instances can be migrated only under a VI, %s is not a VI", to)
+	}
+	if parent == target {
+		return nil
+	}
+
+	// checking the new names before anything is changed
+	subtree := collectSubtreeLevels(inst, level)
+	inSubtree := make(map[*Instance]bool, len(subtree))
+	for _, v := range subtree {
+		inSubtree[v.instance] = true
+	}
+	if inSubtree[target] {
+		return fmt.Errorf("instance %s can't be migrated under its own subtree (%s)", from, to)
+	}
+	shift := targetLevel + 1 - level
+	existing := sm.instancesByName()
+	renamed := make(map[string]string, len(subtree))
+	for _, v := range subtree {
+		newName, err := composeNameForLevel(v.instance.Name, v.level+shift)
+		if err != nil {
+			return err
+		}
+		if other, ok := existing[newName]; ok && !inSubtree[other] {
+			return fmt.Errorf("instance %s already exists in a SystemModel", newName)
+		}
+		renamed[v.instance.Name] = newName
+	}
+
+	parent.removeRelation(inst)
+	target.AddRelation(inst)
+	sm.detachSubtree(inst, level)
+	names := make([]string, 0, len(subtree))
+	for _, v := range subtree {
+		v.instance.Name = renamed[v.instance.Name]
+		sm.getOrCreateLayer(v.level + shift).AddInstanceToLayer(v.instance)
+		names = append(names, v.instance.Name)
+	}
+	sm.trimLayers()
+	sm.refreshLayers()
+
+	affected := []*Instance{inst}
+	if len(parent.Relations) == 0 {
+		affected = append(affected, parent)
+	}
+	err = sm.refreshChainCoefficients(affected)
+	if err != nil {
+		return err
+	}
+
+	sm.notify(ChangeEvent{
+		Type:      SubtreeMigrated,
+		Parent:    to,
+		Instances: names,
+		Renamed:   renamed,
+	})
+
+	return nil
+}
+
+// GetParent returns an instance, which has deployed the instance with a given name (or path)
+func (sm *SystemModel) GetParent(name string) (*Instance, error) {
+	inst, level, err := sm.locateInstance(name)
+	if err != nil {
+		return nil, err
+	}
+	return sm.parentOf(inst, level)
+}
+
+// leveledInstance holds an instance together with the level of the layer it resides in
+type leveledInstance struct {
+	instance *Instance
+	level    int
+}
+
+// InstancePaths returns paths of all instances of the System Model, i.e., names of all instances from the root
+// down to the instance separated with '/' (e.g., MAIS/VI#2-1/App#3-2-1). Siblings with the same name are
+// distinguished by their order, e.g., VI#3-2[2] is the second instance named VI#3-2 deployed by the same parent.
+// Paths can be used instead of names to refer to the instances, which share their name with other instances.
+func (sm *SystemModel) InstancePaths() map[*Instance]string {
+	paths := sm.instancesByPath()
+	res := make(map[*Instance]string, len(paths))
+	for path, inst := range paths {
+		res[inst] = path
+	}
+	return res
+}
+
+// locateInstance returns an instance with a given name (or path) together with the level of the layer it resides in.
+// A name, which is shared by more instances, is rejected.
+func (sm *SystemModel) locateInstance(name string) (*Instance, int, error) {
+	if strings.Contains(name, pathSeparator) {
+		if inst, ok := sm.instancesByPath()[name]; ok {
+			return inst, sm.levelOf(inst), nil
+		}
+		return nil, -1, fmt.Errorf("%s: %w", name, ErrInstanceNotFound)
+	}
+
+	var found *Instance
+	level := -1
+	for l, layer := range sm.Layers {
+		for _, v := range layer.Instances {
+			if v.Name != name {
+				continue
+			}
+			if found != nil {
+				return nil, -1, fmt.Errorf("%s: %w", name, ErrAmbiguousInstance)
+			}
+			found, level = v, l
+		}
+	}
+	if found == nil {
+		return nil, -1, fmt.Errorf("%s: %w", name, ErrInstanceNotFound)
+	}
+	return found, level, nil
+}
+
+// levelOf returns the level of the layer, in which the instance resides
+func (sm *SystemModel) levelOf(inst *Instance) int {
+	for level, layer := range sm.Layers {
+		for _, v := range layer.Instances {
+			if v == inst {
+				return level
+			}
+		}
+	}
+	return -1
+}
+
+// parentOf returns an instance, which has deployed given instance
+func (sm *SystemModel) parentOf(inst *Instance, level int) (*Instance, error) {
+	// levels of the layers don't have to be contiguous, thus all layers above the instance are searched
+	for l, layer := range sm.Layers {
+		if l >= level {
+			continue
+		}
+		for _, v := range layer.Instances {
+			for _, rel := range v.Relations {
+				if rel == inst {
+					return v, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("instance %s has no parent - root instance can't be removed or migrated", inst.Name)
+}
+
+// removeRelation removes given instance from the relations
+func (i *Instance) removeRelation(relation *Instance) {
+	for idx, rel := range i.Relations {
+		if rel == relation {
+			i.Relations = append(i.Relations[:idx], i.Relations[idx+1:]...)
+			return
+		}
+	}
+}
+
+// collectSubtreeLevels gathers all instances under (and including) the instance in breadth-first order
+func collectSubtreeLevels(inst *Instance, level int) []leveledInstance {
+	subtree := []leveledInstance{{instance: inst, level: level}}
+	for idx := 0; idx < len(subtree); idx++ {
+		for _, rel := range subtree[idx].instance.Relations {
+			subtree = append(subtree, leveledInstance{instance: rel, level: subtree[idx].level + 1})
+		}
+	}
+	return subtree
+}
+
+// detachSubtree removes the instance and all instances it has deployed from the layers. Removed instances are returned.
+func (sm *SystemModel) detachSubtree(inst *Instance, level int) []leveledInstance {
+	subtree := collectSubtreeLevels(inst, level)
+	removed := make(map[*Instance]bool, len(subtree))
+	for _, v := range subtree {
+		removed[v.instance] = true
+	}
+	for l, layer := range sm.Layers {
+		if l < level {
+			continue
+		}
+		instances := make([]*Instance, 0, len(layer.Instances))
+		for _, v := range layer.Instances {
+			if !removed[v] {
+				instances = append(instances, v)
+			}
+		}
+		layer.Instances = instances
+	}
+	return subtree
+}
+
+// getOrCreateLayer returns a layer at a given level. If it does not exist, it is created and depth of the System Model
+// is extended, if necessary.
+func (sm *SystemModel) getOrCreateLayer(level int) *Layer {
+	layer, ok := sm.Layers[level]
+	if !ok {
+		layer = &Layer{}
+		layer.InitializeLayer()
+		sm.AddLayer(layer, level)
+	}
+	if sm.Depth < level {
+		sm.Depth = level
+	}
+	return layer
+}
+
+// trimLayers removes empty layers at the bottom of the System Model. Missing layers (i.e., gaps in the levels)
+// are skipped.
+func (sm *SystemModel) trimLayers() {
+	bottom := 0
+	for l := range sm.Layers {
+		if l > bottom {
+			bottom = l
+		}
+	}
+	for l := bottom; l > 1; l-- {
+		layer, ok := sm.Layers[l]
+		if !ok {
+			continue
+		}
+		if len(layer.Instances) != 0 {
+			break
+		}
+		delete(sm.Layers, l)
+	}
+}
+
+// refreshLayers re-evaluates, whether VI is deployed at each layer
+func (sm *SystemModel) refreshLayers() {
+	for _, layer := range sm.Layers {
+		layer.VIwasDeployed = false
+		for _, v := range layer.Instances {
+			if v.IsVI() {
+				layer.VIwasDeployed = true
+				break
+			}
+		}
+	}
+}
+
+// hasInstancesOf returns true, if at least one instance of an Application (or a VI other than root) is present
+func (sm *SystemModel) hasInstancesOf(appName string, instances map[string]*Instance) bool {
+	for name, v := range instances {
+		if appName == "VI" {
+			if v.IsVI() && strings.HasPrefix(name, "VI") {
+				return true
+			}
+			continue
+		}
+		instAppName, err := v.GetAppName()
+		if err == nil && v.IsApp() && instAppName == appName {
+			return true
+		}
+	}
+	return false
+}
+
+// hasChainCoefficients returns true, if chain coefficients were already computed for the System Model
+func (sm *SystemModel) hasChainCoefficients() bool {
+	for _, layer := range sm.Layers {
+		for _, v := range layer.Instances {
+			if _, ok := v.Aspect[chainCoefKey]; ok && len(v.Relations) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// refreshChainCoefficients re-computes chain coefficients of all instances with no relations under (and including)
+// provided instances and of their Applications. Nothing is done, if chain coefficients were not computed before.
+func (sm *SystemModel) refreshChainCoefficients(instances []*Instance) error {
+	if !sm.hasChainCoefficients() {
+		return nil
+	}
+	for _, inst := range instances {
+		for _, v := range collectSubtreeLevels(inst, 0) {
+			if len(v.instance.Relations) != 0 {
+				continue
+			}
+			err := sm.setChainCoefficient(v.instance)
+			if err != nil {
+				return err
+			}
+			if !v.instance.IsApp() {
+				continue
+			}
+			appName, err := v.instance.GetAppName()
+			if err != nil {
+				return err
+			}
+			cc, err := v.instance.GetChainCoefficient()
+			if err != nil {
+				return err
+			}
+			if app, ok := sm.Applications[appName]; ok {
+				app.SetChainCoefficient(cc)
+			}
+		}
+	}
+	return nil
+}
+
+// composeNameForLevel composes a name of an instance, which was moved to another level,
+// e.g., App#3-2-1 becomes App#4-2-1 and VI#3-3 becomes VI#4-3
+func composeNameForLevel(name string, level int) (string, error) {
+	idx := strings.Index(name, "#")
+	if idx == -1 {
+		return "", fmt.Errorf("wrong instance name - couldn't find '#' in %s", name)
+	}
+	idx2 := strings.Index(name[idx+1:], "-")
+	if idx2 == -1 {
+		return "", fmt.Errorf("wrong instance name - couldn't find '-' in %s", name)
+	}
+	return name[:idx+1] + strconv.Itoa(level) + name[idx+idx2+1:], nil
+}
@@ -0,0 +1,207 @@
+package systemmodel
+
+import (
+	"errors"
+	"gotest.tools/assert"
+	"math"
+	"testing"
+)
+
+func TestDeployApplicationAt(t *testing.T) {
+	sm := CreateSystemModelDepth4()
+	events := make([]ChangeEvent, 0)
+	sm.Subscribe(func(event ChangeEvent) {
+		events = append(events, event)
+	})
+
+	// deploying two VIs under a VI, which had no relations, VIs of one deployment share the name
+	vis, err := sm.DeployApplicationAt("VI#3-4", "VI")
+	assert.NilError(t, err)
+	assert.Equal(t, len(vis), 2)
+	assert.Equal(t, vis[0].Name, "VI#4-2")
+	assert.Equal(t, vis[1].Name, "VI#4-2")
+	assert.Equal(t, *sm.VIcount, uint64(2))
+	assert.Assert(t, sm.Layers[4].VIwasDeployed)
+	assert.Equal(t, sm.Depth, 4)
+	paths := sm.InstancePaths()
+	assert.Equal(t, paths[vis[0]], "MAIS/VI#2-2/VI#3-4/VI#4-2")
+	assert.Equal(t, paths[vis[1]], "MAIS/VI#2-2/VI#3-4/VI#4-2[2]")
+
+	// the same-named VIs have to be referred to by their paths
+	sm.CreateApplication(2, 1.0, "App#5")
+	sm.Applications["App#5"].SetPriority(0.1)
+	_, err = sm.DeployApplicationAt("VI#4-2", "App#5")
+	assert.Assert(t, errors.Is(err, ErrAmbiguousInstance))
+
+	// deploying a new Application under the second new VI extends the System Model
+	apps, err := sm.DeployApplicationAt("MAIS/VI#2-2/VI#3-4/VI#4-2[2]", "App#5")
+	assert.NilError(t, err)
+	assert.Equal(t, len(apps), 2)
+	assert.Equal(t, apps[1].Name, "App#5-5-2")
+	assert.Assert(t, sm.Applications["App#5"].State)
+	assert.Equal(t, sm.Depth, 5)
+	assert.Equal(t, len(sm.Layers), 5)
+	prty, err := apps[0].GetPriority()
+	assert.NilError(t, err)
+	assert.Equal(t, prty, 0.5)
+	assert.Equal(t, len(vis[0].Relations), 0)
+	assert.Equal(t, len(vis[1].Relations), 2)
+
+	assert.Equal(t, len(events), 2)
+	assert.Equal(t, events[1].Type, ApplicationDeployed)
+	assert.Equal(t, events[1].Application, "App#5")
+	assert.Equal(t, events[1].Parent, "MAIS/VI#2-2/VI#3-4/VI#4-2[2]")
+	assert.DeepEqual(t, events[1].Instances, []string{"App#5-5-1", "App#5-5-2"})
+
+	// Application can be deployed only once and only by a VI
+	_, err = sm.DeployApplicationAt("MAIS/VI#2-2/VI#3-4/VI#4-2", "App#5")
+	assert.ErrorContains(t, err, "is already deployed")
+	_, err = sm.DeployApplicationAt("App#2-1-1", "VI")
+	assert.ErrorContains(t, err, "is not a VI")
+	_, err = sm.DeployApplicationAt("MAIS/VI#2-2/VI#3-4/VI#4-2", "App#9")
+	assert.ErrorContains(t, err, "was not initialized")
+	assert.Equal(t, len(events), 2)
+}
+
+func TestRemoveInstance(t *testing.T) {
+	sm := CreateSystemModelDepth4()
+	var event ChangeEvent
+	sm.Subscribe(func(e ChangeEvent) {
+		event = e
+	})
+
+	err := sm.RemoveInstance("VI#3-3")
+	assert.NilError(t, err)
+	assert.Equal(t, event.Type, InstanceRemoved)
+	assert.Equal(t, event.Parent, "VI#2-2")
+	assert.DeepEqual(t, event.Instances, []string{"VI#3-3", "App#4-4-1"})
+
+	// the last layer is gone, App#4 is not deployed anymore, depth stays the same
+	assert.Equal(t, len(sm.Layers), 3)
+	assert.Equal(t, sm.Depth, 4)
+	assert.Equal(t, sm.GetTotalNumberOfInstances(), int64(11))
+	assert.Assert(t, !sm.Applications["App#4"].State)
+	assert.Assert(t, sm.Applications["VI"].State)
+	_, err = sm.GetInstance("App#4-4-1")
+	assert.ErrorContains(t, err, "couldn't find instance")
+
+	// App#4 can be deployed again
+	apps, err := sm.DeployApplicationAt("VI#3-4", "App#4")
+	assert.NilError(t, err)
+	assert.Equal(t, apps[0].Name, "App#4-4-1")
+	assert.Equal(t, len(sm.Layers), 4)
+
+	err = sm.RemoveInstance("MAIS")
+	assert.ErrorContains(t, err, "root instance can't be removed")
+	err = sm.RemoveInstance("VI#9-9")
+	assert.ErrorContains(t, err, "couldn't find instance")
+}
+
+func TestDeployApplicationAtChainCoefficients(t *testing.T) {
+	sm := CreateSystemModelDepth4()
+	assert.NilError(t, sm.SetChainCoefficients())
+	parent, err := sm.GetInstance("VI#3-4")
+	assert.NilError(t, err)
+	ccParent, err := parent.GetChainCoefficient()
+	assert.NilError(t, err)
+
+	// each of the same-named VIs of the deployment gets its own chain coefficient
+	vis, err := sm.DeployApplicationAt("VI#3-4", "VI")
+	assert.NilError(t, err)
+	assert.Equal(t, len(vis), 2)
+	viPriority, err := sm.Applications["VI"].GetPriority()
+	assert.NilError(t, err)
+	for _, vi := range vis {
+		cc, err := vi.GetChainCoefficient()
+		assert.NilError(t, err)
+		t.Logf("Chain coefficient of %s is %v", vi.Name, cc)
+		// VI#3-4 has priority 1, so the chain grows by the priority of the VI Application
+		assert.Assert(t, math.Abs(cc-ccParent*viPriority) < 1e-12)
+	}
+}
+
+func TestMigrateSubtree(t *testing.T) {
+	sm := CreateSystemModelDepth4()
+	err := sm.SetChainCoefficients()
+	assert.NilError(t, err)
+	app4, err := sm.GetInstance("App#4-4-1")
+	assert.NilError(t, err)
+	ccBefore, err := app4.GetChainCoefficient()
+	assert.NilError(t, err)
+
+	var event ChangeEvent
+	sm.Subscribe(func(e ChangeEvent) {
+		event = e
+	})
+
+	// moving VI#3-3 one level lower, under VI#3-4
+	err = sm.MigrateSubtree("VI#3-3", "VI#3-4")
+	assert.NilError(t, err)
+	assert.Equal(t, event.Type, SubtreeMigrated)
+	assert.Equal(t, event.Parent, "VI#3-4")
+	assert.DeepEqual(t, event.Renamed, map[string]string{"VI#3-3": "VI#4-3", "App#4-4-1": "App#5-4-1"})
+	assert.Equal(t, app4.Name, "App#5-4-1")
+	assert.Equal(t, len(sm.Layers), 5)
+	assert.Equal(t, sm.Depth, 5)
+	assert.Assert(t, sm.Layers[4].VIwasDeployed)
+	assert.Assert(t, !sm.Layers[5].VIwasDeployed)
+	assert.Equal(t, len(sm.Layers[3].Instances), 5)
+
+	// chain coefficient now includes priority of VI#3-4 (1) and of the VI Application (0.35)
+	ccAfter, err := app4.GetChainCoefficient()
+	assert.NilError(t, err)
+	assert.Assert(t, math.Abs(ccAfter-ccBefore*0.35) < 1e-12)
+	appCC, err := sm.Applications["App#4"].GetChainCoefficient()
+	assert.NilError(t, err)
+	assert.Equal(t, appCC, ccAfter)
+
+	// moving it back restores the original structure
+	err = sm.MigrateSubtree("VI#4-3", "VI#2-2")
+	assert.NilError(t, err)
+	_, err = sm.GetInstance("App#4-4-1")
+	assert.NilError(t, err)
+	assert.Equal(t, len(sm.Layers), 4)
+
+	err = sm.MigrateSubtree("VI#2-2", "VI#3-3")
+	assert.ErrorContains(t, err, "can't be migrated under its own subtree")
+	err = sm.MigrateSubtree("VI#3-3", "App#2-1-1")
+	assert.ErrorContains(t, err, "is not a VI")
+}
+
+func TestRemoveInstanceLayerGap(t *testing.T) {
+	sm := CreateSystemModelDepth4()
+	// the last layer is moved one level lower, thus there is no layer at level 4
+	sm.Layers[5] = sm.Layers[4]
+	delete(sm.Layers, 4)
+
+	err := sm.RemoveInstance("VI#3-3")
+	assert.NilError(t, err)
+	_, err = sm.GetInstance("App#4-4-1")
+	assert.ErrorContains(t, err, "couldn't find instance")
+	// empty layer at the bottom is removed, the gap is skipped
+	assert.Equal(t, len(sm.Layers), 3)
+	_, ok := sm.Layers[5]
+	assert.Assert(t, !ok)
+	assert.Equal(t, len(sm.Layers[3].Instances), 5)
+}
+
+func TestMigrateSubtreeLayerGap(t *testing.T) {
+	sm := CreateSystemModelDepth4()
+	sm.Layers[5] = sm.Layers[4]
+	delete(sm.Layers, 4)
+
+	// App#4-4-1 (residing at level 5) has its parent two levels above
+	parent, err := sm.GetParent("App#4-4-1")
+	assert.NilError(t, err)
+	assert.Equal(t, parent.Name, "VI#3-3")
+
+	err = sm.MigrateSubtree("VI#3-3", "VI#2-1")
+	assert.NilError(t, err)
+	paths := sm.InstancePaths()
+	app4, err := sm.GetInstance("App#4-4-1")
+	assert.NilError(t, err)
+	assert.Equal(t, paths[app4], "MAIS/VI#2-1/VI#3-3/App#4-4-1")
+	// nothing is left at level 5, thus the layer is removed
+	_, ok := sm.Layers[5]
+	assert.Assert(t, !ok)
+}
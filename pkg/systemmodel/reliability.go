@@ -4,6 +4,7 @@ package systemmodel
 
 import (
 	"fmt"
+	"log/slog"
 	"math/rand"
 	"strconv"
 	"strings"
@@ -19,7 +20,7 @@ func (sm *SystemModel) GatherApplicationInstanceReliabilities(appName string) (m
 	app, ok := sm.Applications[appName]
 	if !ok {
 		sm.PrettyPrintApplications()
-		return nil, fmt.Errorf("application %s was not initialized in a SystemModel", appName)
+		return nil, fmt.Errorf("%s: %w", appName, ErrAppNotFound)
 	}
 	if app.State && !strings.HasPrefix(appName, "VI") {
 		res := make(map[string]float64, app.Rules)
@@ -28,7 +29,7 @@ func (sm *SystemModel) GatherApplicationInstanceReliabilities(appName string) (m
 		for i := len(sm.Layers); i > 0; i-- {
 			layer, ok := sm.Layers[i]
 			if !ok {
-				return nil, fmt.Errorf("map entry for SystemModel.Layers with key %v does not exist", i)
+				return nil, fmt.Errorf("level %d: %w", i, ErrLayerNotFound)
 			}
 			hasTagIdx := strings.Index(appName, "#")
 			if hasTagIdx == -1 {
@@ -55,13 +56,13 @@ func (sm *SystemModel) GatherApplicationInstanceReliabilities(appName string) (m
 			}
 		}
 		if count != 0 {
-			fmt.Printf("Not all instances of Application %s were found - missing %d instances\n", appName, count)
+			slog.Warn("not all instances of the Application were found", "application", appName, "missing", count)
 		}
 		sm.Applications[appName].SetReliability(appReliability)
 		return res, nil
 	}
 
-	return nil, fmt.Errorf("application %s was not deployed", appName)
+	return nil, fmt.Errorf("%s: %w", appName, ErrAppNotDeployed)
 }
 
 // GatherAllApplicationsReliabilities function gathers reliability of each application and returns it in a map
@@ -98,8 +99,7 @@ func (i *Instance) SetReliability(reliability float64) *Instance {
 func (i *Instance) GetReliability() (float64, error) {
 	relStr, ok := i.Aspect[reliabilityKey]
 	if !ok {
-		return 0, fmt.Errorf("looks like Reliability aspect for instance %v was not defined.. "+
-			"Empty output for a key %v\n%v", i.Name, reliabilityKey, i)
+		return 0, fmt.Errorf("instance %s: %s %w", i.Name, reliabilityKey, ErrAspectMissing)
 	}
 	reliability, err := strconv.ParseFloat(relStr, 64)
 	if err != nil {
@@ -121,8 +121,7 @@ func (i *Instance) SetPriority(priority float64) *Instance {
 func (i *Instance) GetPriority() (float64, error) {
 	priorStr, ok := i.Aspect[priorityKey]
 	if !ok {
-		return 0, fmt.Errorf("looks like Priority aspect for instance %v was not defined.. "+
-			"Empty output for a key %v", i.Name, priorityKey)
+		return 0, fmt.Errorf("instance %s: %s %w", i.Name, priorityKey, ErrAspectMissing)
 	}
 	priority, err := strconv.ParseFloat(priorStr, 64)
 	if err != nil {
@@ -144,8 +143,7 @@ func (a *Application) SetPriority(priority float64) *Application {
 func (a *Application) GetPriority() (float64, error) {
 	priorStr, ok := a.Aspect[priorityKey]
 	if !ok {
-		return 0, fmt.Errorf("looks like Priority aspect was not defined for the Application.. "+
-			"Empty output for a key %v", priorityKey)
+		return 0, fmt.Errorf("application: %s %w", priorityKey, ErrAspectMissing)
 	}
 	priority, err := strconv.ParseFloat(priorStr, 64)
 	if err != nil {
@@ -167,8 +165,7 @@ func (a *Application) SetReliability(reliability float64) *Application {
 func (a *Application) GetReliability() (float64, error) {
 	relStr, ok := a.Aspect[reliabilityKey]
 	if !ok {
-		return 0, fmt.Errorf("looks like Reliability aspect was not defined for an Application.. "+
-			"Empty output for a key %v", reliabilityKey)
+		return 0, fmt.Errorf("application: %s %w", reliabilityKey, ErrAspectMissing)
 	}
 	reliability, err := strconv.ParseFloat(relStr, 64)
 	if err != nil {
@@ -183,7 +180,7 @@ func (sm *SystemModel) GetInstance(instName string) (*Instance, error) {
 	for i := len(sm.Layers); i > 0; i-- {
 		layer, ok := sm.Layers[i]
 		if !ok {
-			return nil, fmt.Errorf("no layer at level %d exists", i)
+			return nil, fmt.Errorf("level %d: %w", i, ErrLayerNotFound)
 		}
 		for _, v := range layer.Instances {
 			// exact matching the name of an instance
@@ -193,7 +190,7 @@ func (sm *SystemModel) GetInstance(instName string) (*Instance, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("couldn't find instance with name %s", instName)
+	return nil, fmt.Errorf("%s: %w", instName, ErrInstanceNotFound)
 }
 
 // SetApplicationPrioritiesRandom sets random priorities for each application
@@ -219,7 +216,7 @@ func (sm *SystemModel) SetInstancePrioritiesRandom() error {
 				for i := len(sm.Layers); i > 0 && instCount > 0; i-- {
 					layer, ok := sm.Layers[i]
 					if !ok {
-						return fmt.Errorf("no layer at level %d exists", i)
+						return fmt.Errorf("level %d: %w", i, ErrLayerNotFound)
 					}
 					for _, inst := range layer.Instances {
 						if instCount == 0 {
@@ -247,7 +244,7 @@ func (sm *SystemModel) SetInstancePrioritiesRandom() error {
 				for i := 1; i <= len(sm.Layers) && instCount > 0; i++ {
 					layer, ok := sm.Layers[i]
 					if !ok {
-						return fmt.Errorf("no layer at level %d exists", i)
+						return fmt.Errorf("level %d: %w", i, ErrLayerNotFound)
 					}
 					// if there are no VIs, then there is nothing to do
 					if !layer.VIwasDeployed {
@@ -305,7 +302,7 @@ func (sm *SystemModel) SetInstanceReliabilitiesRandom() error {
 					for i := len(sm.Layers); i > 0 && instCount > 0; i-- {
 						layer, ok := sm.Layers[i]
 						if !ok {
-							return fmt.Errorf("no layer at level %d exists", i)
+							return fmt.Errorf("level %d: %w", i, ErrLayerNotFound)
 						}
 						for _, inst := range layer.Instances {
 							if instCount == 0 {
@@ -332,7 +329,7 @@ func (sm *SystemModel) SetInstanceReliabilitiesRandom() error {
 						layer, ok := sm.Layers[d]
 						if !ok {
 							sm.PrettyPrintApplications().PrettyPrintLayers()
-							return fmt.Errorf("couldn't extract layer %d out of system model", d)
+							return fmt.Errorf("level %d: %w", d, ErrLayerNotFound)
 						}
 						for _, inst := range layer.Instances {
 							if strings.Contains(inst.Name, "VI") && len(inst.Relations) == 0 {
@@ -383,8 +380,7 @@ func (i *Instance) SetChainCoefficient(coef float64) *Instance {
 func (i *Instance) GetChainCoefficient() (float64, error) {
 	ccStr, ok := i.Aspect[chainCoefKey]
 	if !ok {
-		return 0, fmt.Errorf("looks like Chain Coefficient aspect was not defined for an Instance.. "+
-			"Empty output for a key %v", chainCoefKey)
+		return 0, fmt.Errorf("instance %s: %s %w", i.Name, chainCoefKey, ErrAspectMissing)
 	}
 	chainCoef, err := strconv.ParseFloat(ccStr, 64)
 	if err != nil {
@@ -401,19 +397,23 @@ func (sm *SystemModel) SetChainCoefficientForInstance(instName string) error {
 	if err != nil {
 		return fmt.Errorf("instance %s was not found in SystemModel: %w", instName, err)
 	}
+	return sm.setChainCoefficient(inst)
+}
+
+// setChainCoefficient gathers all coefficient in a SystemModel tree on top of the provided instance. Parents are
+// matched by pointers, so that same-named instances get their own chain coefficients.
+func (sm *SystemModel) setChainCoefficient(inst *Instance) error {
 
 	// this is to hold the chain coefficient value
 	var cc float64 = 1
 
-	// this is to hold priority of the Application/VI
-	var viPriority float64
 	// obtaining VI priority
 	viInst, ok := sm.Applications["VI"]
 	if !ok {
 		sm.PrettyPrintApplications().PrettyPrintLayers()
-		return fmt.Errorf("couldn't find VI in the Applications map")
+		return fmt.Errorf("VI: %w", ErrAppNotFound)
 	}
-	viPriority, err = viInst.GetPriority()
+	viPriority, err := viInst.GetPriority()
 	if err != nil {
 		sm.PrettyPrintApplications().PrettyPrintLayers()
 		return err
@@ -433,7 +433,7 @@ func (sm *SystemModel) SetChainCoefficientForInstance(instName string) error {
 		appInst, ok := sm.Applications[appName]
 		if !ok {
 			sm.PrettyPrintApplications().PrettyPrintLayers()
-			return fmt.Errorf("couldn't find %s in the Applications map", appName)
+			return fmt.Errorf("%s: %w", appName, ErrAppNotFound)
 		}
 		pr, err := appInst.GetPriority()
 		if err != nil {
@@ -442,17 +442,17 @@ func (sm *SystemModel) SetChainCoefficientForInstance(instName string) error {
 		cc *= pr
 	}
 
-	targetInst := instName // this is to hold an instance name, which has in its relation a required instance
+	targetInst := inst // this is to hold an instance, which has in its relation a required instance
 	// compute chain coefficient
 	for i := len(sm.Layers); i > 1; i-- {
 		layer, ok := sm.Layers[i]
 		if !ok {
-			return fmt.Errorf("no layer at level %d exists", i)
+			return fmt.Errorf("level %d: %w", i, ErrLayerNotFound)
 		}
 		for _, v := range layer.Instances {
 			for _, rel := range v.Relations {
-				// exact matching the name of an instance
-				if rel.Name == targetInst {
+				// exact matching of an instance
+				if rel == targetInst {
 					instPriority, err := v.GetPriority()
 					if err != nil {
 						return err
@@ -461,7 +461,7 @@ func (sm *SystemModel) SetChainCoefficientForInstance(instName string) error {
 					if v.IsVI() {
 						cc *= viPriority
 					}
-					targetInst = v.Name
+					targetInst = v
 				}
 			}
 		}
@@ -480,11 +480,11 @@ func (sm *SystemModel) SetChainCoefficients() error {
 	for i := len(sm.Layers); i > 1; i-- {
 		layer, ok := sm.Layers[i]
 		if !ok {
-			return fmt.Errorf("no layer at level %d exists", i)
+			return fmt.Errorf("level %d: %w", i, ErrLayerNotFound)
 		}
 		for _, v := range layer.Instances {
 			if len(v.Relations) == 0 {
-				err := sm.SetChainCoefficientForInstance(v.Name)
+				err := sm.setChainCoefficient(v)
 				if err != nil {
 					return err
 				}
@@ -500,7 +500,7 @@ func (sm *SystemModel) SetChainCoefficients() error {
 				flag := false
 				layer, ok := sm.Layers[i]
 				if !ok {
-					return fmt.Errorf("no layer at level %d exists", i)
+					return fmt.Errorf("level %d: %w", i, ErrLayerNotFound)
 				}
 				for _, val := range layer.Instances {
 					appNameForCurrentLayer := "App#" + strconv.Itoa(i) + "-" + k[len(k)-1:] + "-"
@@ -541,8 +541,7 @@ func (a *Application) SetChainCoefficient(coef float64) *Application {
 func (a *Application) GetChainCoefficient() (float64, error) {
 	ccStr, ok := a.Aspect[chainCoefKey]
 	if !ok {
-		return 0, fmt.Errorf("looks like Chain Coefficient aspect was not defined for an Application.. "+
-			"Empty output for a key %v", chainCoefKey)
+		return 0, fmt.Errorf("application: %s %w", chainCoefKey, ErrAspectMissing)
 	}
 	chainCoef, err := strconv.ParseFloat(ccStr, 64)
 	if err != nil {
@@ -0,0 +1,216 @@
+// Package systemmodel implements means of Fractal MAIS system model. This file in particular implements
+// (de)serialization of the System Model to (from) JSON, so that an existing system can be described in a file.
+package systemmodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+const instanceTypeVI = "VI"
+const instanceTypeApp = "App"
+
+// systemModelDocument is a serializable form of the SystemModel. Relations between instances are referenced by paths
+// of the instances (or by names, if they are unique).
+type systemModelDocument struct {
+	Depth        int                            `json:"depth"`             // depth of the System Model
+	VIcount      uint64                         `json:"viCount,omitempty"` // number of VI deployments
+	Applications map[string]applicationDocument `json:"applications"`      // Applications, key is the name of the Application
+	Layers       map[int]layerDocument          `json:"layers"`            // Layers, key is the level of the Layer
+}
+
+// applicationDocument is a serializable form of the Application
+type applicationDocument struct {
+	Rules       int               `json:"rules"`             // number of instances that application can deploy
+	Probability float32           `json:"probability"`       // probability of the application deployment
+	Deployed    bool              `json:"deployed"`          // indicates whether the Application is deployed
+	Aspects     map[string]string `json:"aspects,omitempty"` // aspects of the Application (e.g., Priority)
+}
+
+// layerDocument is a serializable form of the Layer
+type layerDocument struct {
+	VIwasDeployed bool               `json:"viWasDeployed"` // indicates whether VI was deployed at this Layer
+	Instances     []instanceDocument `json:"instances"`     // instances deployed at this Layer
+}
+
+// instanceDocument is a serializable form of the Instance
+type instanceDocument struct {
+	Name      string            `json:"name"`                // name of the instance (e.g., VI#2-1)
+	Path      string            `json:"path,omitempty"`      // path of the instance (e.g., MAIS/VI#2-1), it tells apart same-named instances
+	Type      string            `json:"type"`                // type of the instance, either VI, or App
+	Relations []string          `json:"relations,omitempty"` // paths (or unique names) of the instances related to this instance
+	Aspects   map[string]string `json:"aspects,omitempty"`   // aspects of the instance (e.g., Reliability or Priority)
+}
+
+// MarshalJSON serializes the SystemModel to JSON. Relations between instances are serialized as instance paths, since
+// VIs of a single deployment share their name.
+func (sm *SystemModel) MarshalJSON() ([]byte, error) {
+	paths := sm.InstancePaths()
+	doc := systemModelDocument{
+		Depth:        sm.Depth,
+		Applications: make(map[string]applicationDocument, len(sm.Applications)),
+		Layers:       make(map[int]layerDocument, len(sm.Layers)),
+	}
+	if sm.VIcount != nil {
+		doc.VIcount = *sm.VIcount
+	}
+	for name, app := range sm.Applications {
+		doc.Applications[name] = applicationDocument{
+			Rules:       app.Rules,
+			Probability: app.Probability,
+			Deployed:    app.State,
+			Aspects:     app.Aspect,
+		}
+	}
+	for level, layer := range sm.Layers {
+		l := layerDocument{
+			VIwasDeployed: layer.VIwasDeployed,
+			Instances:     make([]instanceDocument, 0, len(layer.Instances)),
+		}
+		for _, inst := range layer.Instances {
+			tp := instanceTypeApp
+			if inst.IsVI() {
+				tp = instanceTypeVI
+			}
+			relations := make([]string, 0, len(inst.Relations))
+			for _, rel := range inst.Relations {
+				if path, ok := paths[rel]; ok {
+					relations = append(relations, path)
+				} else {
+					relations = append(relations, rel.Name)
+				}
+			}
+			l.Instances = append(l.Instances, instanceDocument{
+				Name:      inst.Name,
+				Path:      paths[inst],
+				Type:      tp,
+				Relations: relations,
+				Aspects:   inst.Aspect,
+			})
+		}
+		doc.Layers[level] = l
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON deserializes the SystemModel from JSON. Layers should be numbered from 1 up to the depth of the System
+// Model (a generated System Model may not fill all its layers) and each relation should reference an existing
+// instance by its path, or by its unique name.
+func (sm *SystemModel) UnmarshalJSON(data []byte) error {
+	var doc systemModelDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if doc.Depth == 0 {
+		doc.Depth = len(doc.Layers)
+	}
+	if doc.Depth < len(doc.Layers) {
+		return fmt.Errorf("depth %d of the System Model is smaller than the number of layers %d", doc.Depth, len(doc.Layers))
+	}
+
+	model := &SystemModel{}
+	model.InitializeSystemModel(len(doc.Applications), doc.Depth)
+	*model.VIcount = doc.VIcount
+	for name, app := range doc.Applications {
+		if app.Rules < 0 {
+			return fmt.Errorf("application %s has negative number of instances %d", name, app.Rules)
+		}
+		model.Applications[name] = &Application{
+			Rules:       app.Rules,
+			Probability: app.Probability,
+			State:       app.Deployed,
+			Aspect:      copyAspects(app.Aspects),
+		}
+	}
+
+	// creating instances first, relations are resolved once all instances are known
+	instances := make(map[string]*Instance)
+	byName := make(map[string][]*Instance)
+	for level := 1; level <= len(doc.Layers); level++ {
+		l, ok := doc.Layers[level]
+		if !ok {
+			return fmt.Errorf("level %d: %w", level, ErrLayerNotFound)
+		}
+		layer := &Layer{}
+		layer.InitializeLayer()
+		layer.VIwasDeployed = l.VIwasDeployed
+		for _, i := range l.Instances {
+			key := i.Name
+			if i.Path != "" {
+				key = i.Path
+			}
+			if _, ok := instances[key]; ok {
+				return fmt.Errorf("instance %s is defined more than once", key)
+			}
+			var tp InstanceType
+			switch i.Type {
+			case instanceTypeVI:
+				tp = CreateInstanceTypeVI()
+			case instanceTypeApp:
+				tp = CreateInstanceTypeApp()
+			default:
+				return fmt.Errorf("instance %s has unknown type %q (expected %s or %s)", i.Name, i.Type, instanceTypeVI, instanceTypeApp)
+			}
+			inst := &Instance{}
+			inst.CreateInstance(i.Name, tp)
+			inst.Aspect = copyAspects(i.Aspects)
+			instances[key] = inst
+			byName[i.Name] = append(byName[i.Name], inst)
+			layer.AddInstanceToLayer(inst)
+		}
+		model.AddLayer(layer, level)
+	}
+
+	for _, l := range doc.Layers {
+		for _, i := range l.Instances {
+			key := i.Name
+			if i.Path != "" {
+				key = i.Path
+			}
+			inst := instances[key]
+			for _, ref := range i.Relations {
+				rel, ok := instances[ref]
+				if !ok && len(byName[ref]) > 1 {
+					return fmt.Errorf("instance %s relates to %s: %w", key, ref, ErrAmbiguousInstance)
+				}
+				if !ok && len(byName[ref]) == 1 {
+					rel, ok = byName[ref][0], true
+				}
+				if !ok {
+					return fmt.Errorf("instance %s relates to an unknown instance %s", key, ref)
+				}
+				inst.AddRelation(rel)
+			}
+		}
+	}
+
+	sm.Depth = model.Depth
+	sm.Layers = model.Layers
+	sm.Applications = model.Applications
+	sm.VIcount = model.VIcount
+	return nil
+}
+
+// LoadSystemModel reads the System Model from a JSON file
+func LoadSystemModel(fileName string) (*SystemModel, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	sm := &SystemModel{}
+	if err := json.Unmarshal(content, sm); err != nil {
+		return nil, fmt.Errorf("couldn't parse System Model from %s: %w", fileName, err)
+	}
+	return sm, nil
+}
+
+// SaveSystemModel writes the System Model to a JSON file
+func (sm *SystemModel) SaveSystemModel(fileName string) error {
+	out, err := json.MarshalIndent(sm, "", " ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, out, 0644)
+}
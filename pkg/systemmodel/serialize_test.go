@@ -0,0 +1,90 @@
+package systemmodel
+
+import (
+	"encoding/json"
+	"gotest.tools/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoadSystemModel(t *testing.T) {
+	sm := CreateSystemModelDepth4()
+	fileName := filepath.Join(t.TempDir(), "sm.json")
+	assert.NilError(t, sm.SaveSystemModel(fileName))
+
+	loaded, err := LoadSystemModel(fileName)
+	assert.NilError(t, err)
+	assert.Equal(t, loaded.Depth, sm.Depth)
+	assert.Equal(t, *loaded.VIcount, *sm.VIcount)
+	assert.Equal(t, loaded.GetTotalNumberOfInstances(), sm.GetTotalNumberOfInstances())
+	// loaded System Model does not differ from the original one
+	diff := Diff(sm, loaded)
+	t.Logf("Difference of the loaded System Model is %v", diff)
+	assert.Assert(t, diff.IsEmpty())
+
+	// relations are restored as pointers to the loaded instances
+	vi1, err := loaded.GetInstance("VI#2-1")
+	assert.NilError(t, err)
+	assert.Assert(t, loaded.Layers[1].Instances[0].Relations[0] == vi1)
+	assert.Equal(t, len(vi1.Relations), 2)
+}
+
+func TestSaveLoadSameNamedInstances(t *testing.T) {
+	// VIs of a single deployment share the name, only the second one deploys an Application
+	sm := CreateSystemModelDepth4()
+	_, err := sm.DeployApplicationAt("VI#3-4", "VI")
+	assert.NilError(t, err)
+	sm.CreateApplication(2, 1.0, "App#5")
+	sm.Applications["App#5"].SetPriority(0.1)
+	_, err = sm.DeployApplicationAt("MAIS/VI#2-2/VI#3-4/VI#4-2[2]", "App#5")
+	assert.NilError(t, err)
+	fileName := filepath.Join(t.TempDir(), "sm.json")
+	assert.NilError(t, sm.SaveSystemModel(fileName))
+
+	loaded, err := LoadSystemModel(fileName)
+	assert.NilError(t, err)
+	assert.Assert(t, Diff(sm, loaded).IsEmpty())
+	paths := loaded.instancesByPath()
+	assert.Equal(t, len(paths["MAIS/VI#2-2/VI#3-4/VI#4-2"].Relations), 0)
+	assert.Equal(t, len(paths["MAIS/VI#2-2/VI#3-4/VI#4-2[2]"].Relations), 2)
+}
+
+func TestSaveLoadGeneratedSystemModel(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		sm := &SystemModel{}
+		sm.InitializeSystemModel(10, 4)
+		sm.CreateRandomApplications(GenerateAppNames(10), 1, 10)
+		_, err := sm.GenerateSystemModel()
+		assert.NilError(t, err)
+		fileName := filepath.Join(t.TempDir(), "sm.json")
+		assert.NilError(t, sm.SaveSystemModel(fileName))
+
+		// same-named VIs of a single deployment and unfilled layers are restored
+		loaded, err := LoadSystemModel(fileName)
+		assert.NilError(t, err)
+		t.Logf("Loaded System Model of depth %d has %d layers and %d instances", loaded.Depth, len(loaded.Layers),
+			loaded.GetTotalNumberOfInstances())
+		assert.Equal(t, loaded.Depth, sm.Depth)
+		assert.Equal(t, len(loaded.Layers), len(sm.Layers))
+		assert.Equal(t, loaded.GetTotalNumberOfInstances(), sm.GetTotalNumberOfInstances())
+		assert.Assert(t, Diff(sm, loaded).IsEmpty())
+	}
+}
+
+func TestUnmarshalSystemModelErrors(t *testing.T) {
+	for _, tc := range []struct {
+		doc string
+		err string
+	}{
+		{`{"depth": 1, "layers": {"1": {"instances": [{"name": "MAIS", "type": "VI"}]}, "2": {"instances": []}}}`, "smaller than the number of layers"},
+		{`{"layers": {"1": {"instances": [{"name": "MAIS", "type": "VI"}]}, "3": {"instances": []}}}`, "level 2: layer was not found"},
+		{`{"layers": {"1": {"instances": [{"name": "MAIS", "type": "Robot"}]}}}`, "unknown type"},
+		{`{"layers": {"1": {"instances": [{"name": "MAIS", "type": "VI", "relations": ["VI#2-1"]}]}}}`, "unknown instance VI#2-1"},
+		{`{"layers": {"1": {"instances": [{"name": "MAIS", "type": "VI"}, {"name": "MAIS", "type": "VI"}]}}}`, "more than once"},
+		{`{"layers": {"1": {"instances": [{"name": "MAIS", "type": "VI", "relations": ["VI#2-1"]}]}, "2": {"instances": [{"name": "VI#2-1", "path": "MAIS/VI#2-1", "type": "VI"}, {"name": "VI#2-1", "path": "MAIS/VI#2-1[2]", "type": "VI"}]}}}`, "more instances share the name"},
+	} {
+		sm := &SystemModel{}
+		err := json.Unmarshal([]byte(tc.doc), sm)
+		assert.ErrorContains(t, err, tc.err, tc.doc)
+	}
+}
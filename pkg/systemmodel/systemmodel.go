@@ -3,8 +3,9 @@
 package systemmodel
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"math/rand"
 	"strconv"
 	"strings"
@@ -15,8 +16,9 @@ type SystemModel struct {
 	Depth  int            // represents depth of the system model
 	Layers map[int]*Layer // represents list of layers in the system model
 	// a key for the Application can be whatever string you want (e.g., name of the application)
-	Applications map[string]*Application // represents a list of applications, which were deployed at this layer - this is to track all deployed applications over all layers
-	VIcount      *uint64                 // this pointer is used to be passed to the various functions and change its value once VI is being deployed. This is done to distinguish various VIs on the same layer
+	Applications map[string]*Application   // represents a list of applications, which were deployed at this layer - this is to track all deployed applications over all layers
+	VIcount      *uint64                   // this pointer is used to be passed to the various functions and change its value once VI is being deployed. This is done to distinguish various VIs on the same layer
+	listeners    []func(event ChangeEvent) // listeners, which are notified about changes of the System Model topology
 }
 
 // Layer structure represents the layer of the system model (e.g., Layer[3] corresponds to the 3-rd level of the SystemModel)
@@ -174,7 +176,7 @@ func (a *Application) DeployApplication() bool {
 
 // DeployApplications iterates over a map of Applications and checks, whether application is deployed or not.
 // It returns updated list of Applications, which denotes the updated state of applications
-func (i *Instance) DeployApplications(apps map[string]*Application, currentLevel int, viCount *uint64) (bool, map[string]*Application) {
+func (i *Instance) DeployApplications(apps map[string]*Application, currentLevel int, viCount *uint64) (bool, map[string]*Application, error) {
 	updatedApps := make(map[string]*Application, len(apps))
 	viWasDeployed := false
 
@@ -208,8 +210,7 @@ func (i *Instance) DeployApplications(apps map[string]*Application, currentLevel
 					} else {
 						name, err = ComposeAppName(appName, currentLevel, j)
 						if err != nil {
-							log.Fatalf("Couldn't compose instance name: %v", err)
-							return false, nil
+							return false, nil, fmt.Errorf("couldn't compose instance name: %w", err)
 						}
 					}
 					appInstance.CreateInstance(name, tp)
@@ -224,7 +225,7 @@ func (i *Instance) DeployApplications(apps map[string]*Application, currentLevel
 		}
 	}
 
-	return viWasDeployed, updatedApps
+	return viWasDeployed, updatedApps, nil
 }
 
 // ComposeAppNamePrefix composes a name prefix (App#(layer number)-(app number)) for Application instance per convention
@@ -255,7 +256,7 @@ func ComposeVIName(currentLevel int, viCount int64) string {
 }
 
 // CreateLayer creates a layer of the SystemModel and updates the Applications list to reflect the current deployment state
-func (l *Layer) CreateLayer(apps map[string]*Application, currentLevel int, viCount *uint64) (map[string]*Application, *Layer) {
+func (l *Layer) CreateLayer(apps map[string]*Application, currentLevel int, viCount *uint64) (map[string]*Application, *Layer, error) {
 	nextLayer := &Layer{}
 	nextLayer.InitializeLayer()
 	updApps := apps
@@ -263,7 +264,10 @@ func (l *Layer) CreateLayer(apps map[string]*Application, currentLevel int, viCo
 	for _, instance := range l.Instances {
 		// checking if the instance is of type VI or the root instance, MAIS
 		if strings.HasPrefix(instance.Name, "VI") || strings.Contains(instance.Name, "MAIS") {
-			viWasDeployed, updatedApps := instance.DeployApplications(updApps, currentLevel, viCount)
+			viWasDeployed, updatedApps, err := instance.DeployApplications(updApps, currentLevel, viCount)
+			if err != nil {
+				return nil, nil, err
+			}
 			if viWasDeployed {
 				l.VIwasDeployed = true
 			}
@@ -276,15 +280,28 @@ func (l *Layer) CreateLayer(apps map[string]*Application, currentLevel int, viCo
 		}
 	}
 
-	return updApps, nextLayer
+	return updApps, nextLayer, nil
 }
 
 // GenerateSystemModel generates system model with regard to provided input data
-func (sm *SystemModel) GenerateSystemModel() *SystemModel {
+func (sm *SystemModel) GenerateSystemModel() (*SystemModel, error) {
+	return sm.GenerateSystemModelContext(context.Background())
+}
+
+// GenerateSystemModelContext generates system model with regard to provided input data. Cancellation of the context
+// is checked before each layer is created. Once the context is cancelled, the System Model with the layers generated
+// so far is returned together with the error of the context.
+func (sm *SystemModel) GenerateSystemModelContext(ctx context.Context) (*SystemModel, error) {
 	sm.InitializeRootLayer()
 	for i := 2; i <= sm.Depth; i++ {
+		if err := ctx.Err(); err != nil {
+			return sm, fmt.Errorf("generation stopped before layer %d: %w", i, err)
+		}
 		if sm.Layers[i-1].VIwasDeployed {
-			apps, nextLayer := sm.Layers[i-1].CreateLayer(sm.Applications, i, sm.VIcount)
+			apps, nextLayer, err := sm.Layers[i-1].CreateLayer(sm.Applications, i, sm.VIcount)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't create layer %d: %w", i, err)
+			}
 			sm.Applications = apps // updating Applications map
 			// if something was deployed, then add Layer to the SystemModel, otherwise stop
 			if len(nextLayer.Instances) > 0 {
@@ -296,7 +313,7 @@ func (sm *SystemModel) GenerateSystemModel() *SystemModel {
 			break
 		}
 	}
-	return sm
+	return sm, nil
 }
 
 // InitializeRootLayer initializes 1st level Layer of SystemModel as a single instance with given name "MAIS",
@@ -313,58 +330,50 @@ func (sm *SystemModel) InitializeRootLayer() *SystemModel {
 	return sm
 }
 
-// PrettyPrintApplications prints Application related information
+// PrettyPrintApplications logs Application related information. Records are logged at the debug level, so that
+// large System Models don't flood the output on error paths.
 func (sm *SystemModel) PrettyPrintApplications() *SystemModel {
+	if !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		return sm
+	}
 	for k, v := range sm.Applications {
-		log.Printf("%s has probability %v and deploys %v instances. Number of aspects is %d."+
-			" Deployed status: %v\n", k, v.Probability, v.Rules, len(v.Aspect), v.State)
-		for key, value := range v.Aspect {
-			log.Printf("Aspect %s, value %s\n", key, value)
-		}
+		slog.Debug("application", "name", k, "probability", v.Probability, "instances", v.Rules,
+			"deployed", v.State, "aspects", v.Aspect)
 	}
 	return sm
 }
 
-// PrettyPrintLayers prints Layers related information
+// PrettyPrintLayers logs Layers related information (at the debug level)
 func (sm *SystemModel) PrettyPrintLayers() *SystemModel {
+	if !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		return sm
+	}
 	for k := 1; k <= len(sm.Layers); k++ {
 		v := sm.Layers[k]
-		fmt.Printf("----> Layer %v, VI deployed %v, Instances deployed %v, detailed info about deployed instances:\n", k, v.VIwasDeployed, len(v.Instances))
+		slog.Debug("layer", "level", k, "viDeployed", v.VIwasDeployed, "instances", len(v.Instances))
 		v.PrettyPrintLayer()
 	}
 	return sm
 }
 
-// PrettyPrintLayer prints Layer related information
+// PrettyPrintLayer logs Layer related information (at the debug level)
 func (l *Layer) PrettyPrintLayer() {
+	if !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
 	for _, v := range l.Instances {
-		if len(v.Relations) > 0 {
-			fmt.Printf("--> Instance %s of type %v has %v following relations:\n", v.Name, v.Type, len(v.Relations))
-			for _, val := range v.Relations {
-				fmt.Printf("Related is Instance %s of type %v\n", val.Name, val.Type)
-			}
-			if len(v.Aspect) > 0 {
-				fmt.Printf("-> Instance %s of type %v has %d aspects, they are:\n", v.Name, v.Type, len(v.Aspect))
-				for k, v := range v.Aspect {
-					fmt.Printf("Aspect %s, value %s\n", k, v)
-				}
-			} else {
-				fmt.Printf("-> Instance %s of type %v has 0 aspects\n", v.Name, v.Type)
-			}
-		} else {
-			fmt.Printf("--> Instance %s of type %v has no relations and %d aspects\n", v.Name, v.Type, len(v.Aspect))
-			for k, v := range v.Aspect {
-				fmt.Printf("Aspect %s, value %s\n", k, v)
-			}
+		relations := make([]string, 0, len(v.Relations))
+		for _, val := range v.Relations {
+			relations = append(relations, val.Name)
 		}
+		slog.Debug("instance", "name", v.Name, "type", v.Type, "relations", relations, "aspects", v.Aspect)
 	}
 }
 
 // GetTotalNumberOfInstances gets total number of instances (i.e., nodes) in the SystemModel structure
 func (sm *SystemModel) GetTotalNumberOfInstances() int64 {
 	var total int64
-	for k := 1; k <= len(sm.Layers); k++ {
-		v := sm.Layers[k]
+	for _, v := range sm.Layers {
 		total += int64(len(v.Instances))
 	}
 	return total
@@ -427,7 +436,7 @@ func (i *Instance) GetAspect(key string) (string, error) {
 	var aspect string
 	aspect, ok := i.Aspect[key]
 	if !ok {
-		return "", fmt.Errorf("can't find '%v' aspect for instance %v", key, i.Name)
+		return "", fmt.Errorf("instance %s: %s %w", i.Name, key, ErrAspectMissing)
 	}
 	return aspect, nil
 }
@@ -1,6 +1,8 @@
 package systemmodel
 
 import (
+	"context"
+	"errors"
 	"gotest.tools/assert"
 	"testing"
 )
@@ -48,7 +50,8 @@ func TestGenerateRandomSystemModel(t *testing.T) {
 	names := GenerateAppNames(a)
 	systemModel.InitializeSystemModel(maxNumInstances, l)
 	systemModel.CreateRandomApplications(names, minNumInstances, maxNumInstances)
-	systemModel.GenerateSystemModel()
+	_, err := systemModel.GenerateSystemModel()
+	assert.NilError(t, err)
 	t.Logf("System model is\n%v", systemModel)
 
 	// check that the probabilities are of total 1
@@ -61,6 +64,21 @@ func TestGenerateRandomSystemModel(t *testing.T) {
 	assert.Assert(t, sum <= float32(1.0001)) // leaving .0001 as a possible overhead due to float32 operations..
 }
 
+func TestGenerateSystemModelContext(t *testing.T) {
+	systemModel := &SystemModel{}
+	systemModel.InitializeSystemModel(10, 4)
+	systemModel.CreateRandomApplications(GenerateAppNames(10), 1, 15)
+
+	// cancelled generation returns the layers generated so far, i.e., the root layer
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sm, err := systemModel.GenerateSystemModelContext(ctx)
+	t.Logf("Cancelled generation returns: %v", err)
+	assert.Assert(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, len(sm.Layers), 1)
+	assert.Equal(t, sm.GetTotalNumberOfInstances(), int64(1))
+}
+
 func BenchmarkGenerateSystemModel(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		systemModel := &SystemModel{}
@@ -73,7 +91,10 @@ func BenchmarkGenerateSystemModel(b *testing.B) {
 		names := GenerateAppNames(apps)
 		systemModel.InitializeSystemModel(maxNumInstances, depth)
 		systemModel.CreateRandomApplications(names, 1, maxNumInstances)
-		systemModel.GenerateSystemModel()
+		_, err := systemModel.GenerateSystemModel()
+		if err != nil {
+			b.Fatal(err)
+		}
 	}
 }
 